@@ -144,6 +144,41 @@ func init() {
 	viper.BindPFlag("BanKey.BanTime", configCmd.Flags().Lookup("banTime"))
 	viper.BindPFlag("BanKey.BadTx", configCmd.Flags().Lookup("badTx"))
 
+	configCmd.Flags().BoolVar(&conf.Config.PageCache.Enabled, "pageCache", false, "Enable the cache of rendered pages")
+	configCmd.Flags().IntVar(&conf.Config.PageCache.Size, "pageCacheSize", 1000, "Maximum number of cached pages")
+	viper.BindPFlag("PageCache.Enabled", configCmd.Flags().Lookup("pageCache"))
+	viper.BindPFlag("PageCache.Size", configCmd.Flags().Lookup("pageCacheSize"))
+
+	configCmd.Flags().StringSliceVar(&conf.Config.HTTPSource.AllowedURLs, "httpSourceAllow", []string{}, "Url prefixes which can be requested by HTTPSource in OBS mode")
+	configCmd.Flags().IntVar(&conf.Config.HTTPSource.Timeout, "httpSourceTimeout", 5, "Timeout of HTTPSource requests (seconds)")
+	configCmd.Flags().IntVar(&conf.Config.HTTPSource.CacheTTL, "httpSourceCacheTTL", 60, "Time of caching HTTPSource responses (seconds)")
+	viper.BindPFlag("HTTPSource.AllowedURLs", configCmd.Flags().Lookup("httpSourceAllow"))
+	viper.BindPFlag("HTTPSource.Timeout", configCmd.Flags().Lookup("httpSourceTimeout"))
+	viper.BindPFlag("HTTPSource.CacheTTL", configCmd.Flags().Lookup("httpSourceCacheTTL"))
+
+	configCmd.Flags().StringVar(&conf.Config.Webhook.Secret, "webhookSecret", "", "Secret key of HMAC signature of transaction callbacks")
+	configCmd.Flags().IntVar(&conf.Config.Webhook.Timeout, "webhookTimeout", 10, "Timeout of transaction callback requests (seconds)")
+	viper.BindPFlag("Webhook.Secret", configCmd.Flags().Lookup("webhookSecret"))
+	configCmd.Flags().StringSliceVar(&conf.Config.Webhook.AllowedHosts, "webhookAllow", []string{}, "Hosts which can receive transaction callbacks, any public host if it is empty")
+	viper.BindPFlag("Webhook.Timeout", configCmd.Flags().Lookup("webhookTimeout"))
+	viper.BindPFlag("Webhook.AllowedHosts", configCmd.Flags().Lookup("webhookAllow"))
+
+	configCmd.Flags().BoolVar(&conf.Config.RateLimit.Enabled, "rateLimit", false, "Enable the limiter of API requests")
+	configCmd.Flags().Float64Var(&conf.Config.RateLimit.Rate, "rateLimitRate", 10, "Number of API requests per second for the client")
+	configCmd.Flags().IntVar(&conf.Config.RateLimit.Burst, "rateLimitBurst", 20, "Maximum number of API requests of the client at once")
+	configCmd.Flags().Float64Var(&conf.Config.RateLimit.IP.Rate, "rateLimitIPRate", 50, "Number of API requests per second from the address")
+	configCmd.Flags().IntVar(&conf.Config.RateLimit.IP.Burst, "rateLimitIPBurst", 100, "Maximum number of API requests from the address at once")
+	viper.BindPFlag("RateLimit.Enabled", configCmd.Flags().Lookup("rateLimit"))
+	viper.BindPFlag("RateLimit.Rate", configCmd.Flags().Lookup("rateLimitRate"))
+	viper.BindPFlag("RateLimit.Burst", configCmd.Flags().Lookup("rateLimitBurst"))
+	viper.BindPFlag("RateLimit.IP.Rate", configCmd.Flags().Lookup("rateLimitIPRate"))
+	viper.BindPFlag("RateLimit.IP.Burst", configCmd.Flags().Lookup("rateLimitIPBurst"))
+
+	configCmd.Flags().StringVar(&conf.Config.JWT.Algorithm, "jwtAlgorithm", "ES256", "Signing method of API tokens (HS256, ES256)")
+	configCmd.Flags().Int64Var(&conf.Config.JWT.RefreshExpire, "jwtRefreshExpire", 30*24*3600, "Lifetime of API refresh tokens (seconds), 0 disables refresh tokens")
+	viper.BindPFlag("JWT.Algorithm", configCmd.Flags().Lookup("jwtAlgorithm"))
+	viper.BindPFlag("JWT.RefreshExpire", configCmd.Flags().Lookup("jwtRefreshExpire"))
+
 	// Etc
 	configCmd.Flags().StringVar(&conf.Config.PidFilePath, "pid", "",
 		fmt.Sprintf("Apla pid file name (default dataDir/%s)", consts.DefaultPidFilename),
@@ -0,0 +1,91 @@
+// Copyright (C) 2017, 2018, 2019 EGAAS S.A.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or (at
+// your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but
+// WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301, USA.
+
+package api
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/AplaProject/go-apla/packages/consts"
+	"github.com/AplaProject/go-apla/packages/converter"
+	"github.com/AplaProject/go-apla/packages/crypto"
+	"github.com/AplaProject/go-apla/packages/model"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+)
+
+// apiKeyPrefix is the prefix of Authorization header with API key
+const apiKeyPrefix = "ApiKey "
+
+// parseAPIKey returns the token with the claims of API key. The key is issued by NewAPIKey contract
+// which stores only the hash of the key. Nil token is returned if the key isn't active
+func parseAPIKey(r *http.Request, key string) (*jwt.Token, error) {
+	logger := getLogger(r)
+
+	hash, err := crypto.HashHex([]byte(key))
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.CryptoError, "error": err}).Error("hashing api key")
+		return nil, errServer
+	}
+	apiKey := &model.APIKey{}
+	found, err := apiKey.GetByHash(nil, hash)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting api key")
+		return nil, errServer
+	}
+	if !found || !apiKey.IsActive(time.Now().Unix()) {
+		logger.WithFields(log.Fields{"type": consts.AccessDenied, "found": found}).Warning("api key is not active")
+		return nil, nil
+	}
+
+	var route string
+	if current := mux.CurrentRoute(r); current != nil {
+		route, _ = current.GetPathTemplate()
+	}
+	if !apiKey.AllowRoute(strings.TrimPrefix(route, openAPIPrefix)) {
+		logger.WithFields(log.Fields{"type": consts.AccessDenied, "id": apiKey.ID, "route": route}).Warning("route is not allowed for api key")
+		return nil, errAPIKeyRoute
+	}
+
+	account := converter.AddressToString(apiKey.KeyID)
+	if apiKey.RoleID != 0 {
+		role, err := checkRoleFromParam(apiKey.RoleID, apiKey.Ecosystem, account)
+		if err != nil {
+			return nil, errServer
+		}
+		if role == 0 {
+			return nil, nil
+		}
+	}
+
+	return &jwt.Token{
+		Method: jwt.SigningMethodHS256,
+		Claims: &JWTClaims{
+			EcosystemID: converter.Int64ToStr(apiKey.Ecosystem),
+			KeyID:       converter.Int64ToStr(apiKey.KeyID),
+			AccountID:   account,
+			RoleID:      converter.Int64ToStr(apiKey.RoleID),
+			StandardClaims: jwt.StandardClaims{
+				ExpiresAt: apiKey.Expire,
+			},
+		},
+		Valid: true,
+	}, nil
+}
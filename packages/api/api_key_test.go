@@ -0,0 +1,102 @@
+// Copyright (C) 2017, 2018, 2019 EGAAS S.A.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or (at
+// your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but
+// WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301, USA.
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/AplaProject/go-apla/packages/consts"
+	"github.com/AplaProject/go-apla/packages/crypto"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func sendAPIKeyGet(key, url string, v interface{}) error {
+	req, err := http.NewRequest("GET", apiAddress+consts.ApiPath+url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", apiKeyPrefix+key)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf(`%d %s`, resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	return json.Unmarshal(data, v)
+}
+
+func TestAPIKey(t *testing.T) {
+	assert.NoError(t, keyLogin(1))
+
+	key := randName(`apikey`)
+	hash, err := crypto.HashHex([]byte(key))
+	if !assert.NoError(t, err) {
+		return
+	}
+	form := url.Values{
+		`Name`:    {`erp`},
+		`KeyHash`: {hash},
+		`Routes`:  {`/list/{name}, /row/*`},
+	}
+	_, id, err := postTxResult(`NewAPIKey`, &form)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Error(t, postTx(`NewAPIKey`, &form))
+	assert.Error(t, postTx(`NewAPIKey`, &url.Values{
+		`Name`:    {`erp`},
+		`KeyHash`: {`wrong`},
+		`Routes`:  {`*`},
+	}))
+
+	var list listResult
+	assert.NoError(t, sendAPIKeyGet(key, `list/contracts?limit=1`, &list))
+	assert.Len(t, list.List, 1)
+
+	var row rowResult
+	assert.NoError(t, sendAPIKeyGet(key, `row/contracts/1`, &row))
+
+	var tables tablesResult
+	err = sendAPIKeyGet(key, `tables`, &tables)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), errAPIKeyRoute.Err)
+	}
+	err = sendAPIKeyGet(`wrong`+key, `list/contracts`, &list)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), errUnauthorized.Err)
+	}
+
+	assert.NoError(t, postTx(`RevokeAPIKey`, &url.Values{`Id`: {id}}))
+	assert.Error(t, postTx(`RevokeAPIKey`, &url.Values{`Id`: {id}}))
+	err = sendAPIKeyGet(key, `list/contracts`, &list)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), errUnauthorized.Err)
+	}
+}
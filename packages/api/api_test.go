@@ -45,6 +45,7 @@ var apiAddress = "http://localhost:7079"
 
 var (
 	gAuth             string
+	gRefresh          string
 	gAddress          string
 	gPrivate, gPublic string
 	gMobile           bool
@@ -164,6 +165,7 @@ func keyLogin(state int64) (err error) {
 	gPrivate = string(key)
 	gPublic, err = PrivateToPublicHex(gPrivate)
 	gAuth = logret.Token
+	gRefresh = logret.Refresh
 	if err != nil {
 		return
 	}
@@ -0,0 +1,154 @@
+// Copyright (C) 2017, 2018, 2019 EGAAS S.A.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or (at
+// your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but
+// WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301, USA.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/AplaProject/go-apla/packages/consts"
+	"github.com/AplaProject/go-apla/packages/converter"
+	"github.com/AplaProject/go-apla/packages/model"
+
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+)
+
+type assetResult struct {
+	ID        int64  `json:"id"`
+	Name      string `json:"name"`
+	Owner     string `json:"owner"`
+	Creator   string `json:"creator"`
+	CreatedAt int64  `json:"created_at"`
+	BinaryID  int64  `json:"binary_id"`
+	Link      string `json:"link,omitempty"`
+}
+
+type assetOwnerResult struct {
+	Owner   string `json:"owner"`
+	BlockID int64  `json:"block_id"`
+	Time    int64  `json:"time"`
+}
+
+type assetHistoryResult struct {
+	List []assetOwnerResult `json:"list"`
+}
+
+func getAsset(w http.ResponseWriter, r *http.Request) (*model.Asset, bool) {
+	logger := getLogger(r)
+	client := getClient(r)
+
+	id := converter.StrToInt64(mux.Vars(r)["id"])
+	asset := &model.Asset{}
+	found, err := asset.SetTablePrefix(client.EcosystemID).Get(nil, id)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting asset")
+		errorResponse(w, err)
+		return nil, false
+	}
+	if !found {
+		errorResponse(w, errAssetNotFound.Errorf(id))
+		return nil, false
+	}
+	return asset, true
+}
+
+func getAssetHandler(w http.ResponseWriter, r *http.Request) {
+	logger := getLogger(r)
+
+	asset, ok := getAsset(w, r)
+	if !ok {
+		return
+	}
+	result := &assetResult{
+		ID:        asset.ID,
+		Name:      asset.Name,
+		Owner:     converter.AddressToString(asset.Owner),
+		Creator:   converter.AddressToString(asset.Creator),
+		CreatedAt: asset.CreatedAt,
+		BinaryID:  asset.BinaryID,
+	}
+	if asset.BinaryID != 0 {
+		binary := &model.Binary{}
+		binary.SetTablePrefix(getClient(r).Prefix())
+		found, err := binary.GetInfo(nil, asset.BinaryID)
+		if err != nil {
+			logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting binary")
+			errorResponse(w, err)
+			return
+		}
+		if found {
+			result.Link = binary.Link()
+		}
+	}
+	jsonResponse(w, result)
+}
+
+// getAssetHistoryHandler restores the chain of owners of the asset from the rollback records
+// of the asset row. The latest owner goes first
+func getAssetHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	logger := getLogger(r)
+
+	asset, ok := getAsset(w, r)
+	if !ok {
+		return
+	}
+	rollbackTx := &model.RollbackTx{}
+	txs, err := rollbackTx.GetRollbackTxsByTableIDAndTableName(converter.Int64ToStr(asset.ID),
+		asset.TableName(), rollbackHistoryLimit)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("rollback history")
+		errorResponse(w, err)
+		return
+	}
+
+	owner := asset.Owner
+	list := make([]assetOwnerResult, 0)
+	for _, tx := range *txs {
+		var prevOwner int64
+		if len(tx.Data) > 0 {
+			rollback := map[string]string{}
+			if err := json.Unmarshal([]byte(tx.Data), &rollback); err != nil {
+				logger.WithFields(log.Fields{"type": consts.JSONUnmarshallError, "error": err}).Error("unmarshalling rollbackTx.Data from JSON")
+				errorResponse(w, err)
+				return
+			}
+			value, ok := rollback["owner"]
+			if !ok {
+				continue
+			}
+			prevOwner = converter.StrToInt64(value)
+		}
+
+		item := assetOwnerResult{
+			Owner:   converter.AddressToString(owner),
+			BlockID: tx.BlockID,
+		}
+		block := &model.Block{}
+		if found, err := block.Get(tx.BlockID); err != nil {
+			logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting block")
+			errorResponse(w, err)
+			return
+		} else if found {
+			item.Time = block.Time
+		}
+		list = append(list, item)
+		owner = prevOwner
+	}
+
+	jsonResponse(w, &assetHistoryResult{list})
+}
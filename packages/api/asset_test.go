@@ -0,0 +1,59 @@
+// Copyright (C) 2017, 2018, 2019 EGAAS S.A.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or (at
+// your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but
+// WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301, USA.
+
+package api
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAsset(t *testing.T) {
+	assert.NoError(t, keyLogin(1))
+
+	_, id, err := postTxResult(`NewAsset`, &url.Values{`Name`: {randName(`asset`)}})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var asset assetResult
+	assert.NoError(t, sendGet(`asset/`+id, nil, &asset))
+	assert.Equal(t, gAddress, asset.Owner)
+
+	recipient := `0000-0000-0000-0000-0001`
+	assert.NoError(t, postTx(`AssetTransfer`, &url.Values{
+		`AssetId`:   {id},
+		`Recipient`: {recipient},
+	}))
+
+	err = postTx(`AssetTransfer`, &url.Values{
+		`AssetId`:   {id},
+		`Recipient`: {gAddress},
+	})
+	assert.Error(t, err)
+
+	assert.NoError(t, sendGet(`asset/`+id, nil, &asset))
+	assert.Equal(t, recipient, asset.Owner)
+
+	var history assetHistoryResult
+	assert.NoError(t, sendGet(`asset/`+id+`/history`, nil, &history))
+	if assert.Len(t, history.List, 2) {
+		assert.Equal(t, recipient, history.List[0].Owner)
+		assert.Equal(t, gAddress, history.List[1].Owner)
+	}
+}
@@ -17,20 +17,37 @@
 package api
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"net/http"
+	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/AplaProject/go-apla/packages/conf"
+	"github.com/AplaProject/go-apla/packages/consts"
 	"github.com/AplaProject/go-apla/packages/converter"
 	"github.com/AplaProject/go-apla/packages/crypto"
+	"github.com/AplaProject/go-apla/packages/model"
 	"github.com/AplaProject/go-apla/packages/types"
 
 	"github.com/dgrijalva/jwt-go"
+	log "github.com/sirupsen/logrus"
 )
 
+const jwtIDSize = 16
+
 var (
 	jwtSecret = []byte(crypto.RandSeq(15))
+	jwtKey    *ecdsa.PrivateKey // ES256 key, tokens are signed by jwtSecret if it isn't loaded
 	jwtPrefix = "Bearer "
 	jwtExpire = 36000 // By default, seconds
 
@@ -46,12 +63,70 @@ type JWTClaims struct {
 	AccountID   string `json:"account_id,omitempty"`
 	RoleID      string `json:"role_id,omitempty"`
 	IsMobile    bool   `json:"is_mobile,omitempty"`
+	Refresh     bool   `json:"refresh,omitempty"`
 	jwt.StandardClaims
 }
 
+// InitJWT loads the ES256 key of API tokens from KeysDir. The key is generated if the file doesn't exist.
+// API nodes with the same key file and the same database accept the tokens of each other
+func InitJWT() error {
+	switch conf.Config.JWT.Algorithm {
+	case "", jwt.SigningMethodHS256.Alg():
+		return nil
+	case jwt.SigningMethodES256.Alg():
+	default:
+		log.WithFields(log.Fields{"type": consts.InvalidObject, "algorithm": conf.Config.JWT.Algorithm}).Error("unknown jwt algorithm")
+		return fmt.Errorf("unknown jwt algorithm %s", conf.Config.JWT.Algorithm)
+	}
+
+	filename := filepath.Join(conf.Config.KeysDir, consts.JWTPrivateKeyFilename)
+	data, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		if data, err = generateJWTKey(); err != nil {
+			log.WithFields(log.Fields{"type": consts.CryptoError, "error": err}).Error("generating jwt key")
+			return err
+		}
+		err = ioutil.WriteFile(filename, data, 0600)
+	}
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.IOError, "error": err, "file": filename}).Error("reading jwt key")
+		return err
+	}
+
+	key, err := jwt.ParseECPrivateKeyFromPEM(data)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.CryptoError, "error": err, "file": filename}).Error("parsing jwt key")
+		return err
+	}
+	jwtKey = key
+	return nil
+}
+
+func generateJWTKey() ([]byte, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), nil
+}
+
 func generateJWTToken(claims JWTClaims) (string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtSecret)
+	if len(claims.Id) == 0 {
+		id := make([]byte, jwtIDSize)
+		if _, err := rand.Read(id); err != nil {
+			return "", err
+		}
+		claims.Id = hex.EncodeToString(id)
+	}
+
+	if jwtKey != nil {
+		return jwt.NewWithClaims(jwt.SigningMethodES256, claims).SignedString(jwtKey)
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(jwtSecret)
 }
 
 func parseJWTToken(header string) (*jwt.Token, error) {
@@ -65,14 +140,51 @@ func parseJWTToken(header string) (*jwt.Token, error) {
 		return nil, errJWTAuthValue
 	}
 
-	return jwt.ParseWithClaims(header, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("Unexpected signing method: %v", token.Header["alg"])
+	return parseJWTValue(header)
+}
+
+func parseJWTValue(value string) (*jwt.Token, error) {
+	return jwt.ParseWithClaims(value, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
+		switch token.Method.(type) {
+		case *jwt.SigningMethodHMAC:
+			return jwtSecret, nil
+		case *jwt.SigningMethodECDSA:
+			if jwtKey != nil {
+				return &jwtKey.PublicKey, nil
+			}
 		}
-		return []byte(jwtSecret), nil
+		return nil, fmt.Errorf("Unexpected signing method: %v", token.Header["alg"])
 	})
 }
 
+// isTokenActive returns false for refresh tokens and for the tokens from the revocation list
+func isTokenActive(token *jwt.Token) (bool, error) {
+	claims, ok := token.Claims.(*JWTClaims)
+	if !ok {
+		return false, nil
+	}
+	if claims.Refresh {
+		return false, nil
+	}
+	if len(claims.Id) == 0 {
+		return true, nil
+	}
+	revoked, err := model.IsTokenRevoked(claims.Id)
+	return !revoked, err
+}
+
+// revokeToken adds the token to the revocation list until the token is expired
+func revokeToken(claims *JWTClaims) error {
+	now := time.Now().Unix()
+	if err := model.DeleteExpiredRevokedTokens(now); err != nil {
+		return err
+	}
+	if claims.ExpiresAt != 0 && claims.ExpiresAt < now {
+		return nil
+	}
+	return (&model.RevokedToken{ID: claims.Id, Expire: claims.ExpiresAt}).Create()
+}
+
 func getClientFromToken(token *jwt.Token, ecosysNameService types.EcosystemNameGetter) (*Client, error) {
 	claims, ok := token.Claims.(*JWTClaims)
 	if !ok {
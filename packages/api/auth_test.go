@@ -0,0 +1,122 @@
+// Copyright (C) 2017, 2018, 2019 EGAAS S.A.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or (at
+// your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but
+// WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301, USA.
+
+package api
+
+import (
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AplaProject/go-apla/packages/conf"
+	"github.com/AplaProject/go-apla/packages/consts"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJWTKey(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jwt")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	keysDir, algorithm := conf.Config.KeysDir, conf.Config.JWT.Algorithm
+	defer func() {
+		conf.Config.KeysDir, conf.Config.JWT.Algorithm = keysDir, algorithm
+		jwtKey = nil
+	}()
+
+	claims := JWTClaims{KeyID: "1", StandardClaims: jwt.StandardClaims{
+		ExpiresAt: time.Now().Add(time.Minute).Unix(),
+	}}
+	hsToken, err := generateJWTToken(claims)
+	require.NoError(t, err)
+
+	conf.Config.KeysDir, conf.Config.JWT.Algorithm = dir, "RS256"
+	assert.Error(t, InitJWT())
+
+	conf.Config.JWT.Algorithm = "ES256"
+	require.NoError(t, InitJWT())
+	_, err = os.Stat(filepath.Join(dir, consts.JWTPrivateKeyFilename))
+	require.NoError(t, err)
+
+	esToken, err := generateJWTToken(claims)
+	require.NoError(t, err)
+
+	// the key is loaded from the file by other nodes
+	key := jwtKey
+	require.NoError(t, InitJWT())
+	assert.Equal(t, key.D, jwtKey.D)
+
+	for alg, value := range map[string]string{"HS256": hsToken, "ES256": esToken} {
+		token, err := parseJWTToken(jwtPrefix + value)
+		if assert.NoError(t, err, alg) {
+			assert.True(t, token.Valid)
+			assert.Equal(t, alg, token.Method.Alg())
+			assert.Len(t, token.Claims.(*JWTClaims).Id, jwtIDSize*2)
+		}
+	}
+
+	// refresh tokens aren't accepted as access tokens
+	claims.Refresh = true
+	refresh, err := generateJWTToken(claims)
+	require.NoError(t, err)
+	token, err := parseJWTToken(jwtPrefix + refresh)
+	require.NoError(t, err)
+	active, err := isTokenActive(token)
+	assert.NoError(t, err)
+	assert.False(t, active)
+}
+
+func TestRefreshToken(t *testing.T) {
+	require.NoError(t, keyLogin(1))
+	if len(gRefresh) == 0 {
+		t.Skip("refresh tokens are disabled")
+	}
+
+	var ret refreshResult
+	require.NoError(t, sendPost(`auth/refresh`, &url.Values{"token": {gRefresh}}, &ret))
+	assert.NotEmpty(t, ret.Token)
+	assert.NotEmpty(t, ret.Refresh)
+
+	// the refresh token can be used once
+	err := sendPost(`auth/refresh`, &url.Values{"token": {gRefresh}}, &ret)
+	if assert.Error(t, err) {
+		assert.True(t, strings.Contains(err.Error(), `E_REFRESHTOKEN`), err.Error())
+	}
+
+	gAuth = ret.Token
+	var status authStatusResponse
+	require.NoError(t, sendGet(`auth/status`, nil, &status))
+	assert.True(t, status.IsActive)
+
+	var revoked revokeResult
+	require.NoError(t, sendPost(`auth/revoke`, &url.Values{"refresh": {ret.Refresh}}, &revoked))
+	assert.True(t, revoked.Result)
+
+	require.NoError(t, sendGet(`auth/status`, nil, &status))
+	assert.False(t, status.IsActive)
+
+	err = sendPost(`auth/refresh`, &url.Values{"token": {ret.Refresh}}, &ret)
+	if assert.Error(t, err) {
+		assert.True(t, strings.Contains(err.Error(), `E_REFRESHTOKEN`), err.Error())
+	}
+}
@@ -24,18 +24,33 @@ import (
 	"github.com/AplaProject/go-apla/packages/model"
 
 	"github.com/gorilla/mux"
+	"github.com/shopspring/decimal"
 	log "github.com/sirupsen/logrus"
 )
 
 type balanceResult struct {
-	Amount string `json:"amount"`
-	Money  string `json:"money"`
+	Amount      string `json:"amount"`
+	Money       string `json:"money"`
+	Token       string `json:"token,omitempty"`
+	Locked      string `json:"locked,omitempty"`
+	LockedMoney string `json:"locked_money,omitempty"`
+}
+
+type balanceForm struct {
+	ecosystemForm
+	Token string `schema:"token"`
+}
+
+func (f *balanceForm) Validate(r *http.Request) error {
+	return f.ecosystemForm.Validate(r)
 }
 
 func (m Mode) getBalanceHandler(w http.ResponseWriter, r *http.Request) {
 	logger := getLogger(r)
-	form := &ecosystemForm{
-		Validator: m.EcosysIDValidator,
+	form := &balanceForm{
+		ecosystemForm: ecosystemForm{
+			Validator: m.EcosysIDValidator,
+		},
 	}
 
 	if err := parseForm(r, form); err != nil {
@@ -52,6 +67,11 @@ func (m Mode) getBalanceHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if len(form.Token) > 0 {
+		getTokenBalance(w, r, form.EcosystemID, keyID, form.Token)
+		return
+	}
+
 	key := &model.Key{}
 	key.SetTablePrefix(form.EcosystemID)
 	_, err := key.Get(keyID)
@@ -61,8 +81,60 @@ func (m Mode) getBalanceHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	jsonResponse(w, &balanceResult{
+	locked, err := model.GetLockedAmount(nil, form.EcosystemID, keyID)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting locked amount")
+		errorResponse(w, err)
+		return
+	}
+
+	result := &balanceResult{
 		Amount: key.Amount,
 		Money:  converter.EGSMoney(key.Amount),
+	}
+	if locked.GreaterThan(decimal.Zero) {
+		result.Locked = locked.String()
+		result.LockedMoney = converter.EGSMoney(result.Locked)
+	}
+	jsonResponse(w, result)
+}
+
+func getTokenBalance(w http.ResponseWriter, r *http.Request, ecosystemID, keyID int64, tokenName string) {
+	logger := getLogger(r)
+
+	var (
+		found bool
+		err   error
+	)
+	token := &model.Token{}
+	token.SetTablePrefix(ecosystemID)
+	if id := converter.StrToInt64(tokenName); id > 0 {
+		found, err = token.Get(nil, id)
+	} else {
+		found, err = token.GetBySymbol(nil, tokenName)
+	}
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting token")
+		errorResponse(w, err)
+		return
+	}
+	if !found {
+		errorResponse(w, errTokenNotFound.Errorf(tokenName))
+		return
+	}
+
+	balance := &model.TokenBalance{}
+	balance.SetTablePrefix(ecosystemID)
+	if _, err = balance.Get(nil, token.ID, keyID); err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting token balance")
+		errorResponse(w, err)
+		return
+	}
+
+	amount := balance.Amount.String()
+	jsonResponse(w, &balanceResult{
+		Amount: amount,
+		Money:  converter.DigitsMoney(amount, int(token.Decimals)),
+		Token:  token.Symbol,
 	})
 }
@@ -99,17 +99,51 @@ type TxInfo struct {
 }
 
 type blocksTxInfoForm struct {
+	cursorForm
 	BlockID int64 `schema:"block_id"`
 	Count   int64 `schema:"count"`
 }
 
 func (f *blocksTxInfoForm) Validate(r *http.Request) error {
+	if err := f.cursorForm.Validate(r); err != nil {
+		return err
+	}
+	if f.useCursor && f.Count <= 0 {
+		f.Count = defaultPaginatorLimit
+	}
+	if len(f.Cursor) > 0 {
+		var c blockCursor
+		if err := decodeCursor(f.Cursor, &c); err != nil {
+			return err
+		}
+		f.BlockID = c.ID
+		return nil
+	}
 	if f.BlockID > 0 {
 		f.BlockID--
 	}
 	return nil
 }
 
+// blocksCursorResult is returned instead of the map of blocks when the cursor parameter is specified
+type blocksCursorResult struct {
+	Blocks     interface{} `json:"blocks"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+}
+
+// blocksResponse sends the blocks, the next cursor is returned only if the page is full
+func blocksResponse(w http.ResponseWriter, form *blocksTxInfoForm, blocks []model.Block, result interface{}) {
+	if !form.useCursor {
+		jsonResponse(w, result)
+		return
+	}
+	ret := &blocksCursorResult{Blocks: result}
+	if len(blocks) > 0 && int64(len(blocks)) == form.Count {
+		ret.NextCursor = encodeCursor(blockCursor{ID: blocks[len(blocks)-1].ID})
+	}
+	jsonResponse(w, ret)
+}
+
 func getBlocksTxInfoHandler(w http.ResponseWriter, r *http.Request) {
 	form := &blocksTxInfoForm{}
 	if err := parseForm(r, form); err != nil {
@@ -126,7 +160,8 @@ func getBlocksTxInfoHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if len(blocks) == 0 {
+	// the empty page is returned at the end of the chain when the cursor is used
+	if len(blocks) == 0 && !form.useCursor {
 		errorResponse(w, errNotFound)
 		return
 	}
@@ -165,7 +200,7 @@ func getBlocksTxInfoHandler(w http.ResponseWriter, r *http.Request) {
 		result[blockModel.ID] = txInfoCollection
 	}
 
-	jsonResponse(w, &result)
+	blocksResponse(w, form, blocks, &result)
 }
 
 type TxDetailedInfo struct {
@@ -221,7 +256,8 @@ func getBlocksDetailedInfoHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if len(blocks) == 0 {
+	// the empty page is returned at the end of the chain when the cursor is used
+	if len(blocks) == 0 && !form.useCursor {
 		errorResponse(w, errNotFound)
 		return
 	}
@@ -283,5 +319,5 @@ func getBlocksDetailedInfoHandler(w http.ResponseWriter, r *http.Request) {
 		result[blockModel.ID] = bdi
 	}
 
-	jsonResponse(w, &result)
+	blocksResponse(w, form, blocks, &result)
 }
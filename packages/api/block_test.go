@@ -33,3 +33,30 @@ func TestGetBlockInfo(t *testing.T) {
 	err := sendGet(`block/1`, nil, &ret)
 	assert.NoError(t, err)
 }
+
+func TestGetBlocksCursor(t *testing.T) {
+	var ret blocksCursorResult
+	err := sendGet(`blocks?cursor=&count=1`, nil, &ret)
+	assert.NoError(t, err)
+	assert.Equal(t, encodeCursor(blockCursor{ID: 1}), ret.NextCursor)
+
+	var detailed struct {
+		Blocks     map[int64]BlockDetailedInfo `json:"blocks"`
+		NextCursor string                      `json:"next_cursor"`
+	}
+	err = sendGet(`detailed_blocks?count=1&cursor=`+ret.NextCursor, nil, &detailed)
+	assert.NoError(t, err)
+	assert.Contains(t, detailed.Blocks, int64(2))
+	assert.Equal(t, encodeCursor(blockCursor{ID: 2}), detailed.NextCursor)
+
+	var max maxBlockResult
+	assert.NoError(t, sendGet(`maxblockid`, nil, &max))
+	ret = blocksCursorResult{}
+	err = sendGet(`blocks?count=10&cursor=`+encodeCursor(blockCursor{ID: max.MaxBlockID}), nil, &ret)
+	assert.NoError(t, err)
+	assert.Empty(t, ret.NextCursor)
+	assert.Empty(t, ret.Blocks)
+
+	err = sendGet(`blocks?cursor=wrong`, nil, &ret)
+	assert.EqualError(t, err, `400 {"error":"E_FILTER","msg":"Parameter cursor has wrong format"}`)
+}
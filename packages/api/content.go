@@ -30,6 +30,7 @@ import (
 	"github.com/AplaProject/go-apla/packages/converter"
 	"github.com/AplaProject/go-apla/packages/crypto"
 	"github.com/AplaProject/go-apla/packages/model"
+	"github.com/AplaProject/go-apla/packages/pagecache"
 	"github.com/AplaProject/go-apla/packages/template"
 
 	"github.com/gorilla/mux"
@@ -44,6 +45,10 @@ type contentResult struct {
 	NodesCount int64           `json:"nodesCount,omitempty"`
 }
 
+type lintResult struct {
+	Errors []template.LintError `json:"errors"`
+}
+
 type hashResult struct {
 	Hash string `json:"hash"`
 }
@@ -154,6 +159,22 @@ func pageValue(r *http.Request) (*model.Page, string, error) {
 }
 
 func getPage(r *http.Request) (result *contentResult, err error) {
+	vars := initVars(r)
+	key := pagecache.Key{
+		Name:      mux.Vars(r)["name"],
+		Ecosystem: (*vars)["ecosystem_id"],
+		KeyID:     (*vars)["key_id"],
+		Role:      (*vars)["role_id"],
+		Lang:      (*vars)["lang"],
+		Params:    r.Form.Encode(),
+	}
+	if cached, ok := pagecache.Get(key); ok {
+		return cached.(*contentResult), nil
+	}
+	// the generation is taken before reading the data to skip caching if the block has been
+	// committed while rendering
+	generation := pagecache.Generation()
+
 	page, _, err := pageValue(r)
 	if err != nil {
 		return nil, err
@@ -169,6 +190,11 @@ func getPage(r *http.Request) (result *contentResult, err error) {
 		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting page menu")
 		return nil, errServer
 	}
+	deps := &template.Dependencies{Tables: map[string]bool{
+		page.TableName():                true,
+		menu.TableName():                true,
+		(&model.Language{}).TableName(): true,
+	}}
 	var wg sync.WaitGroup
 	var timeout bool
 	wg.Add(2)
@@ -176,14 +202,13 @@ func getPage(r *http.Request) (result *contentResult, err error) {
 	go func() {
 		defer wg.Done()
 
-		vars := initVars(r)
 		(*vars)["app_id"] = converter.Int64ToStr(page.AppID)
 
-		ret := template.Template2JSON(page.Value, &timeout, vars)
+		ret := template.Template2JSONDeps(page.Value, &timeout, vars, deps)
 		if timeout {
 			return
 		}
-		retmenu := template.Template2JSON(menu.Value, &timeout, vars)
+		retmenu := template.Template2JSONDeps(menu.Value, &timeout, vars, deps)
 		if timeout {
 			return
 		}
@@ -214,6 +239,13 @@ func getPage(r *http.Request) (result *contentResult, err error) {
 		return nil, errHeavyPage
 	}
 
+	if !deps.NoCache {
+		tables := make([]string, 0, len(deps.Tables))
+		for table := range deps.Tables {
+			tables = append(tables, table)
+		}
+		pagecache.Set(key, result, tables, generation)
+	}
 	return result, nil
 }
 
@@ -224,6 +256,11 @@ func getPageHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if r.FormValue("format") == "html" {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(template.JSON2HTML(result.Tree))
+		return
+	}
 	jsonResponse(w, result)
 }
 
@@ -327,6 +364,46 @@ func jsonContentHandler(w http.ResponseWriter, r *http.Request) {
 	jsonResponse(w, &contentResult{Tree: ret})
 }
 
+func lintContentHandler(w http.ResponseWriter, r *http.Request) {
+	form := &jsonContentForm{}
+	if err := parseForm(r, form); err != nil {
+		errorResponse(w, err, http.StatusBadRequest)
+		return
+	}
+
+	errs, err := template.Lint(nil, form.Template, getClient(r).EcosystemID)
+	if err != nil {
+		logger := getLogger(r)
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("checking template")
+		errorResponse(w, errServer)
+		return
+	}
+	if errs == nil {
+		errs = []template.LintError{}
+	}
+	jsonResponse(w, &lintResult{Errors: errs})
+}
+
+func getSchemaHandler(w http.ResponseWriter, r *http.Request) {
+	jsonResponse(w, template.Schema())
+}
+
+func getContractFormHandler(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	logger := getLogger(r)
+
+	contract := getContract(r, params["contract"])
+	if contract == nil {
+		logger.WithFields(log.Fields{"type": consts.ContractError, "contract_name": params["contract"]}).Error("contract name")
+		errorResponse(w, errContract.Errorf(params["contract"]))
+		return
+	}
+
+	var timeout bool
+	ret := template.Template2JSON(`ContractForm(Name: "`+contract.Name+`")`, &timeout, initVars(r))
+	jsonResponse(w, &contentResult{Tree: ret})
+}
+
 func getSourceHandler(w http.ResponseWriter, r *http.Request) {
 	page, _, err := pageValue(r)
 	if err != nil {
@@ -23,6 +23,7 @@ import (
 	"testing"
 
 	"github.com/AplaProject/go-apla/packages/crypto"
+	"github.com/AplaProject/go-apla/packages/template"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -52,6 +53,76 @@ func TestContentHash(t *testing.T) {
 	}
 }
 
+func TestContentHTML(t *testing.T) {
+	assert.NoError(t, keyLogin(1))
+
+	name := randName(`page`)
+	assert.NoError(t, postTx(`NewPage`, &url.Values{
+		"ApplicationId": {`1`},
+		"Name":          {name},
+		"Value":         {`Div(doc){P(Total: <b>#amount#</b>)}`},
+		"Menu":          {`default_menu`},
+		"Conditions":    {"true"},
+	}))
+	data, err := sendRawRequest("POST", "content/page/"+name+"?format=html", &url.Values{"amount": {"10"}})
+	assert.NoError(t, err)
+	assert.Equal(t, `<div class="doc"><p>Total: &lt;b&gt;10&lt;/b&gt;</p></div>`, string(data))
+
+	_, id, err := postTxResult(`RenderBinary`, &url.Values{
+		"ApplicationId": {`1`},
+		"Name":          {randName(`doc`)},
+		"Template":      {`Span(#name#)`},
+		"Params":        {`{"name": "Certificate"}`},
+	})
+	assert.NoError(t, err)
+	doc := `<span>Certificate</span>`
+	hash, err := crypto.Hash([]byte(doc))
+	assert.NoError(t, err)
+	data, err = sendRawRequest("GET", "data/1_binaries/"+id+"/data/"+hex.EncodeToString(hash), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, doc, string(data))
+}
+
+func TestContentForm(t *testing.T) {
+	assert.NoError(t, keyLogin(1))
+
+	var ret contentResult
+	assert.NoError(t, sendPost(`content/form/NewBlock`, &url.Values{}, &ret))
+	tree := string(ret.Tree)
+	assert.Contains(t, tree, `{"tag":"input","attr":{"class":"form-control","name":"ApplicationId","type":"number","validate":{"regexp":"^-?\\d+$","required":"true"}}}`)
+	assert.Contains(t, tree, `{"tag":"button","attr":{"class":"btn btn-primary","contract":"@1NewBlock"}`)
+
+	name := randName(`contract`)
+	assert.EqualError(t, sendPost(`content/form/`+name, &url.Values{}, &ret),
+		`404 {"error":"E_CONTRACT","msg":"There is not `+name+` contract"}`)
+}
+
+func TestContentLint(t *testing.T) {
+	assert.NoError(t, keyLogin(1))
+
+	var ret lintResult
+	assert.NoError(t, sendPost(`content/lint`, &url.Values{
+		"template": {"Div(){\n  DBFind(pages, src).Wher(id=1)\n  Include(" + randName(`block`) + ")\n}"},
+	}, &ret))
+	if assert.Len(t, ret.Errors, 2) {
+		assert.Equal(t, template.LintError{Line: 2, Column: 22, Message: `unknown tail Wher of DBFind`}, ret.Errors[0])
+		assert.Equal(t, 3, ret.Errors[1].Line)
+	}
+
+	name := randName(`page`)
+	form := url.Values{
+		"ApplicationId": {`1`},
+		"Name":          {name},
+		"Value":         {`Div(Body: Spn(text))`},
+		"Menu":          {`default_menu`},
+		"Conditions":    {"true"},
+	}
+	assert.EqualError(t, postTx(`NewPage`, &form),
+		`{"type":"warning","error":"Template error 1:11: unknown function Spn"}`)
+	form["Value"] = []string{`Div(Body: Span(text))`}
+	assert.NoError(t, postTx(`NewPage`, &form))
+}
+
 func TestContent(t *testing.T) {
 	assert.NoError(t, keyLogin(1))
 
@@ -155,3 +226,13 @@ func TestContent(t *testing.T) {
 		assert.Equal(t, v.expected, string(ret.Tree))
 	}
 }
+
+func TestContentSchema(t *testing.T) {
+	var ret struct {
+		Version     string                     `json:"version"`
+		Definitions map[string]json.RawMessage `json:"definitions"`
+	}
+	assert.NoError(t, sendGet(`content/schema`, nil, &ret))
+	assert.Equal(t, template.SchemaVersion, ret.Version)
+	assert.Contains(t, string(ret.Definitions[`tag_dbfind`]), `{"last":false,"name":"Where","params":[{"name":"Where","attr":"where"}],"tag":"where"}`)
+}
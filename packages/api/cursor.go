@@ -0,0 +1,137 @@
+// Copyright (C) 2017, 2018, 2019 EGAAS S.A.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or (at
+// your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but
+// WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301, USA.
+
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const cursorNull = `NULL`
+
+// listCursor is the state of the keyset pagination of /list. It contains the order
+// expression and the values of the order columns of the last returned row
+type listCursor struct {
+	Order  string   `json:"o"`
+	Values []string `json:"v"`
+}
+
+// blockCursor is the state of the pagination of /blocks and /detailed_blocks
+type blockCursor struct {
+	ID int64 `json:"id"`
+}
+
+type orderColumn struct {
+	name string
+	desc bool
+}
+
+type cursorForm struct {
+	Cursor string `schema:"cursor"`
+
+	useCursor bool
+}
+
+func (f *cursorForm) Validate(r *http.Request) error {
+	_, f.useCursor = r.Form["cursor"]
+	return nil
+}
+
+func encodeCursor(v interface{}) string {
+	data, _ := json.Marshal(v)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(in string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(in)
+	if err != nil {
+		return errFilter.Errorf(`cursor`)
+	}
+	if err = json.Unmarshal(data, v); err != nil {
+		return errFilter.Errorf(`cursor`)
+	}
+	return nil
+}
+
+// parseOrderColumns splits the order expression into the columns and their directions
+func parseOrderColumns(order string) []orderColumn {
+	columns := make([]orderColumn, 0)
+	for _, item := range strings.Split(order, `,`) {
+		fields := strings.Fields(item)
+		if len(fields) == 0 {
+			continue
+		}
+		columns = append(columns, orderColumn{
+			name: strings.Trim(fields[0], `"`),
+			desc: len(fields) > 1 && strings.ToLower(fields[1]) == `desc`,
+		})
+	}
+	return columns
+}
+
+func cursorLiteral(value string) string {
+	return `'` + strings.Replace(value, `'`, `''`, -1) + `'`
+}
+
+// equal returns the condition of the rows with the same value of the column.
+func (col orderColumn) equal(value string) string {
+	if value == cursorNull {
+		return fmt.Sprintf(`"%s" IS NULL`, col.name)
+	}
+	return fmt.Sprintf(`"%s" = %s`, col.name, cursorLiteral(value))
+}
+
+// after returns the condition of the rows which follow the value of the column in the sort order.
+// PostgreSQL places NULL values last in ascending order and first in descending order
+func (col orderColumn) after(value string) string {
+	if value == cursorNull {
+		if col.desc {
+			return fmt.Sprintf(`"%s" IS NOT NULL`, col.name)
+		}
+		return `false`
+	}
+	if col.desc {
+		return fmt.Sprintf(`"%s" < %s`, col.name, cursorLiteral(value))
+	}
+	return fmt.Sprintf(`("%s" > %s OR "%[1]s" IS NULL)`, col.name, cursorLiteral(value))
+}
+
+// cursorWhere returns the condition which selects the rows following the cursor
+func cursorWhere(columns []orderColumn, values []string) string {
+	conds := make([]string, 0, len(columns))
+	for i, col := range columns {
+		and := make([]string, 0, i+1)
+		for j, prev := range columns[:i] {
+			and = append(and, prev.equal(values[j]))
+		}
+		and = append(and, col.after(values[i]))
+		conds = append(conds, `(`+strings.Join(and, ` AND `)+`)`)
+	}
+	return strings.Join(conds, ` OR `)
+}
+
+// newListCursor returns the cursor pointing to the row
+func newListCursor(order string, columns []orderColumn, row map[string]string) string {
+	values := make([]string, len(columns))
+	for i, col := range columns {
+		values[i] = row[col.name]
+	}
+	return encodeCursor(listCursor{Order: order, Values: values})
+}
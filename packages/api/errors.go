@@ -25,6 +25,7 @@ import (
 var (
 	defaultStatus        = http.StatusBadRequest
 	ErrEcosystemNotFound = errors.New("Ecosystem not found")
+	errAssetNotFound     = errType{"E_ASSETNOTFOUND", "Asset %d has not been found", http.StatusNotFound}
 	errContract          = errType{"E_CONTRACT", "There is not %s contract", http.StatusNotFound}
 	errDBNil             = errType{"E_DBNIL", "DB is nil", defaultStatus}
 	errDeletedKey        = errType{"E_DELETEDKEY", "The key is deleted", http.StatusForbidden}
@@ -40,6 +41,7 @@ var (
 	errLimitForsign      = errType{"E_LIMITFORSIGN", "Length of forsign is too big (%d)", defaultStatus}
 	errLimitTxSize       = errType{"E_LIMITTXSIZE", "The size of tx is too big (%d)", defaultStatus}
 	errNotFound          = errType{"E_NOTFOUND", "Page not found", http.StatusNotFound}
+	errProposalNotFound  = errType{"E_PROPOSALNOTFOUND", "Proposal %d has not been found", http.StatusNotFound}
 	errParamNotFound     = errType{"E_PARAMNOTFOUND", "Parameter %s has not been found", http.StatusNotFound}
 	errPermission        = errType{"E_PERMISSION", "Permission denied", http.StatusUnauthorized}
 	errQuery             = errType{"E_QUERY", "DB query is wrong", http.StatusInternalServerError}
@@ -50,6 +52,7 @@ var (
 	errStateLogin        = errType{"E_STATELOGIN", "%s is not a membership of ecosystem %s", http.StatusForbidden}
 	errTableNotFound     = errType{"E_TABLENOTFOUND", "Table %s has not been found", http.StatusNotFound}
 	errToken             = errType{"E_TOKEN", "Token is not valid", defaultStatus}
+	errTokenNotFound     = errType{"E_TOKENNOTFOUND", "Token %s has not been found", http.StatusNotFound}
 	errTokenExpired      = errType{"E_TOKENEXPIRED", "Token is expired by %s", http.StatusUnauthorized}
 	errUnauthorized      = errType{"E_UNAUTHORIZED", "Unauthorized", http.StatusUnauthorized}
 	errUndefineval       = errType{"E_UNDEFINEVAL", "Value %s is undefined", defaultStatus}
@@ -64,6 +67,14 @@ var (
 	errBannded           = errType{"E_BANNED", "The key is banned till %s", http.StatusForbidden}
 	errCheckRole         = errType{"E_CHECKROLE", "Access denied", http.StatusForbidden}
 	errNewUser           = errType{"E_NEWUSER", "Can't create a new user", http.StatusUnauthorized}
+	errFilter            = errType{"E_FILTER", "Parameter %s has wrong format", http.StatusBadRequest}
+	errWebSocket         = errType{"E_WEBSOCKET", "Wrong websocket request", http.StatusBadRequest}
+	errCallback          = errType{"E_CALLBACK", "Callback url %s is wrong", http.StatusBadRequest}
+	errCallbackOff       = errType{"E_CALLBACKOFF", "Transaction callbacks are disabled", http.StatusBadRequest}
+	errAPIKeyRoute       = errType{"E_APIKEYROUTE", "Route is not allowed for API key", http.StatusForbidden}
+	errRateLimit         = errType{"E_RATELIMIT", "Too many requests", http.StatusTooManyRequests}
+	errRefreshToken      = errType{"E_REFRESHTOKEN", "Refresh token is not valid", http.StatusUnauthorized}
+	errRevokeToken       = errType{"E_REVOKETOKEN", "Token cannot be revoked", http.StatusBadRequest}
 )
 
 type errType struct {
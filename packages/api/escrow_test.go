@@ -0,0 +1,66 @@
+// Copyright (C) 2017, 2018, 2019 EGAAS S.A.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or (at
+// your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but
+// WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301, USA.
+
+package api
+
+import (
+	"encoding/hex"
+	"net/url"
+	"testing"
+
+	"github.com/AplaProject/go-apla/packages/crypto"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEscrow(t *testing.T) {
+	assert.NoError(t, keyLogin(1))
+
+	_, pub, err := crypto.GenBytesKeys()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NoError(t, postTx(`NewUser`, &url.Values{`NewPubkey`: {hex.EncodeToString(pub)}}))
+	recipient := crypto.KeyToAddress(pub)
+
+	var before balanceResult
+	assert.NoError(t, sendGet(`balance/`+recipient, nil, &before))
+
+	_, id, err := postTxResult(`EscrowLock`, &url.Values{
+		`Recipient`:   {recipient},
+		`Amount`:      {`100`},
+		`RefundBlock`: {`100000000`},
+		`Comment`:     {`escrow test`},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var ret balanceResult
+	assert.NoError(t, sendGet(`balance/`+gAddress, nil, &ret))
+	assert.NotEmpty(t, ret.Locked)
+
+	err = postTx(`EscrowRefund`, &url.Values{`EscrowId`: {id}})
+	assert.Error(t, err)
+
+	assert.NoError(t, postTx(`EscrowRelease`, &url.Values{`EscrowId`: {id}}))
+
+	assert.NoError(t, sendGet(`balance/`+recipient, nil, &ret))
+	assert.NotEqual(t, before.Amount, ret.Amount)
+
+	err = postTx(`EscrowRelease`, &url.Values{`EscrowId`: {id}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,579 @@
+// Copyright (C) 2017, 2018, 2019 EGAAS S.A.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or (at
+// your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but
+// WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301, USA.
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/AplaProject/go-apla/packages/consts"
+	"github.com/AplaProject/go-apla/packages/converter"
+	"github.com/AplaProject/go-apla/packages/graphql"
+	"github.com/AplaProject/go-apla/packages/model"
+	"github.com/AplaProject/go-apla/packages/types"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	gqlQueryType  = `Query`
+	gqlListSuffix = `_list`
+	gqlRefSuffix  = `_id`
+	gqlTypename   = `__typename`
+	gqlMaxDepth   = 8   // maximum nesting of the fields, every level of references is the query
+	gqlMaxFields  = 100 // maximum number of the fields with expanded fragments
+)
+
+type graphqlForm struct {
+	nopeValidator
+	Query         string `json:"query" schema:"query"`
+	OperationName string `json:"operationName" schema:"operationName"`
+	Variables     string `json:"-" schema:"variables"`
+
+	variables map[string]interface{}
+}
+
+type graphqlError struct {
+	Message    string            `json:"message"`
+	Path       []interface{}     `json:"path,omitempty"`
+	Extensions map[string]string `json:"extensions,omitempty"`
+}
+
+type graphqlResult struct {
+	Data   interface{}    `json:"data"`
+	Errors []graphqlError `json:"errors,omitempty"`
+}
+
+type gqlRef struct {
+	column string
+	table  string
+}
+
+// gqlTable describes the type of the ecosystem table
+type gqlTable struct {
+	name    string
+	columns []string
+	types   map[string]string
+	refs    map[string]gqlRef
+}
+
+type gqlSchema struct {
+	client *Client
+	tables map[string]*gqlTable
+	names  []string
+}
+
+type gqlExecutor struct {
+	schema *gqlSchema
+	doc    *graphql.Document
+	vars   map[string]interface{}
+	logger *log.Entry
+	errors []graphqlError
+}
+
+// newGqlSchema loads the list of the tables of the ecosystem. The columns of the tables are loaded
+// on the first usage of the table
+func newGqlSchema(client *Client) (*gqlSchema, error) {
+	tables, err := (&model.Table{}).GetAll(client.Prefix())
+	if err != nil {
+		return nil, err
+	}
+	schema := &gqlSchema{client: client, tables: make(map[string]*gqlTable)}
+	for _, item := range tables {
+		var columns map[string]string
+		if err = json.Unmarshal([]byte(item.Columns), &columns); err != nil {
+			return nil, err
+		}
+		tbl := &gqlTable{name: item.Name, columns: []string{`id`}}
+		for name := range columns {
+			if name != `id` {
+				tbl.columns = append(tbl.columns, name)
+			}
+		}
+		sort.Strings(tbl.columns[1:])
+		schema.tables[item.Name] = tbl
+		schema.names = append(schema.names, item.Name)
+	}
+	sort.Strings(schema.names)
+	for _, tbl := range schema.tables {
+		tbl.refs = make(map[string]gqlRef)
+		for _, col := range tbl.columns {
+			if !strings.HasSuffix(col, gqlRefSuffix) {
+				continue
+			}
+			field := strings.TrimSuffix(col, gqlRefSuffix)
+			if tbl.isColumn(field) {
+				continue
+			}
+			for _, name := range []string{field, field + `s`} {
+				if _, ok := schema.tables[name]; ok {
+					tbl.refs[field] = gqlRef{column: col, table: name}
+					break
+				}
+			}
+		}
+	}
+	return schema, nil
+}
+
+func (tbl *gqlTable) isColumn(name string) bool {
+	for _, col := range tbl.columns {
+		if col == name {
+			return true
+		}
+	}
+	return false
+}
+
+// table returns the description of the table with the types of the columns
+func (schema *gqlSchema) table(name string) (*gqlTable, error) {
+	tbl := schema.tables[name]
+	if tbl == nil || tbl.types != nil {
+		return tbl, nil
+	}
+	list, err := model.GetAllColumnTypes(converter.ParseTable(name, schema.client.EcosystemID))
+	if err != nil {
+		return nil, err
+	}
+	tbl.types = make(map[string]string)
+	for _, item := range list {
+		tbl.types[item["column_name"]] = model.DataTypeToColumnType(item["data_type"])
+	}
+	return tbl, nil
+}
+
+func gqlScalar(colType string) string {
+	switch colType {
+	case `number`:
+		return `BigInt`
+	case `money`:
+		return `Decimal`
+	case `double`:
+		return `Float`
+	case `datetime`:
+		return `DateTime`
+	case `json`:
+		return `JSON`
+	case `bytea`:
+		return `Bytes`
+	}
+	return `String`
+}
+
+// SDL returns the schema in GraphQL schema definition language
+func (schema *gqlSchema) SDL() (string, error) {
+	var out strings.Builder
+	for _, scalar := range []string{`BigInt`, `Bytes`, `DateTime`, `Decimal`, `JSON`} {
+		fmt.Fprintf(&out, "scalar %s\n", scalar)
+	}
+	out.WriteString("\ntype Query {\n")
+	for _, name := range schema.names {
+		fmt.Fprintf(&out, "  %s(id: ID!): %[1]s\n", name)
+		fmt.Fprintf(&out, "  %s%s(where: JSON, order: JSON, limit: Int, offset: Int, cursor: String): %[1]s%[2]s!\n",
+			name, gqlListSuffix)
+	}
+	out.WriteString("}\n")
+	for _, name := range schema.names {
+		tbl, err := schema.table(name)
+		if err != nil {
+			return ``, err
+		}
+		fmt.Fprintf(&out, "\ntype %s {\n  id: ID!\n", name)
+		for _, col := range tbl.columns[1:] {
+			fmt.Fprintf(&out, "  %s: %s\n", col, gqlScalar(tbl.types[col]))
+		}
+		refs := make([]string, 0, len(tbl.refs))
+		for field := range tbl.refs {
+			refs = append(refs, field)
+		}
+		sort.Strings(refs)
+		for _, field := range refs {
+			fmt.Fprintf(&out, "  %s: %s\n", field, tbl.refs[field].table)
+		}
+		out.WriteString("}\n")
+		fmt.Fprintf(&out, "\ntype %s%s {\n  count: BigInt!\n  next_cursor: String\n  list: [%[1]s!]!\n}\n",
+			name, gqlListSuffix)
+	}
+	return out.String(), nil
+}
+
+func (ex *gqlExecutor) fail(path []interface{}, err error) {
+	gerr := graphqlError{Message: err.Error(), Path: append([]interface{}{}, path...)}
+	if et, ok := err.(errType); ok {
+		gerr.Message = et.Message
+		gerr.Extensions = map[string]string{`code`: et.Err}
+	}
+	ex.errors = append(ex.errors, gerr)
+}
+
+func (ex *gqlExecutor) execute(op *graphql.Operation) *types.Map {
+	data := types.NewMap()
+	for _, field := range ex.doc.CollectFields(gqlQueryType, op.SelectionSet, ex.vars) {
+		key := field.ResponseKey()
+		path := []interface{}{key}
+		data.Set(key, nil)
+		if field.Name == gqlTypename {
+			data.Set(key, gqlQueryType)
+			continue
+		}
+		name := strings.TrimSuffix(field.Name, gqlListSuffix)
+		tbl, err := ex.schema.table(name)
+		if err != nil {
+			ex.fail(path, err)
+			continue
+		}
+		if tbl == nil || (name != field.Name && ex.schema.tables[field.Name] != nil) {
+			ex.fail(path, fmt.Errorf(`cannot query field %s on type %s`, field.Name, gqlQueryType))
+			continue
+		}
+		form, err := ex.listForm(field)
+		if err != nil {
+			ex.fail(path, err)
+			continue
+		}
+		if name == field.Name {
+			if form.where == nil {
+				ex.fail(path, fmt.Errorf(`argument id is required`))
+				continue
+			}
+			rows, _ := ex.rows(tbl, form, field.SelectionSet, path)
+			if len(rows) > 0 {
+				data.Set(key, rows[0])
+			}
+			continue
+		}
+		data.Set(key, ex.list(tbl, form, field.SelectionSet, path))
+	}
+	return data
+}
+
+// listForm returns the parameters of the list by the arguments of the field
+func (ex *gqlExecutor) listForm(field *graphql.Field) (*listForm, error) {
+	form := &listForm{}
+	isList := strings.HasSuffix(field.Name, gqlListSuffix) && ex.schema.tables[field.Name] == nil
+	for name, value := range graphql.Arguments(field.Arguments, ex.vars) {
+		if value == nil {
+			continue
+		}
+		switch {
+		case name == `id` && !isList:
+			form.where = types.LoadMap(map[string]interface{}{`id`: converter.StrToInt64(fmt.Sprint(value))})
+			form.Limit = 1
+		case name == `where` && isList:
+			switch v := value.(type) {
+			case string:
+				form.Where = v
+			case *types.Map:
+				form.where = v
+			default:
+				return nil, errFilter.Errorf(name)
+			}
+		case name == `order` && isList:
+			if v, ok := value.(string); ok {
+				form.Order = v
+			} else {
+				form.order = value
+			}
+		case (name == `limit` || name == `offset`) && isList:
+			var v int64
+			switch number := value.(type) {
+			case int64:
+				v = number
+			case float64:
+				v = int64(number)
+			default:
+				return nil, errFilter.Errorf(name)
+			}
+			if name == `limit` {
+				form.Limit = v
+			} else {
+				form.Offset = v
+			}
+		case name == `cursor` && isList:
+			v, ok := value.(string)
+			if !ok {
+				return nil, errFilter.Errorf(name)
+			}
+			form.Cursor = v
+		default:
+			return nil, fmt.Errorf(`unknown argument %s of field %s`, name, field.Name)
+		}
+	}
+	if err := form.Validate(&http.Request{}); err != nil {
+		return nil, err
+	}
+	return form, nil
+}
+
+func (ex *gqlExecutor) list(tbl *gqlTable, form *listForm, set []graphql.Selection, path []interface{}) *types.Map {
+	var (
+		rows    []*types.Map
+		result  *listResult
+		listSet []graphql.Selection
+	)
+	ret := types.NewMap()
+	fields := ex.doc.CollectFields(tbl.name+gqlListSuffix, set, ex.vars)
+	for _, field := range fields {
+		if field.Name == `list` {
+			listSet = append(listSet, field.SelectionSet...)
+		}
+	}
+	for _, field := range fields {
+		key := field.ResponseKey()
+		switch field.Name {
+		case gqlTypename:
+			ret.Set(key, tbl.name+gqlListSuffix)
+			continue
+		case `count`, `next_cursor`, `list`:
+		default:
+			ex.fail(append(path, key), fmt.Errorf(`cannot query field %s on type %s%s`, field.Name,
+				tbl.name, gqlListSuffix))
+			continue
+		}
+		if result == nil {
+			rows, result = ex.rows(tbl, form, listSet, append(path, key))
+			if result == nil {
+				return nil
+			}
+		}
+		switch field.Name {
+		case `count`:
+			ret.Set(key, result.Count)
+		case `next_cursor`:
+			if len(result.NextCursor) > 0 {
+				ret.Set(key, result.NextCursor)
+			} else {
+				ret.Set(key, nil)
+			}
+		case `list`:
+			list := make([]interface{}, len(rows))
+			for i, row := range rows {
+				list[i] = row
+			}
+			ret.Set(key, list)
+		}
+	}
+	return ret
+}
+
+func gqlValue(value, colType string) interface{} {
+	if value == `NULL` {
+		return nil
+	}
+	switch colType {
+	case `double`:
+		if v, err := strconv.ParseFloat(value, 64); err == nil {
+			return v
+		}
+	case `json`:
+		var v interface{}
+		if err := json.Unmarshal([]byte(value), &v); err == nil {
+			return v
+		}
+	}
+	return value
+}
+
+// rows selects the records of the table and resolves the selected fields of them
+func (ex *gqlExecutor) rows(tbl *gqlTable, form *listForm, set []graphql.Selection,
+	path []interface{}) ([]*types.Map, *listResult) {
+	fields := ex.doc.CollectFields(tbl.name, set, ex.vars)
+	columns := []string{`id`}
+	for _, field := range fields {
+		if ref, ok := tbl.refs[field.Name]; ok {
+			columns = append(columns, ref.column)
+		} else if field.Name != `id` && tbl.isColumn(field.Name) {
+			columns = append(columns, field.Name)
+		}
+	}
+	form.Columns = converter.EscapeName(strings.Join(columns, `,`))
+	lq, err := newListQuery(tbl.name, form, ex.schema.client)
+	if err != nil {
+		ex.fail(path, err)
+		return nil, nil
+	}
+	result, err := lq.getRows(tbl.name, form, ex.schema.client, ex.logger)
+	if err != nil {
+		ex.fail(path, err)
+		return nil, nil
+	}
+	allowed := make(map[string]bool)
+	for _, col := range strings.Split(form.Columns, `,`) {
+		allowed[strings.Trim(col, `"`)] = true
+	}
+	allowed[`id`] = true
+
+	rows := make([]*types.Map, len(result.List))
+	for i := range rows {
+		rows[i] = types.NewMap()
+	}
+	for _, field := range fields {
+		key := field.ResponseKey()
+		ref, isRef := tbl.refs[field.Name]
+		switch {
+		case field.Name == gqlTypename:
+			for _, row := range rows {
+				row.Set(key, tbl.name)
+			}
+			continue
+		case !isRef && !tbl.isColumn(field.Name):
+			ex.fail(append(path, key), fmt.Errorf(`cannot query field %s on type %s`, field.Name, tbl.name))
+			continue
+		}
+		column := field.Name
+		if isRef {
+			column = ref.column
+		}
+		if !allowed[column] {
+			for _, row := range rows {
+				row.Set(key, nil)
+			}
+			if len(rows) > 0 {
+				ex.fail(append(path, key), errPermission)
+			}
+			continue
+		}
+		if isRef {
+			ex.resolveRef(ref, field, rows, result.List, append(path, key))
+			continue
+		}
+		for i, row := range rows {
+			row.Set(key, gqlValue(result.List[i][column], tbl.types[column]))
+		}
+	}
+	return rows, result
+}
+
+// resolveRef selects the records referenced by the foreign id with the single query
+func (ex *gqlExecutor) resolveRef(ref gqlRef, field *graphql.Field, rows []*types.Map,
+	list []map[string]string, path []interface{}) {
+	key := field.ResponseKey()
+	ids := make([]interface{}, 0, len(list))
+	found := make(map[string]bool)
+	for _, item := range list {
+		id := item[ref.column]
+		if id != `NULL` && converter.StrToInt64(id) != 0 && !found[id] {
+			found[id] = true
+			ids = append(ids, converter.StrToInt64(id))
+		}
+	}
+	refRows := make(map[string]*types.Map)
+	if len(ids) > 0 {
+		tbl, err := ex.schema.table(ref.table)
+		if err != nil {
+			ex.fail(path, err)
+			return
+		}
+		form := &listForm{where: types.LoadMap(map[string]interface{}{
+			`id`: types.LoadMap(map[string]interface{}{`$in`: ids}),
+		})}
+		form.Limit = int64(len(ids))
+		selected, result := ex.rows(tbl, form, field.SelectionSet, path)
+		for i, row := range selected {
+			refRows[result.List[i][`id`]] = row
+		}
+	}
+	for i, row := range rows {
+		if refRow, ok := refRows[list[i][ref.column]]; ok {
+			row.Set(key, refRow)
+		} else {
+			row.Set(key, nil)
+		}
+	}
+}
+
+func (f *graphqlForm) Validate(r *http.Request) error {
+	if len(f.Variables) > 0 {
+		if err := json.Unmarshal([]byte(f.Variables), &f.variables); err != nil {
+			return errFilter.Errorf(`variables`)
+		}
+	}
+	return nil
+}
+
+func graphqlHandler(w http.ResponseWriter, r *http.Request) {
+	form := &graphqlForm{}
+	if strings.HasPrefix(r.Header.Get(contentType), `application/json`) {
+		var body struct {
+			graphqlForm
+			Variables map[string]interface{} `json:"variables"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			errorResponse(w, errFilter.Errorf(`body`), http.StatusBadRequest)
+			return
+		}
+		form = &body.graphqlForm
+		form.variables = body.Variables
+	} else if err := parseForm(r, form); err != nil {
+		errorResponse(w, err, http.StatusBadRequest)
+		return
+	}
+
+	client := getClient(r)
+	logger := getLogger(r)
+
+	doc, err := graphql.Parse(form.Query)
+	if err != nil {
+		jsonResponse(w, &graphqlResult{Errors: []graphqlError{{Message: err.Error()}}})
+		return
+	}
+	op, err := doc.Operation(form.OperationName)
+	if err == nil && op.Type != `query` {
+		err = fmt.Errorf(`%s operations are not supported`, op.Type)
+	}
+	if err == nil {
+		err = doc.CheckLimits(op.SelectionSet, gqlMaxDepth, gqlMaxFields)
+	}
+	if err != nil {
+		jsonResponse(w, &graphqlResult{Errors: []graphqlError{{Message: err.Error()}}})
+		return
+	}
+	vars, err := op.VariableValues(form.variables)
+	if err != nil {
+		jsonResponse(w, &graphqlResult{Errors: []graphqlError{{Message: err.Error()}}})
+		return
+	}
+	schema, err := newGqlSchema(client)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("Getting graphql schema")
+		errorResponse(w, err)
+		return
+	}
+	ex := &gqlExecutor{schema: schema, doc: doc, vars: vars, logger: logger}
+	data := ex.execute(op)
+	jsonResponse(w, &graphqlResult{Data: data, Errors: ex.errors})
+}
+
+func getGraphqlSchemaHandler(w http.ResponseWriter, r *http.Request) {
+	logger := getLogger(r)
+
+	schema, err := newGqlSchema(getClient(r))
+	if err == nil {
+		var sdl string
+		if sdl, err = schema.SDL(); err == nil {
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			w.Write([]byte(sdl))
+			return
+		}
+	}
+	logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("Getting graphql schema")
+	errorResponse(w, err)
+}
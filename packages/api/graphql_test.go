@@ -0,0 +1,118 @@
+// Copyright (C) 2017, 2018, 2019 EGAAS S.A.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or (at
+// your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but
+// WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301, USA.
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type graphqlTestResult struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []graphqlError  `json:"errors"`
+}
+
+func TestGraphQL(t *testing.T) {
+	assert.NoError(t, keyLogin(1))
+
+	name := randName(`gql`)
+	items := name + `items`
+	assert.NoError(t, postTx(`NewTable`, &url.Values{
+		"Name":          {name},
+		"Columns":       {`[{"name":"title","type":"varchar", "index": "0", "conditions":"true"}]`},
+		"Permissions":   {`{"insert": "true", "update" : "true", "new_column": "true"}`},
+		"ApplicationId": {"1"},
+	}))
+	assert.NoError(t, postTx(`NewTable`, &url.Values{
+		"Name": {items},
+		"Columns": {`[{"name":"` + name + `_id","type":"number", "index": "1", "conditions":"true"},
+			{"name":"amount","type":"number", "index": "0", "conditions":"true"},
+			{"name":"secret","type":"varchar", "index": "0", "conditions":"{\"update\":\"true\", \"read\":\"false\"}"}]`},
+		"Permissions":   {`{"insert": "true", "update" : "true", "new_column": "true", "read": "true"}`},
+		"ApplicationId": {"1"},
+	}))
+	assert.NoError(t, postTx(`NewContract`, &url.Values{
+		"Value": {`contract ` + name + ` {
+				action {
+					var id int
+					id = DBInsert("` + name + `", {title: "first"})
+					DBInsert("` + items + `", {` + name + `_id: id, amount: 10, secret: "a"})
+					DBInsert("` + items + `", {` + name + `_id: id, amount: 20, secret: "b"})
+					DBInsert("` + items + `", {amount: 30, secret: "c"})
+				}
+			}`},
+		"Conditions":    {`true`},
+		"ApplicationId": {"1"},
+	}))
+	assert.NoError(t, postTx(name, &url.Values{}))
+
+	sdl, err := sendRawRequest("GET", `graphql/schema`, nil)
+	assert.NoError(t, err)
+	assert.Contains(t, string(sdl), "type "+items+" {\n  id: ID!\n  amount: BigInt\n  "+name+"_id: BigInt\n  secret: String\n  "+
+		name+": "+name+"\n}")
+
+	var ret graphqlTestResult
+	query := `query Items($order: JSON) {
+		items: ` + items + `_list(where: "{\"amount\": {\"$lt\": 30}}", order: $order, limit: 1) {
+			count
+			list { amount parent: ` + name + ` { title } }
+			next_cursor
+		}
+		` + name + `(id: 1) { __typename title }
+	}`
+	assert.NoError(t, sendPost(`graphql`, &url.Values{
+		`query`:     {query},
+		`variables`: {`{"order": [{"amount": -1}]}`},
+	}, &ret))
+	assert.Empty(t, ret.Errors)
+	assert.Equal(t, `{"items":{"count":"2","list":[{"amount":"20","parent":{"title":"first"}}],"next_cursor":"`,
+		strings.SplitAfter(RawToString(ret.Data), `"next_cursor":"`)[0])
+	assert.Contains(t, RawToString(ret.Data), `"`+name+`":{"__typename":"`+name+`","title":"first"}}`)
+
+	ret = graphqlTestResult{}
+	assert.NoError(t, sendPost(`graphql`, &url.Values{
+		`query`: {`{ ` + items + `(id: 3) { amount secret unknown } }`},
+	}, &ret))
+	assert.Equal(t, `{"`+items+`":{"amount":"30","secret":null}}`, RawToString(ret.Data))
+	if assert.Len(t, ret.Errors, 2) {
+		assert.Equal(t, `Permission denied`, ret.Errors[0].Message)
+		assert.Equal(t, []interface{}{items, `secret`}, ret.Errors[0].Path)
+		assert.Equal(t, `cannot query field unknown on type `+items, ret.Errors[1].Message)
+	}
+
+	ret = graphqlTestResult{}
+	assert.NoError(t, sendPost(`graphql`, &url.Values{`query`: {`mutation { id }`}}, &ret))
+	assert.Equal(t, `mutation operations are not supported`, ret.Errors[0].Message)
+
+	ret = graphqlTestResult{}
+	assert.NoError(t, sendPost(`graphql`, &url.Values{
+		`query`: {`{ ...f } fragment f on Query { ` + items + `_list { list { ...f } } }`},
+	}, &ret))
+	assert.Equal(t, `fragment f spreads itself`, ret.Errors[0].Message)
+
+	ret = graphqlTestResult{}
+	assert.NoError(t, sendPost(`graphql`, &url.Values{
+		`query`: {`{ a: ` + items + `_list { count } ` + strings.Repeat(`...f `, 2) + `}
+			fragment f on Query { ` + strings.Repeat(`b: `+items+`_list(limit: 1000) { list { amount } } `, 50) + `}`},
+	}, &ret))
+	assert.Equal(t, fmt.Sprintf(`query has more than %d fields`, gqlMaxFields), ret.Errors[0].Message)
+}
@@ -17,15 +17,19 @@
 package api
 
 import (
+	"bytes"
+	"encoding/json"
 	"net/http"
 	"fmt"
 	"regexp"
 	"errors"
+	"strings"
 
 	"github.com/AplaProject/go-apla/packages/conf"
 	"github.com/AplaProject/go-apla/packages/consts"
 	"github.com/AplaProject/go-apla/packages/model"
 	"github.com/AplaProject/go-apla/packages/smart"
+	"github.com/AplaProject/go-apla/packages/types"
 	"github.com/AplaProject/go-apla/packages/utils/tx"
 	"github.com/AplaProject/go-apla/packages/converter"
 
@@ -34,13 +38,15 @@ import (
 )
 
 type listResult struct {
-	Count string              `json:"count"`
-	List  []map[string]string `json:"list"`
+	Count      string              `json:"count"`
+	List       []map[string]string `json:"list"`
+	NextCursor string              `json:"next_cursor,omitempty"`
 }
 
 type modelListResult struct {
 	Count string	   `json:"count"`
 	List  interface{}  `json:"list"`
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 type TableName struct {
@@ -61,17 +67,79 @@ func ToTableName(full string) (tableName TableName, err error) {
 type listForm struct {
 	paginatorForm
 	rowForm
+	cursorForm
+	Where string `schema:"where"`
+	Order string `schema:"order"`
+
+	where *types.Map
+	order interface{}
 }
 
 func (f *listForm) Validate(r *http.Request) error {
 	if err := f.paginatorForm.Validate(r); err != nil {
 		return err
 	}
+	if len(f.Where) > 0 {
+		var where interface{}
+		if err := json.Unmarshal([]byte(f.Where), &where); err != nil {
+			return errFilter.Errorf(`where`)
+		}
+		v, ok := types.ConvertMap(where).(*types.Map)
+		if !ok {
+			return errFilter.Errorf(`where`)
+		}
+		f.where = v
+	}
+	if len(f.Order) > 0 {
+		order, err := parseListOrder(f.Order)
+		if err != nil {
+			return errFilter.Errorf(`order`)
+		}
+		f.order = order
+	}
+	if err := f.cursorForm.Validate(r); err != nil {
+		return err
+	}
 	return f.rowForm.Validate(r)
 }
 
+// parseListOrder accepts the order either in the JSON format of DBFind().Order or
+// as a comma separated list of columns where '-' prefix means descending order
+func parseListOrder(in string) (interface{}, error) {
+	in = strings.TrimSpace(in)
+	if strings.HasPrefix(in, `[`) || strings.HasPrefix(in, `{`) {
+		var order interface{}
+		if err := json.Unmarshal([]byte(in), &order); err != nil {
+			return nil, err
+		}
+		return types.ConvertMap(order), nil
+	}
+	order := make([]interface{}, 0)
+	for _, item := range strings.Split(in, `,`) {
+		item = strings.TrimSpace(item)
+		if strings.HasPrefix(item, `-`) {
+			order = append(order, types.LoadMap(map[string]interface{}{item[1:]: -1}))
+		} else if len(item) > 0 {
+			order = append(order, item)
+		}
+	}
+	return order, nil
+}
+
 func checkAccess(tableName, columns string, client *Client) (table string, cols string, err error) {
-	sc := smart.SmartContract{
+	sc := newListContract(client)
+	table, _, cols, err = sc.CheckAccess(tableName, columns, client.EcosystemID)
+	return
+}
+
+func checkFilterAccess(table string, where *types.Map, order interface{},
+	client *Client) (string, string, error) {
+	sc := newListContract(client)
+	return sc.CheckFilterAccess(table, where, order)
+}
+
+func newListContract(client *Client) *smart.SmartContract {
+	return &smart.SmartContract{
 		OBS: conf.Config.IsSupportingOBS(),
 		VM:  smart.GetVM(),
 		TxSmart: tx.SmartContract{
@@ -82,8 +150,85 @@ func checkAccess(tableName, columns string, client *Client) (table string, cols
 			},
 		},
 	}
-	table, _, cols, err = sc.CheckAccess(tableName, columns, client.EcosystemID)
-	return
+}
+
+type listQuery struct {
+	table        string
+	where, order string
+	cursor       string
+	orderColumns []orderColumn
+}
+
+// newListQuery checks the access to the table and to the columns of the list and prepares
+// the conditions of the query
+func newListQuery(name string, form *listForm, client *Client) (*listQuery, error) {
+	var (
+		err error
+		lq  listQuery
+	)
+	lq.table, form.Columns, err = checkAccess(name, form.Columns, client)
+	if err != nil {
+		return nil, err
+	}
+	lq.where, lq.order, err = checkFilterAccess(lq.table, form.where, form.order, client)
+	if err != nil {
+		return nil, err
+	}
+	lq.orderColumns = parseOrderColumns(lq.order)
+	if len(form.Cursor) > 0 {
+		var c listCursor
+		if err = decodeCursor(form.Cursor, &c); err != nil {
+			return nil, err
+		}
+		if c.Order != lq.order || len(c.Values) != len(lq.orderColumns) {
+			return nil, errFilter.Errorf(`cursor`)
+		}
+		lq.cursor = cursorWhere(lq.orderColumns, c.Values)
+		form.Offset = 0
+	}
+	if len(form.Columns) > 0 {
+		for _, col := range lq.orderColumns {
+			if col.name != `id` && !strings.Contains(`,`+form.Columns+`,`, `,"`+col.name+`",`) {
+				form.Columns += `,"` + col.name + `"`
+			}
+		}
+	}
+	return &lq, nil
+}
+
+// getRows returns the count of the records matching the conditions and the page of the list
+func (lq *listQuery) getRows(name string, form *listForm, client *Client, logger *log.Entry) (*listResult, error) {
+	q := model.GetTableQuery(name, client.EcosystemID)
+	if len(lq.where) > 0 {
+		q = q.Where(lq.where)
+	}
+	if len(form.Columns) > 0 {
+		q = q.Select("id," + form.Columns)
+	}
+
+	result := new(listResult)
+	err := q.Count(&result.Count).Error
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err, "table": lq.table}).Error("Getting table records count")
+		return nil, errTableNotFound.Errorf(lq.table)
+	}
+
+	if len(lq.cursor) > 0 {
+		q = q.Where(`(` + lq.cursor + `)`)
+	}
+	rows, err := q.Order(lq.order).Offset(form.Offset).Limit(form.Limit).Rows()
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err, "table": lq.table}).Error("Getting rows from table")
+		return nil, err
+	}
+	result.List, err = model.GetResult(rows)
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(result.List)) == form.Limit {
+		result.NextCursor = newListCursor(lq.order, lq.orderColumns, result.List[len(result.List)-1])
+	}
+	return result, nil
 }
 
 func getListHandler(w http.ResponseWriter, r *http.Request) {
@@ -97,51 +242,37 @@ func getListHandler(w http.ResponseWriter, r *http.Request) {
 	client := getClient(r)
 	logger := getLogger(r)
 
-	var (
-		err   error
-		table string
-	)
-	table, form.Columns, err = checkAccess(params["name"], form.Columns, client)
+	lq, err := newListQuery(params["name"], form, client)
 	if err != nil {
 		errorResponse(w, err)
 		return
 	}
-	q := model.GetTableQuery(params["name"], client.EcosystemID)
-
-	if len(form.Columns) > 0 {
-		q = q.Select("id," + form.Columns)
-	}
 
 	tableName, err := ToTableName(params["name"])
 	if err != nil {
 		errorResponse(w, err)
 	}
-	order := "id ASC"
 	switch tableName.suffix {
 	case "keys":
-		list := model.GetKeys(tableName.prefix, order, form.Limit, form.Offset)
-		cnt, err := model.GetKeysCount()
+		keysWhere := lq.where
+		if len(lq.cursor) > 0 {
+			keysWhere = `(` + lq.cursor + `)`
+			if len(lq.where) > 0 {
+				keysWhere = `(` + lq.where + `) AND ` + keysWhere
+			}
+		}
+		list := model.GetKeys(tableName.prefix, keysWhere, lq.order, form.Limit, form.Offset)
+		cnt, err := model.GetKeysCount(lq.where)
 		if err != nil {
 		    errorResponse(w, err)
 		}
 		result := modelListResult{Count: converter.Int64ToStr(cnt), List: list}
+		if int64(len(list)) == form.Limit {
+			result.NextCursor = newListCursor(lq.order, lq.orderColumns, keyRow(list[len(list)-1]))
+		}
 		jsonResponse(w, result)
 	default:
-		result := new(listResult)
-		err = q.Count(&result.Count).Error
-		if err != nil {
-			logger.WithFields(log.Fields{"type": consts.DBError, "error": err, "table": table}).Error("Getting table records count")
-			errorResponse(w, errTableNotFound.Errorf(table))
-			return
-		}
-
-		rows, err := q.Order(order).Offset(form.Offset).Limit(form.Limit).Rows()
-		if err != nil {
-			logger.WithFields(log.Fields{"type": consts.DBError, "error": err, "table": table}).Error("Getting rows from table")
-			errorResponse(w, err)
-			return
-		}
-		result.List, err = model.GetResult(rows)
+		result, err := lq.getRows(params["name"], form, client, logger)
 		if err != nil {
 			errorResponse(w, err)
 			return
@@ -149,3 +280,22 @@ func getListHandler(w http.ResponseWriter, r *http.Request) {
 		jsonResponse(w, result)
 	}
 }
+
+// keyRow returns the values of the key record by the column names
+func keyRow(key model.Key) map[string]string {
+	row := make(map[string]string)
+	data, err := json.Marshal(key)
+	if err != nil {
+		return row
+	}
+	var values map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err = decoder.Decode(&values); err != nil {
+		return row
+	}
+	for name, value := range values {
+		row[name] = fmt.Sprint(value)
+	}
+	return row
+}
@@ -18,6 +18,7 @@ package api
 
 import (
 	"fmt"
+	"net/url"
 	"testing"
 
 	"github.com/AplaProject/go-apla/packages/converter"
@@ -66,3 +67,88 @@ func TestList(t *testing.T) {
 		return
 	}
 }
+
+func TestListFilter(t *testing.T) {
+	if err := keyLogin(1); err != nil {
+		t.Error(err)
+		return
+	}
+	var ret listResult
+	err := sendGet(`list/contracts?`+url.Values{
+		`columns`: {`name`},
+		`where`:   {`{"id": {"$lt": 4}}`},
+		`order`:   {`-id`},
+	}.Encode(), nil, &ret)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if ret.Count != `3` || len(ret.List) != 3 || ret.List[0][`id`] != `3` || ret.List[2][`id`] != `1` {
+		t.Errorf(`wrong list %v`, ret)
+		return
+	}
+	err = sendGet(`list/contracts?`+url.Values{
+		`where`: {`{"$or": [{"id": 1}, {"id": 2}]}`},
+		`order`: {`[{"id": -1}]`},
+	}.Encode(), nil, &ret)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if ret.Count != `2` || len(ret.List) != 2 || ret.List[0][`id`] != `2` {
+		t.Errorf(`wrong list %v`, ret)
+		return
+	}
+	err = sendGet(`list/contracts?`+url.Values{`where`: {`{"id":`}}.Encode(), nil, &ret)
+	if err == nil || err.Error() != `400 {"error":"E_FILTER","msg":"Parameter where has wrong format"}` {
+		t.Errorf(`wrong error %v`, err)
+	}
+}
+
+func TestListCursor(t *testing.T) {
+	if err := keyLogin(1); err != nil {
+		t.Error(err)
+		return
+	}
+	var first, next listResult
+	err := sendGet(`list/contracts?`+url.Values{
+		`columns`: {`value`},
+		`order`:   {`-name`},
+		`limit`:   {`2`},
+	}.Encode(), nil, &first)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(first.List) != 2 || len(first.NextCursor) == 0 || len(first.List[1][`name`]) == 0 {
+		t.Errorf(`wrong list %v`, first)
+		return
+	}
+	err = sendGet(`list/contracts?`+url.Values{
+		`columns`: {`value`},
+		`order`:   {`-name`},
+		`limit`:   {`2`},
+		`cursor`:  {first.NextCursor},
+	}.Encode(), nil, &next)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if next.Count != first.Count || len(next.List) == 0 || next.List[0][`name`] >= first.List[1][`name`] {
+		t.Errorf(`wrong next page %v`, next)
+		return
+	}
+	err = sendGet(`list/contracts?`+url.Values{`cursor`: {first.NextCursor}}.Encode(), nil, &next)
+	if err == nil || err.Error() != `400 {"error":"E_FILTER","msg":"Parameter cursor has wrong format"}` {
+		t.Errorf(`wrong error %v`, err)
+	}
+}
+
+func TestCursorWhere(t *testing.T) {
+	columns := parseOrderColumns(`"name" desc,"amount",id`)
+	where := cursorWhere(columns, []string{`o'k`, `NULL`, `5`})
+	if where != `("name" < 'o''k') OR ("name" = 'o''k' AND false) OR `+
+		`("name" = 'o''k' AND "amount" IS NULL AND ("id" > '5' OR "id" IS NULL))` {
+		t.Errorf(`wrong where %s`, where)
+	}
+}
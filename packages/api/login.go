@@ -74,6 +74,7 @@ func (f *loginForm) Validate(r *http.Request) error {
 
 type loginResult struct {
 	Token       string        `json:"token,omitempty"`
+	Refresh     string        `json:"refresh,omitempty"`
 	EcosystemID string        `json:"ecosystem_id,omitempty"`
 	KeyID       string        `json:"key_id,omitempty"`
 	Account     string        `json:"account,omitempty"`
@@ -288,9 +289,8 @@ func (m Mode) loginHandler(w http.ResponseWriter, r *http.Request) {
 		errorResponse(w, err)
 		return
 	}
-	claims.StandardClaims.ExpiresAt = time.Now().Add(time.Hour * 30 * 24).Unix()
-	if err != nil {
-		logger.WithFields(log.Fields{"type": consts.JWTError, "error": err}).Error("generating jwt token")
+	if result.Refresh, err = generateRefreshToken(claims); err != nil {
+		logger.WithFields(log.Fields{"type": consts.JWTError, "error": err}).Error("generating refresh token")
 		errorResponse(w, err)
 		return
 	}
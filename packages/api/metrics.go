@@ -23,6 +23,8 @@ import (
 	"github.com/AplaProject/go-apla/packages/conf/syspar"
 	"github.com/AplaProject/go-apla/packages/consts"
 	"github.com/AplaProject/go-apla/packages/model"
+	"github.com/AplaProject/go-apla/packages/pagecache"
+	"github.com/AplaProject/go-apla/packages/ratelimit"
 	"github.com/AplaProject/go-apla/packages/service"
 
 	log "github.com/sirupsen/logrus"
@@ -103,7 +105,7 @@ func (m Mode) ecosysCountHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 func keysCountHandler(w http.ResponseWriter, r *http.Request) {
-	cnt, err := model.GetKeysCount()
+	cnt, err := model.GetKeysCount(``)
 	if err != nil {
 		logger := getLogger(r)
 		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("on getting keys count")
@@ -143,3 +145,11 @@ func banStatHandler(w http.ResponseWriter, _ *http.Request) {
 
 	jsonResponse(w, list)
 }
+
+func pageCacheStatHandler(w http.ResponseWriter, _ *http.Request) {
+	jsonResponse(w, pagecache.GetStats())
+}
+
+func rateLimitStatHandler(w http.ResponseWriter, _ *http.Request) {
+	jsonResponse(w, ratelimit.GetStats())
+}
@@ -18,11 +18,17 @@ package api
 
 import (
 	"fmt"
+	"math"
+	"net"
 	"net/http"
 	"runtime/debug"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/AplaProject/go-apla/packages/consts"
+	"github.com/AplaProject/go-apla/packages/publisher"
+	"github.com/AplaProject/go-apla/packages/ratelimit"
 	"github.com/AplaProject/go-apla/packages/service"
 	"github.com/AplaProject/go-apla/packages/statsd"
 
@@ -101,13 +107,41 @@ func tokenMiddleware(next http.Handler) http.Handler {
 	const authHeader = "AUTHORIZATION"
 
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		token, err := parseJWTToken(r.Header.Get(authHeader))
+		header := r.Header.Get(authHeader)
+		if strings.HasPrefix(header, apiKeyPrefix) {
+			token, err := parseAPIKey(r, header[len(apiKeyPrefix):])
+			if err != nil {
+				errorResponse(w, err)
+				return
+			}
+			if token != nil {
+				r = setToken(r, token)
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(header) == 0 && publisher.IsWebSocket(r) {
+			// browsers can't set headers of websocket requests
+			if v := r.URL.Query().Get("token"); len(v) > 0 {
+				header = jwtPrefix + v
+			}
+		}
+		token, err := parseJWTToken(header)
 		if err != nil {
 			logger := getLogger(r)
 			logger.WithFields(log.Fields{"type": consts.JWTError, "error": err}).Error("starting session")
 		}
 		if token != nil && token.Valid {
-			r = setToken(r, token)
+			active, err := isTokenActive(token)
+			if err != nil {
+				logger := getLogger(r)
+				logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("checking revoked token")
+				errorResponse(w, errServer)
+				return
+			}
+			if active {
+				r = setToken(r, token)
+			}
 		}
 		next.ServeHTTP(w, r)
 	})
@@ -133,6 +167,55 @@ func (m Mode) clientMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// routeGroup returns the first part of the route template which is used as the group of routes
+func routeGroup(tpl string) string {
+	return strings.SplitN(strings.TrimPrefix(strings.TrimPrefix(tpl, openAPIPrefix), `/`), `/`, 2)[0]
+}
+
+func remoteIP(r *http.Request) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return ip
+}
+
+func rateLimitResponse(w http.ResponseWriter, r *http.Request, ip, group string, retry time.Duration) {
+	logger := getLogger(r)
+	logger.WithFields(log.Fields{"type": consts.ParameterExceeded, "ip": ip, "group": group}).Warning("too many requests")
+	w.Header().Set("Retry-After", strconv.FormatInt(int64(math.Ceil(retry.Seconds())), 10))
+	errorResponse(w, errRateLimit)
+}
+
+// ipRateLimitMiddleware limits the requests of the address before the token is checked in the database
+func ipRateLimitMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip := remoteIP(r)
+		if ok, retry := ratelimit.AllowIP(ip); !ok {
+			rateLimitResponse(w, r, ip, ratelimit.IPGroup, retry)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func rateLimitMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var group string
+		if route := mux.CurrentRoute(r); route != nil {
+			if tpl, err := route.GetPathTemplate(); err == nil {
+				group = routeGroup(tpl)
+			}
+		}
+		ip := remoteIP(r)
+		if ok, retry := ratelimit.Allow(ip, getClient(r).KeyID, group); !ok {
+			rateLimitResponse(w, r, ip, group, retry)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
 func statsdMiddleware(next http.Handler) http.Handler {
 	const v = 1.0
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,504 @@
+// Copyright (C) 2017, 2018, 2019 EGAAS S.A.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or (at
+// your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but
+// WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301, USA.
+
+package api
+
+import (
+	"encoding"
+	"encoding/json"
+	"net/http"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/AplaProject/go-apla/packages/consts"
+	"github.com/AplaProject/go-apla/packages/model"
+	"github.com/AplaProject/go-apla/packages/pagecache"
+	"github.com/AplaProject/go-apla/packages/ratelimit"
+
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	openAPIVersion = `3.0.0`
+	openAPIPrefix  = `/api/v2`
+	openAPIAuth    = `bearer`
+	openAPIKeyAuth = `apiKey`
+)
+
+// routeDoc describes the route in OpenAPI specification
+type routeDoc struct {
+	Summary     string
+	Public      bool        // the route doesn't require the authorization
+	Form        interface{} // the form which is decoded by parseForm
+	Params      []paramDoc  // the parameters which are read without the form
+	Result      interface{} // the value which is passed to jsonResponse
+	ContentType string      // the content type of the response if it isn't JSON
+}
+
+type paramDoc struct {
+	Name        string
+	Type        string
+	Description string
+}
+
+var (
+	stringParam = `string`
+	intParam    = `integer`
+	boolParam   = `boolean`
+	fileParam   = `file`
+)
+
+// apiDocs contains the description of the routes, the key is the method and the path template
+var apiDocs = map[string]routeDoc{
+	"GET /data/{prefix}_binaries/{id}/data/{hash}": {Summary: "Returns the content of the binary",
+		Public: true, ContentType: "application/octet-stream"},
+	"GET /data/{table}/{id}/{column}/{hash}": {Summary: "Returns the value of the column as a file",
+		Public: true, ContentType: "application/octet-stream"},
+	"GET /avatar/{ecosystem}/{account}": {Summary: "Returns the avatar of the member",
+		Public: true, ContentType: "application/octet-stream"},
+	"GET /auth/status": {Summary: "Returns the status of the authorization", Public: true,
+		Result: authStatusResponse{}},
+	"GET /contract/{name}":  {Summary: "Returns the information about the contract", Result: getContractResult{}},
+	"GET /contracts":        {Summary: "Returns the list of contracts", Form: paginatorForm{}, Result: listResult{}},
+	"GET /getuid":           {Summary: "Returns the unique value for signing in", Public: true, Result: getUIDResult{}},
+	"GET /keyinfo/{wallet}": {Summary: "Returns the ecosystems and roles of the key", Public: true, Result: keyInfoResult{}},
+	"GET /list/{name}":      {Summary: "Returns the rows of the table", Form: listForm{}, Result: listResult{}},
+	"GET /graphql": {Summary: "Executes GraphQL query over the tables of the ecosystem", Form: graphqlForm{},
+		Result: graphqlResult{}},
+	"POST /graphql": {Summary: "Executes GraphQL query over the tables of the ecosystem", Form: graphqlForm{},
+		Result: graphqlResult{}},
+	"GET /graphql/schema": {Summary: "Returns GraphQL schema of the ecosystem", ContentType: "text/plain"},
+	"GET /ws": {Summary: "Opens the websocket connection for the subscriptions",
+		Params: []paramDoc{{"token", stringParam, "JWT token if Authorization header can't be set"}}},
+	"GET /network":                  {Summary: "Returns the parameters of the network", Public: true, Result: NetworkResult{}},
+	"GET /sections":                 {Summary: "Returns the sections of the ecosystem", Form: sectionsForm{}, Result: listResult{}},
+	"GET /row/{name}/{id}":          {Summary: "Returns the row of the table", Form: rowForm{}, Result: rowResult{}},
+	"GET /row/{name}/{column}/{id}": {Summary: "Returns the row of the table by the column", Form: rowForm{}, Result: rowResult{}},
+	"GET /interface/page/{name}":    {Summary: "Returns the page", Result: model.Page{}},
+	"GET /interface/menu/{name}":    {Summary: "Returns the menu", Result: model.Menu{}},
+	"GET /interface/block/{name}":   {Summary: "Returns the block", Result: model.BlockInterface{}},
+	"GET /table/{name}":             {Summary: "Returns the description of the table", Result: tableResult{}},
+	"GET /tables":                   {Summary: "Returns the list of tables", Form: paginatorForm{}, Result: tablesResult{}},
+	"GET /test/{name}":              {Summary: "Returns the test value", Public: true, Result: getTestResult{}},
+	"POST /test/{name}":             {Summary: "Returns the test value", Public: true, Result: getTestResult{}},
+	"GET /version":                  {Summary: "Returns the version of the node", Public: true, Result: ""},
+	"GET /config/{option}":          {Summary: "Returns the option of the node config", Public: true, Result: ""},
+	"GET /page/validators_count/{name}": {Summary: "Returns the number of validators of the page", Public: true,
+		Result: map[string]int64{}},
+	"POST /content/source/{name}": {Summary: "Returns the tree of the page source", Result: contentResult{}},
+	"POST /content/page/{name}": {Summary: "Returns the tree of the page", Result: contentResult{},
+		Params: []paramDoc{{"format", stringParam, "html returns the page as HTML"}}},
+	"POST /content/hash/{name}": {Summary: "Returns the hash of the page", Public: true, Result: hashResult{},
+		Params: []paramDoc{{"ecosystem", intParam, "Ecosystem of the page"}}},
+	"POST /content/menu/{name}":     {Summary: "Returns the tree of the menu", Result: contentResult{}},
+	"POST /content/form/{contract}": {Summary: "Returns the form of the contract", Result: contentResult{}},
+	"POST /content":                 {Summary: "Returns the tree of the template", Public: true, Form: jsonContentForm{}, Result: contentResult{}},
+	"POST /content/lint":            {Summary: "Checks the template", Form: jsonContentForm{}, Result: lintResult{}},
+	"GET /content/schema":           {Summary: "Returns the schema of the template functions", Public: true, Result: map[string]interface{}{}},
+	"POST /login":                   {Summary: "Signs in and returns JWT token", Public: true, Form: loginForm{}, Result: loginResult{}},
+	"POST /auth/refresh": {Summary: "Exchanges the refresh token for the new pair of tokens", Public: true,
+		Form: refreshForm{}, Result: refreshResult{}},
+	"POST /auth/revoke": {Summary: "Revokes the token of the request and the refresh token", Form: revokeForm{},
+		Result: revokeResult{}},
+	"POST /sendTx": {Summary: "Sends the transactions", Result: sendTxResult{},
+		Params: []paramDoc{
+			{"<key>", fileParam, "Binary transaction, hex encoded one can be sent as a value"},
+			{callbackParam, stringParam, "Url which receives the statuses of all transactions"},
+			{callbackParam + ".<key>", stringParam, "Url which receives the status of the transaction"},
+		}},
+	"POST /node/{name}": {Summary: "Calls the contract of the node", Public: true},
+	"POST /txstatus": {Summary: "Returns the statuses of the transactions", Result: multiTxStatusResult{},
+		Params: []paramDoc{{"data", stringParam, `JSON object {"hashes": [...]}`}}},
+	"GET /metrics/blocks":       {Summary: "Returns the number of blocks", Public: true, Result: blockMetric{}},
+	"GET /metrics/transactions": {Summary: "Returns the number of transactions", Public: true, Result: txMetric{}},
+	"GET /metrics/ecosystems":   {Summary: "Returns the number of ecosystems", Public: true, Result: ecosysMetric{}},
+	"GET /metrics/keys":         {Summary: "Returns the number of keys", Public: true, Result: keyMetric{}},
+	"GET /metrics/mem":          {Summary: "Returns the memory usage", Public: true, Result: memMetric{}},
+	"GET /metrics/ban":          {Summary: "Returns the banned nodes", Public: true, Result: []banMetric{}},
+	"GET /metrics/pagecache":    {Summary: "Returns the statistics of the page cache", Public: true, Result: pagecache.Stats{}},
+	"GET /metrics/ratelimit":    {Summary: "Returns the numbers of rejected requests", Public: true, Result: ratelimit.Stats{}},
+	"GET /metrics/fullnodes":    {Summary: "Returns the number of full nodes", Public: true, Result: fullNodeMetric{}},
+	"GET /txinfo/{hash}":        {Summary: "Returns the information about the transaction", Form: txInfoForm{}, Result: txinfoResult{}},
+	"GET /txinfomultiple":       {Summary: "Returns the information about the transactions", Form: txInfoForm{}, Result: multiTxInfoResult{}},
+	"GET /account/{wallet}/transactions": {Summary: "Returns the transactions sent by the key", Form: txIndexForm{},
+		Result: txIndexResult{}},
+	"GET /contract/{name}/transactions": {Summary: "Returns the transactions which have called the contract",
+		Form: txIndexForm{}, Result: txIndexResult{}},
+	"GET /appparam/{appID}/{name}": {Summary: "Returns the parameter of the application", Form: ecosystemForm{},
+		Result: paramResult{}},
+	"GET /appparams/{appID}": {Summary: "Returns the parameters of the application", Form: appParamsForm{},
+		Result: appParamsResult{}},
+	"GET /appcontent/{appID}": {Summary: "Returns the pages, blocks and contracts of the application", Form: appParamsForm{},
+		Result: appContentResult{}},
+	"GET /history/{name}/{id}":   {Summary: "Returns the history of the row", Result: historyResult{}},
+	"GET /balance/{wallet}":      {Summary: "Returns the balance of the wallet", Form: balanceForm{}, Result: balanceResult{}},
+	"GET /asset/{id}":            {Summary: "Returns the asset", Result: assetResult{}},
+	"GET /asset/{id}/history":    {Summary: "Returns the owners of the asset", Result: assetHistoryResult{}},
+	"GET /proposals":             {Summary: "Returns the list of proposals", Form: proposalsForm{}, Result: proposalsResult{}},
+	"GET /proposal/{id}":         {Summary: "Returns the proposal with the votes", Result: proposalResult{}},
+	"GET /block/{id}":            {Summary: "Returns the block", Public: true, Result: blockInfoResult{}},
+	"GET /maxblockid":            {Summary: "Returns the id of the last block", Public: true, Result: maxBlockResult{}},
+	"GET /blocks":                {Summary: "Returns the transactions of the blocks", Public: true, Form: blocksTxInfoForm{}, Result: map[int64][]TxInfo{}},
+	"GET /detailed_blocks":       {Summary: "Returns the detailed blocks", Public: true, Form: blocksTxInfoForm{}, Result: map[int64]BlockDetailedInfo{}},
+	"GET /ecosystemparams":       {Summary: "Returns the parameters of the ecosystem", Form: appParamsForm{}, Result: ecosystemParamsResult{}},
+	"GET /systemparams":          {Summary: "Returns the system parameters", Form: paramsForm{}, Result: ecosystemParamsResult{}},
+	"GET /ecosystemparam/{name}": {Summary: "Returns the parameter of the ecosystem", Form: ecosystemForm{}, Result: paramResult{}},
+	"GET /ecosystemname": {Summary: "Returns the name of the ecosystem", Public: true,
+		Params: []paramDoc{{"id", intParam, "Ecosystem identifier"}},
+		Result: struct {
+			EcosystemName string `json:"ecosystem_name"`
+		}{}},
+	"GET /openapi.json": {Summary: "Returns OpenAPI specification of the API", Public: true, Result: map[string]interface{}{}},
+}
+
+type openAPISpec struct {
+	OpenAPI    string                           `json:"openapi"`
+	Info       openAPIInfo                      `json:"info"`
+	Servers    []openAPIServer                  `json:"servers"`
+	Paths      map[string]map[string]*openAPIOp `json:"paths"`
+	Components openAPIComponents                `json:"components"`
+}
+
+type openAPIInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type openAPIServer struct {
+	URL string `json:"url"`
+}
+
+type openAPIComponents struct {
+	Schemas         map[string]openAPISchema `json:"schemas"`
+	SecuritySchemes map[string]openAPISchema `json:"securitySchemes"`
+}
+
+type openAPIOp struct {
+	Summary     string                     `json:"summary"`
+	Tags        []string                   `json:"tags"`
+	Parameters  []openAPIParam             `json:"parameters,omitempty"`
+	RequestBody *openAPIBody               `json:"requestBody,omitempty"`
+	Responses   map[string]openAPIResponse `json:"responses"`
+	Security    []map[string][]string      `json:"security,omitempty"`
+}
+
+type openAPIParam struct {
+	Name        string        `json:"name"`
+	In          string        `json:"in"`
+	Description string        `json:"description,omitempty"`
+	Required    bool          `json:"required,omitempty"`
+	Schema      openAPISchema `json:"schema"`
+}
+
+type openAPIBody struct {
+	Content map[string]openAPIMedia `json:"content"`
+}
+
+type openAPIResponse struct {
+	Description string                  `json:"description"`
+	Content     map[string]openAPIMedia `json:"content,omitempty"`
+}
+
+type openAPIMedia struct {
+	Schema openAPISchema `json:"schema"`
+}
+
+type openAPISchema map[string]interface{}
+
+var (
+	rawMessageType    = reflect.TypeOf(json.RawMessage{})
+	timeType          = reflect.TypeOf(time.Time{})
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// schemaBuilder makes JSON schemas of Go types, named structs are placed in the components
+type schemaBuilder struct {
+	schemas map[string]openAPISchema
+	names   map[reflect.Type]string
+}
+
+func newSchemaBuilder() *schemaBuilder {
+	return &schemaBuilder{
+		schemas: make(map[string]openAPISchema),
+		names:   make(map[reflect.Type]string),
+	}
+}
+
+func (b *schemaBuilder) typeName(t reflect.Type) string {
+	if name, ok := b.names[t]; ok {
+		return name
+	}
+	name := t.Name()
+	if _, ok := b.schemas[name]; ok {
+		name = path.Base(t.PkgPath()) + `.` + name
+	}
+	b.names[t] = name
+	return name
+}
+
+func (b *schemaBuilder) schema(t reflect.Type) openAPISchema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch {
+	case t == rawMessageType:
+		return openAPISchema{}
+	case t == timeType:
+		return openAPISchema{"type": "string", "format": "date-time"}
+	case t.Implements(jsonMarshalerType) || reflect.PtrTo(t).Implements(jsonMarshalerType):
+		return openAPISchema{}
+	case t.Implements(textMarshalerType) || reflect.PtrTo(t).Implements(textMarshalerType):
+		return openAPISchema{"type": "string"}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return openAPISchema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8,
+		reflect.Uint16, reflect.Uint32:
+		return openAPISchema{"type": "integer", "format": "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return openAPISchema{"type": "integer", "format": "int64"}
+	case reflect.Float32, reflect.Float64:
+		return openAPISchema{"type": "number"}
+	case reflect.String:
+		return openAPISchema{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return openAPISchema{"type": "string", "format": "byte"}
+		}
+		return openAPISchema{"type": "array", "items": b.schema(t.Elem())}
+	case reflect.Map:
+		return openAPISchema{"type": "object", "additionalProperties": b.schema(t.Elem())}
+	case reflect.Struct:
+		if len(t.Name()) == 0 {
+			return b.structSchema(t)
+		}
+		name := b.typeName(t)
+		if _, ok := b.schemas[name]; !ok {
+			b.schemas[name] = openAPISchema{}
+			b.schemas[name] = b.structSchema(t)
+		}
+		return openAPISchema{"$ref": "#/components/schemas/" + name}
+	}
+	return openAPISchema{}
+}
+
+func (b *schemaBuilder) structSchema(t reflect.Type) openAPISchema {
+	properties := make(map[string]interface{})
+	var required []string
+	b.fields(t, properties, &required)
+	ret := openAPISchema{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		sort.Strings(required)
+		ret["required"] = required
+	}
+	return ret
+}
+
+func (b *schemaBuilder) fields(t reflect.Type, properties map[string]interface{}, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := strings.Split(field.Tag.Get(`json`), `,`)
+		if tag[0] == `-` {
+			continue
+		}
+		if field.Anonymous && len(tag[0]) == 0 && field.Type.Kind() == reflect.Struct {
+			b.fields(field.Type, properties, required)
+			continue
+		}
+		if len(field.PkgPath) > 0 {
+			continue
+		}
+		name := field.Name
+		if len(tag[0]) > 0 {
+			name = tag[0]
+		}
+		properties[name] = b.schema(field.Type)
+		if len(tag) == 1 || tag[1] != `omitempty` {
+			*required = append(*required, name)
+		}
+	}
+}
+
+// formParams returns the parameters of the form in the same way as they are decoded by parseForm
+func formParams(t reflect.Type) (params []openAPIParam) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			params = append(params, formParams(field.Type)...)
+			continue
+		}
+		name := strings.Split(field.Tag.Get(`schema`), `,`)[0]
+		if len(field.PkgPath) > 0 || name == `-` || field.Type.Kind() == reflect.Interface {
+			continue
+		}
+		if len(name) == 0 {
+			name = field.Name
+		}
+		param := openAPIParam{Name: name, In: `query`, Schema: openAPISchema{"type": "string"}}
+		switch field.Type.Kind() {
+		case reflect.Bool:
+			param.Schema["type"] = "boolean"
+		case reflect.Int, reflect.Int32, reflect.Int64:
+			param.Schema["type"] = "integer"
+		}
+		params = append(params, param)
+	}
+	return
+}
+
+func newOpenAPIOp(key string, doc routeDoc, b *schemaBuilder) *openAPIOp {
+	parts := strings.SplitN(key, ` `, 2)
+	method, tpl := parts[0], parts[1]
+	op := &openAPIOp{
+		Summary:   doc.Summary,
+		Tags:      []string{routeGroup(tpl)},
+		Responses: make(map[string]openAPIResponse),
+	}
+	if !doc.Public {
+		op.Security = []map[string][]string{{openAPIAuth: {}}, {openAPIKeyAuth: {}}}
+	}
+
+	pathParams := make(map[string]bool)
+	for _, item := range strings.Split(tpl, `/`) {
+		for len(item) > 0 {
+			start := strings.IndexByte(item, '{')
+			end := strings.IndexByte(item, '}')
+			if start < 0 || end < start {
+				break
+			}
+			name := item[start+1 : end]
+			pathParams[name] = true
+			op.Parameters = append(op.Parameters, openAPIParam{Name: name, In: `path`, Required: true,
+				Schema: openAPISchema{"type": "string"}})
+			item = item[end+1:]
+		}
+	}
+
+	var params []openAPIParam
+	if doc.Form != nil {
+		params = formParams(reflect.TypeOf(doc.Form))
+	}
+	for _, item := range doc.Params {
+		param := openAPIParam{Name: item.Name, In: `query`, Description: item.Description,
+			Schema: openAPISchema{"type": item.Type}}
+		if item.Type == fileParam {
+			param.Schema = openAPISchema{"type": "string", "format": "binary"}
+		}
+		params = append(params, param)
+	}
+	if method == http.MethodGet {
+		for _, param := range params {
+			if !pathParams[param.Name] {
+				op.Parameters = append(op.Parameters, param)
+			}
+		}
+	} else if len(params) > 0 {
+		properties := make(map[string]interface{})
+		for _, param := range params {
+			if !pathParams[param.Name] {
+				if len(param.Description) > 0 {
+					param.Schema["description"] = param.Description
+				}
+				properties[param.Name] = param.Schema
+			}
+		}
+		schema := openAPISchema{"type": "object", "properties": properties}
+		op.RequestBody = &openAPIBody{Content: map[string]openAPIMedia{
+			"application/x-www-form-urlencoded": {Schema: schema},
+			"multipart/form-data":               {Schema: schema},
+		}}
+	}
+
+	success := openAPIResponse{Description: "Successful response"}
+	if len(doc.ContentType) > 0 {
+		success.Content = map[string]openAPIMedia{
+			doc.ContentType: {Schema: openAPISchema{"type": "string", "format": "binary"}},
+		}
+	} else if doc.Result != nil {
+		success.Content = map[string]openAPIMedia{
+			"application/json": {Schema: b.schema(reflect.TypeOf(doc.Result))},
+		}
+	}
+	op.Responses["200"] = success
+	op.Responses["default"] = openAPIResponse{
+		Description: "Error response",
+		Content: map[string]openAPIMedia{
+			"application/json": {Schema: b.schema(reflect.TypeOf(errType{}))},
+		},
+	}
+	return op
+}
+
+// routeKeys returns the method and the path template of the API routes
+func (r Router) routeKeys() (keys []string) {
+	r.main.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		tpl, err := route.GetPathTemplate()
+		if err != nil || !strings.HasPrefix(tpl, openAPIPrefix+`/`) {
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+		for _, method := range methods {
+			keys = append(keys, method+` `+strings.TrimPrefix(tpl, openAPIPrefix))
+		}
+		return nil
+	})
+	return
+}
+
+// getOpenAPI returns the specification of the registered routes which have been described in apiDocs
+func (r Router) getOpenAPI() *openAPISpec {
+	b := newSchemaBuilder()
+	spec := &openAPISpec{
+		OpenAPI: openAPIVersion,
+		Info:    openAPIInfo{Title: "Apla API", Version: consts.VERSION},
+		Servers: []openAPIServer{{URL: openAPIPrefix}},
+		Paths:   make(map[string]map[string]*openAPIOp),
+	}
+	for _, key := range r.routeKeys() {
+		doc, ok := apiDocs[key]
+		if !ok {
+			log.WithFields(log.Fields{"type": consts.NotFound, "route": key}).Warning("route is not described in openapi")
+			continue
+		}
+		parts := strings.SplitN(key, ` `, 2)
+		if spec.Paths[parts[1]] == nil {
+			spec.Paths[parts[1]] = make(map[string]*openAPIOp)
+		}
+		spec.Paths[parts[1]][strings.ToLower(parts[0])] = newOpenAPIOp(key, doc, b)
+	}
+	spec.Components = openAPIComponents{
+		Schemas: b.schemas,
+		SecuritySchemes: map[string]openAPISchema{
+			openAPIAuth: {"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
+			openAPIKeyAuth: {"type": "apiKey", "in": "header", "name": "Authorization",
+				"description": "API key with the prefix " + apiKeyPrefix},
+		},
+	}
+	return spec
+}
+
+func (r Router) getOpenAPIHandler(w http.ResponseWriter, req *http.Request) {
+	jsonResponse(w, r.getOpenAPI())
+}
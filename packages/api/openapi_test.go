@@ -0,0 +1,74 @@
+// Copyright (C) 2017, 2018, 2019 EGAAS S.A.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or (at
+// your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but
+// WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301, USA.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestOpenAPI(t *testing.T) {
+	m := Mode{}
+	r := NewRouter(m)
+	m.SetBlockchainRoutes(r)
+
+	spec := r.getOpenAPI()
+	routes := make(map[string]bool)
+	for _, key := range r.routeKeys() {
+		routes[key] = true
+		parts := strings.SplitN(key, ` `, 2)
+		if _, ok := spec.Paths[parts[1]][strings.ToLower(parts[0])]; !ok {
+			t.Errorf("route %s is missing from openapi specification", key)
+		}
+	}
+	for key := range apiDocs {
+		if !routes[key] {
+			t.Errorf("route %s is described in openapi but it isn't registered", key)
+		}
+	}
+
+	op := spec.Paths[`/list/{name}`][`get`]
+	params := make(map[string]string)
+	for _, param := range op.Parameters {
+		params[param.Name] = param.In
+	}
+	for name, in := range map[string]string{`name`: `path`, `limit`: `query`, `where`: `query`,
+		`cursor`: `query`, `columns`: `query`} {
+		if params[name] != in {
+			t.Errorf("wrong parameter %s of /list/{name}: %v", name, params)
+		}
+	}
+	if op.Security == nil || spec.Paths[`/login`][`post`].Security != nil {
+		t.Errorf("wrong security of the routes")
+	}
+	if _, ok := spec.Components.Schemas[`listResult`]; !ok {
+		t.Errorf("listResult schema is missing")
+	}
+
+	w := httptest.NewRecorder()
+	r.getOpenAPIHandler(w, httptest.NewRequest(http.MethodGet, `/api/v2/openapi.json`, nil))
+	var ret map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &ret); err != nil {
+		t.Fatal(err, w.Body.String())
+	}
+	if ret[`openapi`] != openAPIVersion {
+		t.Errorf("wrong openapi response %v", ret[`openapi`])
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright (C) 2017, 2018, 2019 EGAAS S.A.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or (at
+// your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but
+// WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301, USA.
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/AplaProject/go-apla/packages/consts"
+	"github.com/AplaProject/go-apla/packages/converter"
+	"github.com/AplaProject/go-apla/packages/model"
+
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+)
+
+var proposalStatuses = map[string]int64{
+	"voting":   model.ProposalVoting,
+	"accepted": model.ProposalAccepted,
+	"applied":  model.ProposalApplied,
+	"rejected": model.ProposalRejected,
+}
+
+type proposalsForm struct {
+	paginatorForm
+	Status string `schema:"status"`
+
+	status int64
+}
+
+func (f *proposalsForm) Validate(r *http.Request) error {
+	if err := f.paginatorForm.Validate(r); err != nil {
+		return err
+	}
+	f.status = -1
+	if len(f.Status) > 0 {
+		status, ok := proposalStatuses[f.Status]
+		if !ok {
+			return errUndefineval.Errorf(f.Status)
+		}
+		f.status = status
+	}
+	return nil
+}
+
+type proposalsResult struct {
+	Count int64            `json:"count"`
+	List  []model.Proposal `json:"list"`
+}
+
+type proposalVoteResult struct {
+	Account   string `json:"account"`
+	Accept    bool   `json:"accept"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+type proposalResult struct {
+	model.Proposal
+	Votes []proposalVoteResult `json:"votes"`
+}
+
+func getProposalsHandler(w http.ResponseWriter, r *http.Request) {
+	form := &proposalsForm{}
+	if err := parseForm(r, form); err != nil {
+		errorResponse(w, err, http.StatusBadRequest)
+		return
+	}
+
+	client := getClient(r)
+	logger := getLogger(r)
+
+	proposal := &model.Proposal{}
+	list, count, err := proposal.SetTablePrefix(client.EcosystemID).GetList(form.status, form.Offset, form.Limit)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting proposals")
+		errorResponse(w, err)
+		return
+	}
+
+	jsonResponse(w, &proposalsResult{
+		Count: count,
+		List:  list,
+	})
+}
+
+func getProposalHandler(w http.ResponseWriter, r *http.Request) {
+	client := getClient(r)
+	logger := getLogger(r)
+
+	id := converter.StrToInt64(mux.Vars(r)["id"])
+	proposal := &model.Proposal{}
+	found, err := proposal.SetTablePrefix(client.EcosystemID).Get(nil, id)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting proposal")
+		errorResponse(w, err)
+		return
+	}
+	if !found {
+		errorResponse(w, errProposalNotFound.Errorf(id))
+		return
+	}
+
+	vote := &model.ProposalVote{}
+	votes, err := vote.SetTablePrefix(client.EcosystemID).GetByProposal(id)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting proposal votes")
+		errorResponse(w, err)
+		return
+	}
+
+	result := &proposalResult{
+		Proposal: *proposal,
+		Votes:    make([]proposalVoteResult, len(votes)),
+	}
+	for i, v := range votes {
+		result.Votes[i] = proposalVoteResult{
+			Account:   converter.AddressToString(v.KeyID),
+			Accept:    v.Vote == 1,
+			CreatedAt: v.CreatedAt,
+		}
+	}
+	jsonResponse(w, result)
+}
@@ -0,0 +1,70 @@
+// Copyright (C) 2017, 2018, 2019 EGAAS S.A.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or (at
+// your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but
+// WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301, USA.
+
+package api
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProposal(t *testing.T) {
+	assert.NoError(t, keyLogin(1))
+
+	_, id, err := postTxResult(`NewProposal`, &url.Values{
+		`Kind`:      {`system`},
+		`Name`:      {`max_tx_count`},
+		`Value`:     {`1000`},
+		`Quorum`:    {`50`},
+		`Threshold`: {`50`},
+		`Duration`:  {`3600`},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.NoError(t, postTx(`ProposalVote`, &url.Values{
+		`ProposalId`: {id},
+		`Accept`:     {`true`},
+	}))
+	assert.Error(t, postTx(`ProposalVote`, &url.Values{
+		`ProposalId`: {id},
+		`Accept`:     {`false`},
+	}))
+
+	var proposal proposalResult
+	assert.NoError(t, sendGet(`proposal/`+id, nil, &proposal))
+	assert.Equal(t, int64(1), proposal.VotesFor)
+	if assert.Len(t, proposal.Votes, 1) {
+		assert.Equal(t, gAddress, proposal.Votes[0].Account)
+		assert.True(t, proposal.Votes[0].Accept)
+	}
+
+	var list proposalsResult
+	assert.NoError(t, sendGet(`proposals`, &url.Values{`status`: {`voting`}}, &list))
+	assert.NotEmpty(t, list.List)
+
+	assert.Error(t, postTx(`NewProposal`, &url.Values{
+		`Kind`:      {`unknown`},
+		`Name`:      {`max_tx_count`},
+		`Value`:     {`1000`},
+		`Quorum`:    {`50`},
+		`Threshold`: {`50`},
+		`Duration`:  {`3600`},
+	}))
+}
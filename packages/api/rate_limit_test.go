@@ -0,0 +1,76 @@
+// Copyright (C) 2017, 2018, 2019 EGAAS S.A.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or (at
+// your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but
+// WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301, USA.
+
+package api
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimit(t *testing.T) {
+	assert.NoError(t, keyLogin(1))
+
+	rnd := randName(`rate`)
+	form := url.Values{`Value`: {`contract ` + rnd + ` {
+		action {
+			$result = $block
+		}
+	}`}, `Conditions`: {`true`}}
+	assert.NoError(t, postTx(`NewContract`, &form))
+
+	assert.NoError(t, postTx(`NewRateLimit`, &url.Values{
+		`Contract`:   {rnd},
+		`MaxCalls`:   {`1`},
+		`Period`:     {`3600`},
+		`Conditions`: {`true`},
+	}))
+
+	assert.NoError(t, postTx(rnd, &url.Values{}))
+
+	err := postTx(rnd, &url.Values{})
+	if assert.Error(t, err) {
+		assert.True(t, strings.Contains(err.Error(), `E_RATELIMIT`), err.Error())
+	}
+}
+
+func TestRateLimitExpired(t *testing.T) {
+	assert.NoError(t, keyLogin(1))
+
+	rnd := randName(`rate`)
+	form := url.Values{`Value`: {`contract ` + rnd + ` {
+		action {
+			$result = $block
+		}
+	}`}, `Conditions`: {`true`}}
+	assert.NoError(t, postTx(`NewContract`, &form))
+
+	assert.NoError(t, postTx(`NewRateLimit`, &url.Values{
+		`Contract`:   {rnd},
+		`MaxCalls`:   {`1`},
+		`Period`:     {`1`},
+		`Conditions`: {`true`},
+	}))
+
+	assert.NoError(t, postTx(rnd, &url.Values{}))
+	time.Sleep(2 * time.Second)
+	// the expired call is deleted and doesn't prevent the new one
+	assert.NoError(t, postTx(rnd, &url.Values{}))
+}
@@ -0,0 +1,227 @@
+// Copyright (C) 2017, 2018, 2019 EGAAS S.A.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or (at
+// your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but
+// WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301, USA.
+
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/AplaProject/go-apla/packages/conf"
+	"github.com/AplaProject/go-apla/packages/consts"
+	"github.com/AplaProject/go-apla/packages/converter"
+	"github.com/AplaProject/go-apla/packages/model"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type refreshForm struct {
+	Token  string `schema:"token"`
+	Expire int64  `schema:"expire"`
+}
+
+func (f *refreshForm) Validate(r *http.Request) error {
+	if len(f.Token) == 0 {
+		return errUndefineval.Errorf("token")
+	}
+	if f.Expire == 0 {
+		f.Expire = int64(jwtExpire)
+	}
+	return nil
+}
+
+type refreshResult struct {
+	Token   string `json:"token"`
+	Refresh string `json:"refresh"`
+}
+
+type revokeForm struct {
+	nopeValidator
+	Refresh string `schema:"refresh"`
+}
+
+type revokeResult struct {
+	Result bool `json:"result"`
+}
+
+// generateRefreshToken returns the refresh token with the claims of the access token,
+// the empty string is returned if refresh tokens are disabled
+func generateRefreshToken(claims JWTClaims) (string, error) {
+	if conf.Config.JWT.RefreshExpire == 0 {
+		return "", nil
+	}
+	claims.Refresh = true
+	claims.Id = ""
+	claims.ExpiresAt = time.Now().Add(time.Second * time.Duration(conf.Config.JWT.RefreshExpire)).Unix()
+	return generateJWTToken(claims)
+}
+
+func getRefreshClaims(r *http.Request, value string) (*JWTClaims, error) {
+	logger := getLogger(r)
+
+	token, err := parseJWTValue(value)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.JWTError, "error": err}).Warning("parsing refresh token")
+		return nil, errRefreshToken
+	}
+	claims, ok := token.Claims.(*JWTClaims)
+	if !ok || !token.Valid || !claims.Refresh || len(claims.Id) == 0 {
+		return nil, errRefreshToken
+	}
+
+	revoked, err := model.IsTokenRevoked(claims.Id)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("checking revoked token")
+		return nil, errServer
+	}
+	if revoked {
+		logger.WithFields(log.Fields{"type": consts.AccessDenied, "id": claims.Id}).Warning("refresh token is revoked")
+		return nil, errRefreshToken
+	}
+	return claims, nil
+}
+
+// refreshHandler issues the new pair of the access and refresh tokens. The refresh token can be used once,
+// the new refresh token has the same expiration
+func refreshHandler(w http.ResponseWriter, r *http.Request) {
+	form := &refreshForm{}
+	if err := parseForm(r, form); err != nil {
+		errorResponse(w, err, http.StatusBadRequest)
+		return
+	}
+
+	logger := getLogger(r)
+	claims, err := getRefreshClaims(r, form.Token)
+	if err != nil {
+		errorResponse(w, err)
+		return
+	}
+
+	// the key can be deleted and the role can be revoked since the login
+	ecosystemID := converter.StrToInt64(claims.EcosystemID)
+	account := &model.Key{}
+	account.SetTablePrefix(ecosystemID)
+	found, err := account.Get(converter.StrToInt64(claims.KeyID))
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("selecting public key from keys")
+		errorResponse(w, err)
+		return
+	}
+	if !found {
+		errorResponse(w, errKeyNotFound)
+		return
+	}
+	if account.Deleted == 1 {
+		errorResponse(w, errDeletedKey)
+		return
+	}
+	if roleID := converter.StrToInt64(claims.RoleID); roleID != 0 {
+		checkedRole, err := checkRoleFromParam(roleID, ecosystemID, claims.AccountID)
+		if err != nil {
+			errorResponse(w, err)
+			return
+		}
+		if checkedRole != roleID {
+			errorResponse(w, errCheckRole)
+			return
+		}
+	}
+
+	if err = revokeToken(claims); err != nil {
+		if err == model.ErrTokenRevoked {
+			logger.WithFields(log.Fields{"type": consts.AccessDenied, "id": claims.Id}).Warning("refresh token is revoked")
+			errorResponse(w, errRefreshToken)
+			return
+		}
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("revoking refresh token")
+		errorResponse(w, errServer)
+		return
+	}
+
+	result := &refreshResult{}
+	access := *claims
+	access.Refresh = false
+	access.Id = ""
+	access.ExpiresAt = time.Now().Add(time.Second * time.Duration(form.Expire)).Unix()
+	if result.Token, err = generateJWTToken(access); err != nil {
+		logger.WithFields(log.Fields{"type": consts.JWTError, "error": err}).Error("generating jwt token")
+		errorResponse(w, err)
+		return
+	}
+	refresh := *claims
+	refresh.Id = ""
+	if result.Refresh, err = generateJWTToken(refresh); err != nil {
+		logger.WithFields(log.Fields{"type": consts.JWTError, "error": err}).Error("generating refresh token")
+		errorResponse(w, err)
+		return
+	}
+
+	jsonResponse(w, result)
+}
+
+// revokeHandler adds the token of the request and the optional refresh token to the revocation list
+func revokeHandler(w http.ResponseWriter, r *http.Request) {
+	form := &revokeForm{}
+	if err := parseForm(r, form); err != nil {
+		errorResponse(w, err, http.StatusBadRequest)
+		return
+	}
+
+	logger := getLogger(r)
+	claims, ok := getToken(r).Claims.(*JWTClaims)
+	if !ok {
+		errorResponse(w, errToken)
+		return
+	}
+	// the tokens of API keys don't have the identifier, they are revoked with the key
+	if len(claims.Id) == 0 {
+		errorResponse(w, errRevokeToken)
+		return
+	}
+
+	list := []*JWTClaims{claims}
+	if len(form.Refresh) > 0 {
+		refresh, err := getRefreshClaims(r, form.Refresh)
+		if err != nil {
+			errorResponse(w, err)
+			return
+		}
+		if refresh.KeyID != claims.KeyID || refresh.EcosystemID != claims.EcosystemID {
+			logger.WithFields(log.Fields{"type": consts.AccessDenied, "key_id": refresh.KeyID}).Warning("refresh token of other key")
+			errorResponse(w, errRefreshToken)
+			return
+		}
+		list = append(list, refresh)
+	}
+
+	for _, item := range list {
+		if err := revokeToken(item); err != nil {
+			if err == model.ErrTokenRevoked {
+				if item.Refresh {
+					errorResponse(w, errRefreshToken)
+				} else {
+					errorResponse(w, errToken)
+				}
+				return
+			}
+			logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("revoking token")
+			errorResponse(w, errServer)
+			return
+		}
+	}
+
+	jsonResponse(w, &revokeResult{Result: true})
+}
@@ -48,18 +48,23 @@ func (r Router) NewVersion(preffix string) *mux.Router {
 func (m Mode) SetCommonRoutes(r Router) {
 	api := r.NewVersion("/api/v2")
 
-	api.Use(nodeStateMiddleware, tokenMiddleware, m.clientMiddleware)
+	api.Use(nodeStateMiddleware, ipRateLimitMiddleware, tokenMiddleware, m.clientMiddleware, rateLimitMiddleware)
 
 	api.HandleFunc("/data/{prefix}_binaries/{id}/data/{hash}", getBinaryHandler).Methods("GET")
 	api.HandleFunc("/data/{table}/{id}/{column}/{hash}", getDataHandler).Methods("GET")
 	api.HandleFunc("/avatar/{ecosystem}/{account}", getAvatarHandler).Methods("GET")
 	api.HandleFunc("/auth/status", getAuthStatus).Methods("GET")
+	api.HandleFunc("/auth/refresh", refreshHandler).Methods("POST")
+	api.HandleFunc("/auth/revoke", authRequire(revokeHandler)).Methods("POST")
 
 	api.HandleFunc("/contract/{name}", authRequire(getContractInfoHandler)).Methods("GET")
 	api.HandleFunc("/contracts", authRequire(getContractsHandler)).Methods("GET")
 	api.HandleFunc("/getuid", getUIDHandler).Methods("GET")
 	api.HandleFunc("/keyinfo/{wallet}", m.getKeyInfoHandler).Methods("GET")
 	api.HandleFunc("/list/{name}", authRequire(getListHandler)).Methods("GET")
+	api.HandleFunc("/graphql", authRequire(graphqlHandler)).Methods("GET", "POST")
+	api.HandleFunc("/graphql/schema", authRequire(getGraphqlSchemaHandler)).Methods("GET")
+	api.HandleFunc("/ws", authRequire(wsHandler)).Methods("GET")
 	api.HandleFunc("/network", getNetworkHandler).Methods("GET")
 	api.HandleFunc("/sections", authRequire(getSectionsHandler)).Methods("GET")
 	api.HandleFunc("/row/{name}/{id}", authRequire(getRowHandler)).Methods("GET")
@@ -72,13 +77,17 @@ func (m Mode) SetCommonRoutes(r Router) {
 	api.HandleFunc("/test/{name}", getTestHandler).Methods("GET", "POST")
 	api.HandleFunc("/version", getVersionHandler).Methods("GET")
 	api.HandleFunc("/config/{option}", getConfigOptionHandler).Methods("GET")
+	api.HandleFunc("/openapi.json", r.getOpenAPIHandler).Methods("GET")
 
 	api.HandleFunc("/page/validators_count/{name}", getPageValidatorsCountHandler).Methods("GET")
 	api.HandleFunc("/content/source/{name}", authRequire(getSourceHandler)).Methods("POST")
 	api.HandleFunc("/content/page/{name}", authRequire(getPageHandler)).Methods("POST")
 	api.HandleFunc("/content/hash/{name}", getPageHashHandler).Methods("POST")
 	api.HandleFunc("/content/menu/{name}", authRequire(getMenuHandler)).Methods("POST")
+	api.HandleFunc("/content/form/{contract}", authRequire(getContractFormHandler)).Methods("POST")
 	api.HandleFunc("/content", jsonContentHandler).Methods("POST")
+	api.HandleFunc("/content/lint", authRequire(lintContentHandler)).Methods("POST")
+	api.HandleFunc("/content/schema", getSchemaHandler).Methods("GET")
 	api.HandleFunc("/login", m.loginHandler).Methods("POST")
 	api.HandleFunc("/sendTx", authRequire(m.sendTxHandler)).Methods("POST")
 	api.HandleFunc("/node/{name}", nodeContractHandler).Methods("POST")
@@ -89,6 +98,8 @@ func (m Mode) SetCommonRoutes(r Router) {
 	api.HandleFunc("/metrics/keys", keysCountHandler).Methods("GET")
 	api.HandleFunc("/metrics/mem", memStatHandler).Methods("GET")
 	api.HandleFunc("/metrics/ban", banStatHandler).Methods("GET")
+	api.HandleFunc("/metrics/pagecache", pageCacheStatHandler).Methods("GET")
+	api.HandleFunc("/metrics/ratelimit", rateLimitStatHandler).Methods("GET")
 }
 
 func (m Mode) SetBlockchainRoutes(r Router) {
@@ -96,11 +107,17 @@ func (m Mode) SetBlockchainRoutes(r Router) {
 	api.HandleFunc("/metrics/fullnodes", fullNodesCountHandler).Methods("GET")
 	api.HandleFunc("/txinfo/{hash}", authRequire(getTxInfoHandler)).Methods("GET")
 	api.HandleFunc("/txinfomultiple", authRequire(getTxInfoMultiHandler)).Methods("GET")
+	api.HandleFunc("/account/{wallet}/transactions", authRequire(getAccountTxsHandler)).Methods("GET")
+	api.HandleFunc("/contract/{name}/transactions", authRequire(getContractTxsHandler)).Methods("GET")
 	api.HandleFunc("/appparam/{appID}/{name}", authRequire(m.GetAppParamHandler)).Methods("GET")
 	api.HandleFunc("/appparams/{appID}", authRequire(m.getAppParamsHandler)).Methods("GET")
 	api.HandleFunc("/appcontent/{appID}", authRequire(m.getAppContentHandler)).Methods("GET")
 	api.HandleFunc("/history/{name}/{id}", authRequire(getHistoryHandler)).Methods("GET")
 	api.HandleFunc("/balance/{wallet}", authRequire(m.getBalanceHandler)).Methods("GET")
+	api.HandleFunc("/asset/{id}", authRequire(getAssetHandler)).Methods("GET")
+	api.HandleFunc("/asset/{id}/history", authRequire(getAssetHistoryHandler)).Methods("GET")
+	api.HandleFunc("/proposals", authRequire(getProposalsHandler)).Methods("GET")
+	api.HandleFunc("/proposal/{id}", authRequire(getProposalHandler)).Methods("GET")
 	api.HandleFunc("/block/{id}", getBlockInfoHandler).Methods("GET")
 	api.HandleFunc("/maxblockid", getMaxBlockHandler).Methods("GET")
 	api.HandleFunc("/blocks", getBlocksTxInfoHandler).Methods("GET")
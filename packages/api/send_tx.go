@@ -19,19 +19,97 @@ package api
 import (
 	"encoding/hex"
 	"io/ioutil"
+	"net"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
 
 	"github.com/AplaProject/go-apla/packages/block"
+	"github.com/AplaProject/go-apla/packages/conf"
 	"github.com/AplaProject/go-apla/packages/conf/syspar"
 	"github.com/AplaProject/go-apla/packages/consts"
+	"github.com/AplaProject/go-apla/packages/converter"
+	"github.com/AplaProject/go-apla/packages/model"
+	"github.com/AplaProject/go-apla/packages/utils"
 
 	log "github.com/sirupsen/logrus"
 )
 
+const (
+	// callbackParam is the callback url for all transactions of the request,
+	// callbackParam + `.` + key is the callback url for the transaction with the key
+	callbackParam  = `callback`
+	maxCallbackLen = 255
+)
+
 type sendTxResult struct {
 	Hashes map[string]string `json:"hashes"`
 }
 
+type txCallbacks map[string]string
+
+func isCallbackParam(key string) bool {
+	return key == callbackParam || strings.HasPrefix(key, callbackParam+`.`)
+}
+
+// isCallbackHost returns true if the host is allowed or it is resolved only to public addresses
+func isCallbackHost(host string) bool {
+	if len(conf.Config.Webhook.AllowedHosts) > 0 {
+		return conf.Config.Webhook.IsAllowedHost(host)
+	}
+	ips, err := net.LookupIP(host)
+	if err != nil || len(ips) == 0 {
+		return false
+	}
+	for _, ip := range ips {
+		if utils.IsPrivateIP(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+func getTxCallbacks(r *http.Request) (txCallbacks, error) {
+	callbacks := make(txCallbacks)
+	for key := range r.Form {
+		if !isCallbackParam(key) {
+			continue
+		}
+		if len(conf.Config.Webhook.Secret) == 0 {
+			return nil, errCallbackOff
+		}
+		value := r.FormValue(key)
+		u, err := url.Parse(value)
+		if err != nil || (u.Scheme != `http` && u.Scheme != `https`) || len(u.Host) == 0 ||
+			len(value) > maxCallbackLen || !isCallbackHost(u.Hostname()) {
+			return nil, errCallback.Errorf(value)
+		}
+		callbacks[strings.TrimPrefix(strings.TrimPrefix(key, callbackParam), `.`)] = value
+	}
+	return callbacks, nil
+}
+
+// save stores the callback url of the transaction if it has been specified
+func (c txCallbacks) save(r *http.Request, key, hash string) error {
+	callback, ok := c[key]
+	if !ok {
+		if callback, ok = c[``]; !ok {
+			return nil
+		}
+	}
+	tc := &model.TxCallback{
+		Hash: converter.HexToBin(hash),
+		Url:  callback,
+		Time: time.Now().Unix(),
+	}
+	if err := tc.Save(); err != nil {
+		getLogger(r).WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("saving tx callback")
+		return err
+	}
+	return nil
+}
+
 func getTxData(r *http.Request, key string) ([]byte, error) {
 	logger := getLogger(r)
 
@@ -65,6 +143,12 @@ func (m Mode) sendTxHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	callbacks, err := getTxCallbacks(r)
+	if err != nil {
+		errorResponse(w, err)
+		return
+	}
+
 	result := &sendTxResult{Hashes: make(map[string]string)}
 	for key := range r.MultipartForm.File {
 		txData, err := getTxData(r, key)
@@ -78,10 +162,17 @@ func (m Mode) sendTxHandler(w http.ResponseWriter, r *http.Request) {
 			errorResponse(w, err)
 			return
 		}
+		if err = callbacks.save(r, key, hash); err != nil {
+			errorResponse(w, err)
+			return
+		}
 		result.Hashes[key] = hash
 	}
 
 	for key := range r.Form {
+		if isCallbackParam(key) {
+			continue
+		}
 		txData, err := hex.DecodeString(r.FormValue(key))
 		if err != nil {
 			errorResponse(w, err)
@@ -93,6 +184,10 @@ func (m Mode) sendTxHandler(w http.ResponseWriter, r *http.Request) {
 			errorResponse(w, err)
 			return
 		}
+		if err = callbacks.save(r, key, hash); err != nil {
+			errorResponse(w, err)
+			return
+		}
 		result.Hashes[key] = hash
 	}
 
@@ -327,6 +327,65 @@ func TestCutoff(t *testing.T) {
 	assert.Equal(t, longText, string(data))
 }
 
+func TestChart(t *testing.T) {
+	assert.NoError(t, keyLogin(1))
+
+	name := randName(`tbl`)
+	assert.NoError(t, postTx(`NewTable`, &url.Values{
+		"Name": {name},
+		"Columns": {`[
+			{"name":"region","type":"varchar", "index": "0", "conditions":"true"},
+			{"name":"amount", "type":"number", "index":"0", "conditions":"true"},
+			{"name":"created", "type":"datetime", "index":"0", "conditions":"true"}
+			]`},
+		"Permissions":   {`{"insert": "true", "update" : "true", "new_column": "true"}`},
+		"ApplicationId": {"1"},
+	}))
+	assert.NoError(t, postTx(`NewContract`, &url.Values{
+		"Value": {`contract ` + name + ` {
+				data {
+					Region string
+					Amount int
+					Created string
+				}
+				action {
+					DBInsert("` + name + `", {region: $Region, amount: $Amount, created: $Created})
+				}
+			}`},
+		"Conditions":    {`true`},
+		"ApplicationId": {"1"},
+	}))
+	for _, item := range [][]string{
+		{"north", "10", "2019-01-10 10:00:00"},
+		{"south", "5", "2019-01-20 10:00:00"},
+		{"north", "7", "2019-02-01 10:00:00"},
+	} {
+		assert.NoError(t, postTx(name, &url.Values{
+			"Region": {item[0]}, "Amount": {item[1]}, "Created": {item[2]},
+		}))
+	}
+
+	var ret contentResult
+	template := `Chart(Type: stackedbar, Source: sales, FieldLabel: created, FieldValue: amount).Table(` +
+		name + `).Bucket(month).Series(region)`
+	assert.NoError(t, sendPost(`content`, &url.Values{`template`: {template}}, &ret))
+	assert.Equal(t, `[{"tag":"data","attr":{"columns":["created","north","south"],"data":[["2019-01","10","5"],["2019-02","7","0"]],"source":"sales","types":["text","text","text"]}},`+
+		`{"tag":"chart","attr":{"fieldlabel":"created","fieldvalue":"north,south","series":["north","south"],"source":"sales","stacked":"true","type":"bar"}}]`,
+		RawToString(ret.Tree))
+
+	template = `Chart(Type: pie, Source: regions, FieldLabel: region).Table(` + name +
+		`).Aggregate(count).Where({amount: {$gt: 5}})`
+	assert.NoError(t, sendPost(`content`, &url.Values{`template`: {template}}, &ret))
+	assert.Equal(t, `[{"tag":"data","attr":{"columns":["region","count"],"data":[["north","2"]],"source":"regions","types":["text","text"]}},`+
+		`{"tag":"chart","attr":{"fieldlabel":"region","fieldvalue":"count","source":"regions","type":"pie"}}]`,
+		RawToString(ret.Tree))
+
+	template = `Chart(Type: bar, FieldLabel: region, FieldValue: amount).Table(` + name + `).Aggregate(median)`
+	assert.NoError(t, sendPost(`content`, &url.Values{`template`: {template}}, &ret))
+	assert.Equal(t, `[{"tag":"text","text":"Chart aggregate function must be sum, count, avg, min or max"}]`,
+		RawToString(ret.Tree))
+}
+
 var imageData = `iVBORw0KGgoAAAANSUhEUgAAADIAAAAyCAIAAACRXR/mAAAACXBIWXMAAAsTAAALEwEAmpwYAAAARklEQVRYw+3OMQ0AIBAEwQOzaCLBBQZfAd0XFLMCNjOyb1o7q2Ey82VYWFhYWFhYWFhYWFhYWFhYWFhYWFhYWFhYWFhYrwqjmwKzLUjCbwAAAABJRU5ErkJggg==`
 
 func TestBinary(t *testing.T) {
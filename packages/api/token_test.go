@@ -0,0 +1,76 @@
+// Copyright (C) 2017, 2018, 2019 EGAAS S.A.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or (at
+// your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but
+// WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301, USA.
+
+package api
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToken(t *testing.T) {
+	assert.NoError(t, keyLogin(1))
+
+	symbol := strings.ToUpper(randName(`tkn`))
+	_, tokenID, err := postTxResult(`NewToken`, &url.Values{
+		`Symbol`:   {symbol},
+		`Name`:     {`Test token`},
+		`Decimals`: {`2`},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.NoError(t, postTx(`TokenMint`, &url.Values{
+		`TokenId`:   {tokenID},
+		`Recipient`: {gAddress},
+		`Amount`:    {`1000`},
+	}))
+
+	recipient := `0000-0000-0000-0000-0001`
+	assert.NoError(t, postTx(`TokenTransfer`, &url.Values{
+		`TokenId`:   {tokenID},
+		`Recipient`: {recipient},
+		`Amount`:    {`250`},
+		`Comment`:   {`test`},
+	}))
+
+	err = postTx(`TokenTransfer`, &url.Values{
+		`TokenId`:   {tokenID},
+		`Recipient`: {recipient},
+		`Amount`:    {`1000`},
+	})
+	assert.Error(t, err)
+
+	var ret balanceResult
+	assert.NoError(t, sendGet(`balance/`+gAddress, &url.Values{`token`: {symbol}}, &ret))
+	assert.Equal(t, `750`, ret.Amount)
+	assert.Equal(t, `7.5`, ret.Money)
+	assert.Equal(t, symbol, ret.Token)
+
+	assert.NoError(t, sendGet(`balance/`+recipient, &url.Values{`token`: {tokenID}}, &ret))
+	assert.Equal(t, `250`, ret.Amount)
+
+	assert.NoError(t, postTx(`TokenBurn`, &url.Values{
+		`TokenId`: {tokenID},
+		`Amount`:  {`50`},
+	}))
+	assert.NoError(t, sendGet(`balance/`+gAddress, &url.Values{`token`: {symbol}}, &ret))
+	assert.Equal(t, `700`, ret.Amount)
+}
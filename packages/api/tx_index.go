@@ -0,0 +1,139 @@
+// Copyright (C) 2017, 2018, 2019 EGAAS S.A.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or (at
+// your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but
+// WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301, USA.
+
+package api
+
+import (
+	"encoding/hex"
+	"net/http"
+
+	"github.com/AplaProject/go-apla/packages/consts"
+	"github.com/AplaProject/go-apla/packages/converter"
+	"github.com/AplaProject/go-apla/packages/model"
+
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+)
+
+type txIndexForm struct {
+	paginatorForm
+	Ecosystem int64 `schema:"ecosystem"`
+	From      int64 `schema:"from"`
+	To        int64 `schema:"to"`
+}
+
+type txIndexItem struct {
+	Hash      string `json:"hash"`
+	BlockID   int64  `json:"block_id"`
+	Time      int64  `json:"time"`
+	Ecosystem int64  `json:"ecosystem"`
+	Account   string `json:"account"`
+	Contract  string `json:"contract"`
+}
+
+type txIndexResult struct {
+	Count      int64         `json:"count"`
+	FirstBlock int64         `json:"first_block"`
+	List       []txIndexItem `json:"list"`
+}
+
+func (f *txIndexForm) filter() model.TxIndexFilter {
+	return model.TxIndexFilter{
+		Ecosystem: f.Ecosystem,
+		From:      f.From,
+		To:        f.To,
+	}
+}
+
+func getTxIndexList(w http.ResponseWriter, r *http.Request, form *txIndexForm, filter model.TxIndexFilter) {
+	logger := getLogger(r)
+
+	list, count, err := model.GetTxIndexList(filter, form.Offset, form.Limit)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting transaction index")
+		errorResponse(w, err)
+		return
+	}
+
+	// the transactions of the blocks before the first indexed block are missing in the list
+	start := &model.TxIndexStart{}
+	if _, err = start.Get(); err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting start of transaction index")
+		errorResponse(w, err)
+		return
+	}
+
+	result := &txIndexResult{
+		Count:      count,
+		FirstBlock: start.BlockID,
+		List:       make([]txIndexItem, 0, len(list)),
+	}
+	for _, item := range list {
+		result.List = append(result.List, txIndexItem{
+			Hash:      hex.EncodeToString(item.Hash),
+			BlockID:   item.BlockID,
+			Time:      item.Time,
+			Ecosystem: item.Ecosystem,
+			Account:   converter.AddressToString(item.KeyID),
+			Contract:  item.Contract,
+		})
+	}
+	jsonResponse(w, result)
+}
+
+// getAccountTxsHandler returns the transactions sent by the key
+func getAccountTxsHandler(w http.ResponseWriter, r *http.Request) {
+	form := &txIndexForm{}
+	if err := parseForm(r, form); err != nil {
+		errorResponse(w, err, http.StatusBadRequest)
+		return
+	}
+
+	params := mux.Vars(r)
+	keyID := converter.StringToAddress(params["wallet"])
+	if keyID == 0 {
+		logger := getLogger(r)
+		logger.WithFields(log.Fields{"type": consts.ConversionError, "value": params["wallet"]}).Error("converting wallet to address")
+		errorResponse(w, errInvalidWallet.Errorf(params["wallet"]))
+		return
+	}
+
+	filter := form.filter()
+	filter.KeyID = keyID
+	getTxIndexList(w, r, form, filter)
+}
+
+// getContractTxsHandler returns the transactions which have called the contract
+func getContractTxsHandler(w http.ResponseWriter, r *http.Request) {
+	form := &txIndexForm{}
+	if err := parseForm(r, form); err != nil {
+		errorResponse(w, err, http.StatusBadRequest)
+		return
+	}
+
+	params := mux.Vars(r)
+	contract := getContract(r, params["name"])
+	if contract == nil {
+		logger := getLogger(r)
+		logger.WithFields(log.Fields{"type": consts.ContractError, "contract_name": params["name"]}).Error("contract name")
+		errorResponse(w, errContract.Errorf(params["name"]))
+		return
+	}
+
+	filter := form.filter()
+	filter.Contract = contract.Name
+	getTxIndexList(w, r, form, filter)
+}
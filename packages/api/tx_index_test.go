@@ -0,0 +1,61 @@
+// Copyright (C) 2017, 2018, 2019 EGAAS S.A.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or (at
+// your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but
+// WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301, USA.
+
+package api
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxIndex(t *testing.T) {
+	require.NoError(t, keyLogin(1))
+
+	rnd := randName(`index`)
+	form := url.Values{`Value`: {`contract ` + rnd + ` {
+		action {
+			$result = $block
+		}
+	}`}, `Conditions`: {`true`}}
+	require.NoError(t, postTx(`NewContract`, &form))
+	for i := 0; i < 3; i++ {
+		require.NoError(t, postTx(rnd, &url.Values{}))
+	}
+
+	var ret txIndexResult
+	require.NoError(t, sendGet(`contract/`+rnd+`/transactions`, &url.Values{"limit": {`2`}}, &ret))
+	assert.Equal(t, int64(3), ret.Count)
+	assert.True(t, ret.FirstBlock > 0)
+	if assert.Len(t, ret.List, 2) {
+		assert.Equal(t, `@1`+rnd, ret.List[0].Contract)
+		assert.Equal(t, gAddress, ret.List[0].Account)
+		assert.True(t, ret.List[0].BlockID >= ret.List[1].BlockID)
+	}
+
+	require.NoError(t, sendGet(`account/`+gAddress+`/transactions`, &url.Values{"ecosystem": {`1`}}, &ret))
+	if assert.NotEmpty(t, ret.List) {
+		assert.Equal(t, `@1`+rnd, ret.List[0].Contract)
+	}
+
+	require.NoError(t, sendGet(`contract/`+rnd+`/transactions`, &url.Values{"from": {`1`}, "to": {`2`}}, &ret))
+	assert.Equal(t, int64(0), ret.Count)
+
+	assert.Error(t, sendGet(`contract/`+rnd+`unknown/transactions`, nil, &ret))
+	assert.Error(t, sendGet(`account/wrong/transactions`, nil, &ret))
+}
@@ -0,0 +1,169 @@
+// Copyright (C) 2017, 2018, 2019 EGAAS S.A.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or (at
+// your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but
+// WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301, USA.
+
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/AplaProject/go-apla/packages/consts"
+	"github.com/AplaProject/go-apla/packages/publisher"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	wsPingPeriod   = 30 * time.Second
+	wsTablePrefix  = "table:"
+	wsSubscribe    = "subscribe"
+	wsUnsubscribe  = "unsubscribe"
+	wsBlocks       = "blocks"
+	wsNotification = "notifications"
+	wsTransactions = "transactions"
+)
+
+// wsRequest is the message of the client. Channel is one of blocks, notifications,
+// transactions or table:name
+type wsRequest struct {
+	ID      interface{} `json:"id,omitempty"`
+	Method  string      `json:"method"`
+	Channel string      `json:"channel"`
+}
+
+type wsResponse struct {
+	ID      interface{} `json:"id,omitempty"`
+	Channel string      `json:"channel,omitempty"`
+	Result  string      `json:"result,omitempty"`
+	Error   string      `json:"error,omitempty"`
+}
+
+// wsChannel returns the channel of the publisher if the client is allowed to subscribe to it
+func wsChannel(channel string, client *Client) (string, error) {
+	switch channel {
+	case wsBlocks:
+		return publisher.ChannelBlocks, nil
+	case wsNotification:
+		return publisher.ClientChannel(client.KeyID), nil
+	case wsTransactions:
+		return publisher.TxChannel(client.KeyID), nil
+	}
+	if strings.HasPrefix(channel, wsTablePrefix) {
+		table, _, err := checkAccess(strings.TrimPrefix(channel, wsTablePrefix), ``, client)
+		if err != nil {
+			return ``, err
+		}
+		return publisher.TableChannel(table), nil
+	}
+	return ``, errWebSocket
+}
+
+func wsWrite(conn *publisher.Conn, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return conn.WriteMessage(publisher.OpText, data)
+}
+
+func wsHandler(w http.ResponseWriter, r *http.Request) {
+	client := getClient(r)
+	logger := getLogger(r)
+
+	conn, err := publisher.Upgrade(w, r)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.WebSocketError, "error": err}).Error("upgrading connection")
+		errorResponse(w, errWebSocket, http.StatusBadRequest)
+		return
+	}
+	defer conn.Close(publisher.CloseNormal)
+	sub := publisher.NewSubscriber()
+	defer sub.Close()
+
+	// the connection is closed when the token expires
+	var expire <-chan time.Time
+	if token := getToken(r); token != nil {
+		if claims, ok := token.Claims.(*JWTClaims); ok && claims.ExpiresAt > 0 {
+			timer := time.NewTimer(time.Until(time.Unix(claims.ExpiresAt, 0)))
+			defer timer.Stop()
+			expire = timer.C
+		}
+	}
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ping := time.NewTicker(wsPingPeriod)
+		defer ping.Stop()
+		for {
+			var err error
+			select {
+			case msg, ok := <-sub.Messages():
+				if !ok {
+					return
+				}
+				err = conn.WriteMessage(publisher.OpText, msg)
+			case <-ping.C:
+				err = conn.WriteMessage(publisher.OpPing, nil)
+			case <-expire:
+				conn.Close(publisher.CloseNormal)
+				return
+			case <-done:
+				return
+			}
+			if err != nil {
+				conn.Close(publisher.CloseNormal)
+				return
+			}
+		}
+	}()
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			if err != io.EOF {
+				logger.WithFields(log.Fields{"type": consts.WebSocketError, "error": err}).Debug("reading websocket message")
+			}
+			return
+		}
+		var req wsRequest
+		resp := wsResponse{Result: `ok`}
+		if err = json.Unmarshal(data, &req); err != nil {
+			resp = wsResponse{Error: errWebSocket.Message}
+		} else {
+			resp.ID, resp.Channel = req.ID, req.Channel
+			channel, err := wsChannel(req.Channel, client)
+			switch {
+			case err != nil:
+				resp.Result, resp.Error = ``, err.Error()
+				if et, ok := err.(errType); ok {
+					resp.Error = et.Message
+				}
+			case req.Method == wsSubscribe:
+				sub.Subscribe(channel)
+			case req.Method == wsUnsubscribe:
+				sub.Unsubscribe(channel)
+			default:
+				resp.Result, resp.Error = ``, errWebSocket.Message
+			}
+		}
+		if err = wsWrite(conn, &resp); err != nil {
+			return
+		}
+	}
+}
@@ -18,6 +18,7 @@ package block
 
 import (
 	"bytes"
+	"encoding/hex"
 	"fmt"
 	"strings"
 	"time"
@@ -28,7 +29,9 @@ import (
 	"github.com/AplaProject/go-apla/packages/crypto"
 	"github.com/AplaProject/go-apla/packages/model"
 	"github.com/AplaProject/go-apla/packages/notificator"
+	"github.com/AplaProject/go-apla/packages/pagecache"
 	"github.com/AplaProject/go-apla/packages/protocols"
+	"github.com/AplaProject/go-apla/packages/publisher"
 	"github.com/AplaProject/go-apla/packages/script"
 	"github.com/AplaProject/go-apla/packages/smart"
 	"github.com/AplaProject/go-apla/packages/transaction"
@@ -115,7 +118,29 @@ func (b *Block) PlaySafe() error {
 		return err
 	}
 
+	var (
+		tables    []string
+		tablesErr error
+	)
+	if pagecache.Enabled() {
+		rollbackTx := &model.RollbackTx{}
+		if tables, tablesErr = rollbackTx.GetBlockTables(dbTransaction, b.Header.BlockID); tablesErr != nil {
+			logger.WithFields(log.Fields{"type": consts.DBError, "error": tablesErr}).Error("getting changed tables of block")
+		}
+	}
+	changedRows, err := (&model.RollbackTx{}).GetBlockRows(dbTransaction, b.Header.BlockID)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting changed rows of block")
+	}
+
 	dbTransaction.Commit()
+	if tablesErr != nil {
+		// the changed tables are unknown
+		pagecache.Clear()
+	} else {
+		pagecache.Invalidate(tables)
+	}
+	b.publish(changedRows)
 	if b.SysUpdate {
 		b.SysUpdate = false
 		if err = syspar.SysUpdate(nil); err != nil {
@@ -130,6 +155,39 @@ func (b *Block) PlaySafe() error {
 	return nil
 }
 
+// publish sends the events of the committed block to the subscribers
+func (b *Block) publish(changedRows []model.RollbackTx) {
+	publisher.PublishBlock(publisher.BlockEvent{
+		ID:           b.Header.BlockID,
+		Hash:         hex.EncodeToString(b.Header.Hash),
+		Time:         b.Header.Time,
+		KeyID:        b.Header.KeyID,
+		NodePosition: b.Header.NodePosition,
+		TxCount:      len(b.Transactions),
+	})
+	for _, t := range b.Transactions {
+		publisher.PublishTxStatus(t.TxKeyID, t.TxHash, b.Header.BlockID, ``)
+	}
+	events := make(map[string]*publisher.RowsEvent)
+	added := make(map[string]bool)
+	var order []string
+	for _, row := range changedRows {
+		event, ok := events[row.NameTable]
+		if !ok {
+			event = &publisher.RowsEvent{Table: row.NameTable, BlockID: b.Header.BlockID}
+			events[row.NameTable] = event
+			order = append(order, row.NameTable)
+		}
+		if key := row.NameTable + `.` + row.TableID; !added[key] {
+			added[key] = true
+			event.IDs = append(event.IDs, row.TableID)
+		}
+	}
+	for _, table := range order {
+		publisher.PublishRows(*events[table])
+	}
+}
+
 func (b *Block) repeatMarshallBlock() error {
 	trData := make([][]byte, 0, len(b.Transactions))
 	for _, tr := range b.Transactions {
@@ -308,6 +366,9 @@ func (b *Block) Play(dbTransaction *model.DbTransaction) error {
 		if err := transaction.InsertInLogTx(t, b.Header.BlockID); err != nil {
 			return utils.ErrInfo(err)
 		}
+		if err := transaction.InsertInTxIndex(t, b.Header.BlockID, b.Header.Time); err != nil {
+			return utils.ErrInfo(err)
+		}
 
 		if t.Notifications.Size() > 0 {
 			b.Notifications = append(b.Notifications, t.Notifications)
@@ -81,6 +81,7 @@ func NewLimits(b *Block) (limits *Limits) {
 		{&txUserEcosysLimit{}, letPreprocess | letParsing},
 		{&timeBlockLimit{}, letGenBlock},
 		{&txMaxFuel{}, letGenBlock | letParsing},
+		{&txRateLimit{}, letGenBlock | letParsing},
 	}
 	for _, limiter := range allLimiters {
 		if limiter.modes&limits.Mode == 0 {
@@ -281,3 +282,18 @@ func (bl *txMaxFuel) check(t *transaction.Transaction, mode int) error {
 	}
 	return nil
 }
+
+// Checking the rate limits of the ecosystem contracts
+type txRateLimit struct {
+	BlockID int64 // the id of the block
+	Time    int64 // the time of the block
+}
+
+func (bl *txRateLimit) init(b *Block) {
+	bl.BlockID = b.Header.BlockID
+	bl.Time = b.Header.Time
+}
+
+func (bl *txRateLimit) check(t *transaction.Transaction, mode int) error {
+	return t.ApplyRateLimits(bl.BlockID, bl.Time)
+}
@@ -22,6 +22,7 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 
 	"github.com/BurntSushi/toml"
 	"github.com/pkg/errors"
@@ -98,6 +99,58 @@ type BanKeyConfig struct {
 	BadTx   int // maximum bad tx during badTime minutes
 }
 
+// PageCacheConfig is the cache of rendered pages
+type PageCacheConfig struct {
+	Enabled bool
+	Size    int // maximum number of cached pages
+}
+
+// HTTPSourceConfig is the settings of HTTPSource template function in OBS mode
+type HTTPSourceConfig struct {
+	AllowedURLs []string // prefixes of urls which can be requested
+	Timeout     int      // timeout of the request in seconds
+	CacheTTL    int      // time of keeping the response in the cache in seconds
+}
+
+// WebhookConfig is the settings of callbacks with the statuses of transactions
+type WebhookConfig struct {
+	Secret       string   // key of HMAC signature, callbacks are disabled if it is empty
+	Timeout      int      // timeout of the request in seconds
+	AllowedHosts []string // hosts which can receive callbacks, any public host is allowed if it is empty
+}
+
+// IsAllowedHost returns true if the host is in the list of allowed hosts
+func (c WebhookConfig) IsAllowedHost(host string) bool {
+	for _, item := range c.AllowedHosts {
+		if strings.EqualFold(item, host) {
+			return true
+		}
+	}
+	return false
+}
+
+// RateLimitConfig is the settings of the limiter of API requests
+type RateLimitConfig struct {
+	Enabled bool
+	Rate    float64               // number of requests per second which is allowed for the client
+	Burst   int                   // maximum number of requests of the client at once
+	Routes  map[string]RouteLimit // limits of the route groups, the group is the first part of the route
+	// IP is the limit of all requests of the address, it is checked before the authorization
+	IP RouteLimit
+}
+
+// RouteLimit is the limit of requests of the route group
+type RouteLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// JWTConfig is the settings of the authorization tokens of API
+type JWTConfig struct {
+	Algorithm     string // HS256 or ES256, the key of ES256 is stored in KeysDir and can be shared by the nodes
+	RefreshExpire int64  // lifetime of refresh tokens in seconds, refresh tokens are disabled if it is 0
+}
+
 // GlobalConfig is storing all startup config as global struct
 type GlobalConfig struct {
 	KeyID        int64  `toml:"-"`
@@ -129,6 +182,11 @@ type GlobalConfig struct {
 	Log           LogConfig
 	TokenMovement TokenMovementConfig
 	BanKey        BanKeyConfig
+	PageCache     PageCacheConfig
+	HTTPSource    HTTPSourceConfig
+	Webhook       WebhookConfig
+	RateLimit     RateLimitConfig
+	JWT           JWTConfig
 
 	NodesAddr []string
 }
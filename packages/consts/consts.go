@@ -143,6 +143,9 @@ const (
 	// NodePublicKeyFilename name of node public key file
 	NodePublicKeyFilename = "NodePublicKey"
 
+	// JWTPrivateKeyFilename name of private key file of API tokens
+	JWTPrivateKeyFilename = "JWTPrivateKey"
+
 	// KeyIDFilename generated KeyID
 	KeyIDFilename = "KeyID"
 
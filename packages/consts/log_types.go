@@ -63,6 +63,7 @@ const (
 	IncorrectCallingContract = "IncorrectCallingContract"
 	WritingFile              = "WritingFile"
 	CentrifugoError          = "CentrifugoError"
+	WebSocketError           = "WebSocketError"
 	StatsdError              = "StatsdError"
 	MigrationError           = "MigrationError"
 	AutoupdateError          = "AutoupdateError"
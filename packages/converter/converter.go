@@ -59,6 +59,16 @@ var FirstEcosystemTables = map[string]bool{
 	`binaries`:           true,
 	`buffer_data`:        true,
 	`app_params`:         true,
+	`rate_limits`:        true,
+	`tokens`:             true,
+	`token_balances`:     true,
+	`token_allowances`:   true,
+	`assets`:             true,
+	`asset_operators`:    true,
+	`escrows`:            true,
+	`proposals`:          true,
+	`proposal_votes`:     true,
+	`api_keys`:           true,
 }
 
 // FillLeft is filling slice
@@ -847,7 +857,14 @@ func checkSum(val []byte) int {
 
 // EGSMoney converts qEGS to EGS. For example, 123455000000000000000 => 123.455
 func EGSMoney(money string) string {
-	digit := consts.MoneyDigits
+	return DigitsMoney(money, consts.MoneyDigits)
+}
+
+// DigitsMoney converts the integer amount to the decimal string with the specified number of digits
+func DigitsMoney(money string, digit int) string {
+	if digit <= 0 {
+		return money
+	}
 	if len(money) < digit+1 {
 		money = strings.Repeat(`0`, digit+1-len(money)) + money
 	}
@@ -69,8 +69,13 @@ func InitialLoad(logger *log.Entry) error {
 		if err := firstLoad(logger); err != nil {
 			return err
 		}
+	}
 
-		model.UpdateSchema()
+	// update migrations are applied both after the first block and on the nodes
+	// which are upgraded from the previous version
+	if err := model.UpdateSchema(); err != nil {
+		logger.WithFields(log.Fields{"type": consts.MigrationError, "error": err}).Error("applying update migrations")
+		return err
 	}
 
 	return nil
@@ -51,6 +51,7 @@ var daemonsList = map[string]func(context.Context, *daemon) error{
 	"Confirmations":     Confirmations,
 	"Scheduler":         Scheduler,
 	"ExternalNetwork":   ExternalNetwork,
+	"TxCallbacks":       TxCallbacks,
 }
 
 var rollbackList = []string{
@@ -0,0 +1,235 @@
+// Copyright (C) 2017, 2018, 2019 EGAAS S.A.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or (at
+// your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but
+// WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301, USA.
+
+package daemons
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/AplaProject/go-apla/packages/conf"
+	"github.com/AplaProject/go-apla/packages/consts"
+	"github.com/AplaProject/go-apla/packages/crypto"
+	"github.com/AplaProject/go-apla/packages/model"
+	"github.com/AplaProject/go-apla/packages/utils"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// TxCallbackSignHeader is the header with HMAC signature of the callback body
+	TxCallbackSignHeader = `X-Apla-Signature`
+
+	callbackDaemonTimeout = 2
+	callbackBatch         = 100
+	callbackWorkers       = 10 // the number of callbacks which are sent at once
+	callbackMaxAttempts   = 10
+	callbackBackoff       = 5         // the delay before the second attempt in seconds
+	callbackMaxBackoff    = 3600      // the maximum delay between attempts in seconds
+	callbackExpiration    = 24 * 3600 // the time of waiting for the status of the transaction
+)
+
+var (
+	tcOnRun uint32
+
+	errCallbackHost = errors.New(`callback host is not allowed`)
+
+	// callbackTransport is shared by the runs of the daemon to reuse keep-alive connections
+	callbackTransport = &http.Transport{DialContext: callbackDialContext}
+)
+
+type txCallbackError struct {
+	Type  string `json:"type,omitempty"`
+	Error string `json:"error,omitempty"`
+	Id    string `json:"id,omitempty"`
+}
+
+type txCallbackBody struct {
+	Hash    string           `json:"hash"`
+	BlockID int64            `json:"blockid"`
+	Result  string           `json:"result"`
+	Message *txCallbackError `json:"errmsg,omitempty"`
+}
+
+// newTxCallbackBody returns the status of the transaction in the same format as txstatus API
+func newTxCallbackBody(item *model.TxCallbackStatus) *txCallbackBody {
+	body := &txCallbackBody{
+		Hash:    hex.EncodeToString(item.Hash),
+		BlockID: item.BlockID,
+	}
+	if item.BlockID > 0 {
+		body.Result = item.Error
+	} else if len(item.Error) > 0 {
+		if err := json.Unmarshal([]byte(item.Error), &body.Message); err != nil {
+			body.Message = &txCallbackError{
+				Type:  "txError",
+				Error: item.Error,
+			}
+		}
+	}
+	return body
+}
+
+// callbackDelay returns the delay before the next attempt after the specified number of attempts
+func callbackDelay(attempts int64) int64 {
+	delay := int64(callbackBackoff)
+	for i := int64(1); i < attempts && delay < callbackMaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > callbackMaxBackoff {
+		delay = callbackMaxBackoff
+	}
+	return delay
+}
+
+// callbackDialContext connects only to the allowed hosts or to public addresses if the list
+// of allowed hosts is empty. The address is checked after the resolving to prevent DNS rebinding.
+func callbackDialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	dialer := &net.Dialer{}
+	if len(conf.Config.Webhook.AllowedHosts) > 0 {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		if !conf.Config.Webhook.IsAllowedHost(host) {
+			return nil, errCallbackHost
+		}
+	} else {
+		dialer.Control = func(network, address string, c syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || utils.IsPrivateIP(ip) {
+				return errCallbackHost
+			}
+			return nil
+		}
+	}
+	return dialer.DialContext(ctx, network, addr)
+}
+
+// newCallbackClient returns the client which doesn't follow redirects and connects only to allowed hosts
+func newCallbackClient() *http.Client {
+	return &http.Client{
+		Timeout:   time.Duration(conf.Config.Webhook.Timeout) * time.Second,
+		Transport: callbackTransport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+func postTxCallback(client *http.Client, item *model.TxCallbackStatus) error {
+	data, err := json.Marshal(newTxCallbackBody(item))
+	if err != nil {
+		return err
+	}
+	sign, err := crypto.GetHMAC(conf.Config.Webhook.Secret, string(data))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, item.Url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(TxCallbackSignHeader, hex.EncodeToString(sign))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf(`callback response status %d`, resp.StatusCode)
+	}
+	return nil
+}
+
+// sendTxCallback posts the status of the transaction and deletes the callback or schedules the next attempt
+func sendTxCallback(client *http.Client, item *model.TxCallbackStatus) {
+	err := postTxCallback(client, item)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.NetworkError, "error": err, "url": item.Url,
+			"attempts": item.Attempts + 1}).Warning("sending tx callback")
+		if item.Attempts < callbackMaxAttempts-1 {
+			if err = model.SetTxCallbackAttempt(item.Hash, item.Attempts+1,
+				time.Now().Unix()+callbackDelay(item.Attempts+1)); err != nil {
+				log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("SetTxCallbackAttempt")
+			}
+			return
+		}
+	}
+	if err = model.DeleteTxCallback(item.Hash); err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("DeleteTxCallback")
+	}
+}
+
+// SendTxCallbacks posts the final statuses of transactions to their callback urls
+func SendTxCallbacks() error {
+	now := time.Now().Unix()
+	if err := model.DeleteExpiredTxCallbacks(now - callbackExpiration); err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("DeleteExpiredTxCallbacks")
+		return err
+	}
+	list, err := model.GetReadyTxCallbacks(now, callbackBatch)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("GetReadyTxCallbacks")
+		return err
+	}
+	client := newCallbackClient()
+	sem := make(chan struct{}, callbackWorkers)
+	var wg sync.WaitGroup
+	for i := range list {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(item *model.TxCallbackStatus) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			sendTxCallback(client, item)
+		}(&list[i])
+	}
+	wg.Wait()
+	return nil
+}
+
+// TxCallbacks sends the statuses of transactions to the callbacks
+func TxCallbacks(ctx context.Context, d *daemon) error {
+	if !atomic.CompareAndSwapUint32(&tcOnRun, 0, 1) {
+		return nil
+	}
+	defer func() {
+		atomic.StoreUint32(&tcOnRun, 0)
+	}()
+	d.sleepTime = callbackDaemonTimeout * time.Second
+	if len(conf.Config.Webhook.Secret) == 0 {
+		return nil
+	}
+	return SendTxCallbacks()
+}
@@ -0,0 +1,126 @@
+// Copyright (C) 2017, 2018, 2019 EGAAS S.A.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or (at
+// your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but
+// WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301, USA.
+
+package daemons
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/AplaProject/go-apla/packages/conf"
+	"github.com/AplaProject/go-apla/packages/crypto"
+	"github.com/AplaProject/go-apla/packages/model"
+)
+
+func TestTxCallbackBody(t *testing.T) {
+	var cases = []struct {
+		status model.TxCallbackStatus
+		want   string
+	}{
+		{model.TxCallbackStatus{TxCallback: model.TxCallback{Hash: []byte{1, 2}}, BlockID: 10, Error: `ok`},
+			`{"hash":"0102","blockid":10,"result":"ok"}`},
+		{model.TxCallbackStatus{TxCallback: model.TxCallback{Hash: []byte{3}},
+			Error: `{"type":"panic","error":"wrong"}`},
+			`{"hash":"03","blockid":0,"result":"","errmsg":{"type":"panic","error":"wrong"}}`},
+		{model.TxCallbackStatus{TxCallback: model.TxCallback{Hash: []byte{4}}, Error: `bad`},
+			`{"hash":"04","blockid":0,"result":"","errmsg":{"type":"txError","error":"bad"}}`},
+	}
+	for _, v := range cases {
+		data, err := json.Marshal(newTxCallbackBody(&v.status))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != v.want {
+			t.Errorf("wrong body %s != %s", data, v.want)
+		}
+	}
+}
+
+func TestTxCallbackDelay(t *testing.T) {
+	for attempts, want := range []int64{5, 5, 10, 20, 40} {
+		if delay := callbackDelay(int64(attempts)); delay != want {
+			t.Errorf("attempts %d: delay %d != %d", attempts, delay, want)
+		}
+	}
+	if delay := callbackDelay(100); delay != callbackMaxBackoff {
+		t.Errorf("delay %d != %d", delay, callbackMaxBackoff)
+	}
+}
+
+func TestPostTxCallback(t *testing.T) {
+	secret := conf.Config.Webhook.Secret
+	conf.Config.Webhook.Secret = `secret`
+	defer func() {
+		conf.Config.Webhook.Secret = secret
+	}()
+
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		sign, _ := crypto.GetHMAC(`secret`, string(data))
+		if r.Header.Get(TxCallbackSignHeader) != hex.EncodeToString(sign) {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	item := &model.TxCallbackStatus{TxCallback: model.TxCallback{Hash: []byte{1}, Url: server.URL},
+		BlockID: 1}
+	if err := postTxCallback(server.Client(), item); err != nil {
+		t.Error(err)
+	}
+	status = http.StatusInternalServerError
+	if err := postTxCallback(server.Client(), item); err == nil {
+		t.Error("should be error")
+	}
+}
+
+func TestCallbackClient(t *testing.T) {
+	hosts := conf.Config.Webhook.AllowedHosts
+	defer func() {
+		conf.Config.Webhook.AllowedHosts = hosts
+	}()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == `/redirect` {
+			http.Redirect(w, r, `/`, http.StatusFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	item := &model.TxCallbackStatus{TxCallback: model.TxCallback{Hash: []byte{1}, Url: server.URL},
+		BlockID: 1}
+	conf.Config.Webhook.AllowedHosts = nil
+	if err := postTxCallback(newCallbackClient(), item); err == nil {
+		t.Error("loopback host should be denied")
+	}
+	conf.Config.Webhook.AllowedHosts = []string{`127.0.0.1`}
+	if err := postTxCallback(newCallbackClient(), item); err != nil {
+		t.Error(err)
+	}
+	item.Url = server.URL + `/redirect`
+	if err := postTxCallback(newCallbackClient(), item); err == nil {
+		t.Error("redirect should not be followed")
+	}
+}
@@ -150,6 +150,9 @@ func delPidFile() {
 }
 
 func initRoutes(listenHost string) {
+	if err := api.InitJWT(); err != nil {
+		log.WithFields(log.Fields{"type": consts.CryptoError, "error": err}).Fatal("initializing jwt key")
+	}
 	handler := modes.RegisterRoutes()
 	handler = api.WithCors(handler)
 	handler = httpserver.NewMaxBodyReader(handler, conf.Config.HTTPServerMaxBodySize)
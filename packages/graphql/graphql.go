@@ -0,0 +1,338 @@
+// Copyright (C) 2017, 2018, 2019 EGAAS S.A.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or (at
+// your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but
+// WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301, USA.
+
+// Package graphql implements the parser of GraphQL executable documents.
+// The execution of the queries is up to the caller.
+package graphql
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/AplaProject/go-apla/packages/types"
+)
+
+// Document is the parsed GraphQL request
+type Document struct {
+	Operations []*Operation
+	Fragments  map[string]*Fragment
+}
+
+// Operation is the query, mutation or subscription definition
+type Operation struct {
+	Type         string
+	Name         string
+	Variables    []*VariableDefinition
+	Directives   []*Directive
+	SelectionSet []Selection
+}
+
+// VariableDefinition is the declaration of the operation variable
+type VariableDefinition struct {
+	Name    string
+	Type    string
+	Default interface{}
+}
+
+// Selection is *Field, *FragmentSpread or *InlineFragment
+type Selection interface{}
+
+// Field is the selected field
+type Field struct {
+	Alias        string
+	Name         string
+	Arguments    []*Argument
+	Directives   []*Directive
+	SelectionSet []Selection
+}
+
+// Argument is the argument of the field or the directive
+type Argument struct {
+	Name  string
+	Value interface{}
+}
+
+// Directive is the directive like @include(if: $flag)
+type Directive struct {
+	Name      string
+	Arguments []*Argument
+}
+
+// FragmentSpread is ...Name
+type FragmentSpread struct {
+	Name       string
+	Directives []*Directive
+}
+
+// InlineFragment is ... on Type { }
+type InlineFragment struct {
+	TypeCondition string
+	Directives    []*Directive
+	SelectionSet  []Selection
+}
+
+// Fragment is the named fragment definition
+type Fragment struct {
+	Name          string
+	TypeCondition string
+	Directives    []*Directive
+	SelectionSet  []Selection
+}
+
+// Variable is the reference to the operation variable in the value
+type Variable struct {
+	Name string
+}
+
+// Enum is the enum value
+type Enum struct {
+	Name string
+}
+
+// ResponseKey returns the key of the field in the result
+func (f *Field) ResponseKey() string {
+	if len(f.Alias) > 0 {
+		return f.Alias
+	}
+	return f.Name
+}
+
+// Operation returns the operation which should be executed
+func (d *Document) Operation(name string) (*Operation, error) {
+	if len(name) == 0 {
+		if len(d.Operations) != 1 {
+			return nil, fmt.Errorf(`operation name is required`)
+		}
+		return d.Operations[0], nil
+	}
+	for _, op := range d.Operations {
+		if op.Name == name {
+			return op, nil
+		}
+	}
+	return nil, fmt.Errorf(`unknown operation %s`, name)
+}
+
+// VariableValues returns the values of the operation variables with applied default values
+func (op *Operation) VariableValues(vars map[string]interface{}) (map[string]interface{}, error) {
+	ret := make(map[string]interface{})
+	for _, def := range op.Variables {
+		if v, ok := vars[def.Name]; ok {
+			ret[def.Name] = types.ConvertMap(v)
+		} else if def.Default != nil {
+			ret[def.Name] = def.Default
+		} else if len(def.Type) > 0 && def.Type[len(def.Type)-1] == '!' {
+			return nil, fmt.Errorf(`variable $%s is required`, def.Name)
+		}
+	}
+	return ret, nil
+}
+
+// Value returns the value with the substituted variables
+func Value(v interface{}, vars map[string]interface{}) interface{} {
+	switch value := v.(type) {
+	case *Variable:
+		return vars[value.Name]
+	case *Enum:
+		return value.Name
+	case []interface{}:
+		ret := make([]interface{}, len(value))
+		for i, item := range value {
+			ret[i] = Value(item, vars)
+		}
+		return ret
+	case *types.Map:
+		ret := types.NewMap()
+		for _, key := range value.Keys() {
+			item, _ := value.Get(key)
+			ret.Set(key, Value(item, vars))
+		}
+		return ret
+	}
+	return v
+}
+
+// Arguments returns the values of the arguments
+func Arguments(args []*Argument, vars map[string]interface{}) map[string]interface{} {
+	ret := make(map[string]interface{})
+	for _, arg := range args {
+		ret[arg.Name] = Value(arg.Value, vars)
+	}
+	return ret
+}
+
+func skip(directives []*Directive, vars map[string]interface{}) bool {
+	for _, dir := range directives {
+		args := Arguments(dir.Arguments, vars)
+		switch dir.Name {
+		case `skip`:
+			if v, _ := args[`if`].(bool); v {
+				return true
+			}
+		case `include`:
+			if v, _ := args[`if`].(bool); !v {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// spreads returns the names of the fragments which are spread in the selection set at any level
+func spreads(set []Selection, names []string) []string {
+	for _, sel := range set {
+		switch item := sel.(type) {
+		case *Field:
+			names = spreads(item.SelectionSet, names)
+		case *FragmentSpread:
+			names = append(names, item.Name)
+		case *InlineFragment:
+			names = spreads(item.SelectionSet, names)
+		}
+	}
+	return names
+}
+
+// checkFragments checks that all spread fragments are defined and fragments don't spread themselves
+// directly or through other fragments
+func (d *Document) checkFragments() error {
+	const (
+		visiting = iota + 1
+		checked
+	)
+	state := make(map[string]int)
+	var visit func(string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case visiting:
+			return fmt.Errorf(`fragment %s spreads itself`, name)
+		case checked:
+			return nil
+		}
+		state[name] = visiting
+		for _, spread := range spreads(d.Fragments[name].SelectionSet, nil) {
+			if d.Fragments[spread] == nil {
+				return fmt.Errorf(`unknown fragment %s`, spread)
+			}
+			if err := visit(spread); err != nil {
+				return err
+			}
+		}
+		state[name] = checked
+		return nil
+	}
+	names := make([]string, 0, len(d.Fragments))
+	for name := range d.Fragments {
+		names = append(names, name)
+	}
+	// the fragments are sorted to report the same fragment of the cycle every time
+	sort.Strings(names)
+	for _, name := range names {
+		if err := visit(name); err != nil {
+			return err
+		}
+	}
+	for _, op := range d.Operations {
+		for _, spread := range spreads(op.SelectionSet, nil) {
+			if d.Fragments[spread] == nil {
+				return fmt.Errorf(`unknown fragment %s`, spread)
+			}
+		}
+	}
+	return nil
+}
+
+// CheckLimits returns an error if the selection set with the expanded fragments has fields
+// nested deeper than maxDepth or has more than maxFields fields. Directives are not applied.
+func (d *Document) CheckLimits(set []Selection, maxDepth, maxFields int) error {
+	var (
+		count int
+		check func([]Selection, int) error
+	)
+	check = func(set []Selection, depth int) error {
+		for _, sel := range set {
+			switch item := sel.(type) {
+			case *Field:
+				if depth > maxDepth {
+					return fmt.Errorf(`query depth exceeds %d`, maxDepth)
+				}
+				if count++; count > maxFields {
+					return fmt.Errorf(`query has more than %d fields`, maxFields)
+				}
+				if err := check(item.SelectionSet, depth+1); err != nil {
+					return err
+				}
+			case *FragmentSpread:
+				if frag := d.Fragments[item.Name]; frag != nil {
+					if err := check(frag.SelectionSet, depth); err != nil {
+						return err
+					}
+				}
+			case *InlineFragment:
+				if err := check(item.SelectionSet, depth); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	return check(set, 1)
+}
+
+// CollectFields returns the fields of the selection set of the object having typeName.
+// Fragments are expanded and @skip, @include directives are applied. The fields with
+// the same response key are merged.
+func (d *Document) CollectFields(typeName string, set []Selection, vars map[string]interface{}) []*Field {
+	var (
+		fields  []*Field
+		collect func([]Selection, map[string]bool)
+	)
+	keys := make(map[string]*Field)
+	collect = func(set []Selection, visited map[string]bool) {
+		for _, sel := range set {
+			switch item := sel.(type) {
+			case *Field:
+				if skip(item.Directives, vars) {
+					continue
+				}
+				if prev, ok := keys[item.ResponseKey()]; ok {
+					prev.SelectionSet = append(prev.SelectionSet, item.SelectionSet...)
+					continue
+				}
+				field := *item
+				field.SelectionSet = append([]Selection{}, item.SelectionSet...)
+				keys[item.ResponseKey()] = &field
+				fields = append(fields, &field)
+			case *FragmentSpread:
+				frag := d.Fragments[item.Name]
+				if frag == nil || visited[item.Name] || skip(item.Directives, vars) ||
+					(len(frag.TypeCondition) > 0 && frag.TypeCondition != typeName) {
+					continue
+				}
+				visited[item.Name] = true
+				collect(frag.SelectionSet, visited)
+			case *InlineFragment:
+				if skip(item.Directives, vars) ||
+					(len(item.TypeCondition) > 0 && item.TypeCondition != typeName) {
+					continue
+				}
+				collect(item.SelectionSet, visited)
+			}
+		}
+	}
+	collect(set, make(map[string]bool))
+	return fields
+}
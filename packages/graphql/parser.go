@@ -0,0 +1,515 @@
+// Copyright (C) 2017, 2018, 2019 EGAAS S.A.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or (at
+// your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but
+// WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301, USA.
+
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/AplaProject/go-apla/packages/types"
+)
+
+const (
+	tokenEOF = iota
+	tokenPunct
+	tokenName
+	tokenInt
+	tokenFloat
+	tokenString
+)
+
+type token struct {
+	kind  int
+	value string
+	line  int
+	col   int
+}
+
+type parser struct {
+	src   string
+	pos   int
+	line  int
+	start int // offset of the current line
+	tok   token
+}
+
+// Parse parses the GraphQL executable document
+func Parse(src string) (doc *Document, err error) {
+	p := &parser{src: src, line: 1}
+	defer func() {
+		if r := recover(); r != nil {
+			if perr, ok := r.(parseError); ok {
+				err = perr
+				return
+			}
+			panic(r)
+		}
+	}()
+	p.next()
+	doc = &Document{Fragments: make(map[string]*Fragment)}
+	for p.tok.kind != tokenEOF {
+		switch {
+		case p.is(`{`):
+			doc.Operations = append(doc.Operations, &Operation{Type: `query`, SelectionSet: p.selectionSet()})
+		case p.tok.kind == tokenName && p.tok.value == `fragment`:
+			frag := p.fragment()
+			if _, ok := doc.Fragments[frag.Name]; ok {
+				p.fail(`fragment %s is already defined`, frag.Name)
+			}
+			doc.Fragments[frag.Name] = frag
+		case p.tok.kind == tokenName && (p.tok.value == `query` || p.tok.value == `mutation` ||
+			p.tok.value == `subscription`):
+			doc.Operations = append(doc.Operations, p.operation())
+		default:
+			p.unexpected()
+		}
+	}
+	if len(doc.Operations) == 0 {
+		return nil, fmt.Errorf(`document does not contain any operations`)
+	}
+	if err = doc.checkFragments(); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+type parseError struct {
+	msg       string
+	line, col int
+}
+
+func (e parseError) Error() string {
+	return fmt.Sprintf(`syntax error: %s at %d:%d`, e.msg, e.line, e.col)
+}
+
+func (p *parser) fail(format string, args ...interface{}) {
+	panic(parseError{fmt.Sprintf(format, args...), p.tok.line, p.tok.col})
+}
+
+func (p *parser) unexpected() {
+	if p.tok.kind == tokenEOF {
+		p.fail(`unexpected end of document`)
+	}
+	p.fail(`unexpected %q`, p.tok.value)
+}
+
+func (p *parser) is(punct string) bool {
+	return p.tok.kind == tokenPunct && p.tok.value == punct
+}
+
+func (p *parser) expect(punct string) {
+	if !p.is(punct) {
+		p.unexpected()
+	}
+	p.next()
+}
+
+func (p *parser) name() string {
+	if p.tok.kind != tokenName {
+		p.unexpected()
+	}
+	name := p.tok.value
+	p.next()
+	return name
+}
+
+func (p *parser) operation() *Operation {
+	op := &Operation{Type: p.name()}
+	if p.tok.kind == tokenName {
+		op.Name = p.name()
+	}
+	if p.is(`(`) {
+		p.next()
+		for !p.is(`)`) {
+			p.expect(`$`)
+			def := &VariableDefinition{Name: p.name()}
+			p.expect(`:`)
+			def.Type = p.typeRef()
+			if p.is(`=`) {
+				p.next()
+				def.Default = p.value(true)
+			}
+			op.Variables = append(op.Variables, def)
+		}
+		p.next()
+	}
+	op.Directives = p.directives()
+	op.SelectionSet = p.selectionSet()
+	return op
+}
+
+func (p *parser) typeRef() string {
+	var ret string
+	if p.is(`[`) {
+		p.next()
+		ret = `[` + p.typeRef() + `]`
+		p.expect(`]`)
+	} else {
+		ret = p.name()
+	}
+	if p.is(`!`) {
+		p.next()
+		ret += `!`
+	}
+	return ret
+}
+
+func (p *parser) fragment() *Fragment {
+	p.next()
+	frag := &Fragment{Name: p.name()}
+	if frag.Name == `on` {
+		p.fail(`wrong fragment name`)
+	}
+	if p.tok.kind != tokenName || p.tok.value != `on` {
+		p.unexpected()
+	}
+	p.next()
+	frag.TypeCondition = p.name()
+	frag.Directives = p.directives()
+	frag.SelectionSet = p.selectionSet()
+	return frag
+}
+
+func (p *parser) selectionSet() []Selection {
+	p.expect(`{`)
+	set := make([]Selection, 0)
+	for !p.is(`}`) {
+		if p.is(`...`) {
+			p.next()
+			if p.tok.kind == tokenName && p.tok.value != `on` {
+				set = append(set, &FragmentSpread{Name: p.name(), Directives: p.directives()})
+				continue
+			}
+			frag := &InlineFragment{}
+			if p.tok.kind == tokenName {
+				p.next()
+				frag.TypeCondition = p.name()
+			}
+			frag.Directives = p.directives()
+			frag.SelectionSet = p.selectionSet()
+			set = append(set, frag)
+			continue
+		}
+		field := &Field{Name: p.name()}
+		if p.is(`:`) {
+			p.next()
+			field.Alias = field.Name
+			field.Name = p.name()
+		}
+		field.Arguments = p.arguments(false)
+		field.Directives = p.directives()
+		if p.is(`{`) {
+			field.SelectionSet = p.selectionSet()
+		}
+		set = append(set, field)
+	}
+	if len(set) == 0 {
+		p.fail(`empty selection set`)
+	}
+	p.next()
+	return set
+}
+
+func (p *parser) arguments(isConst bool) []*Argument {
+	var args []*Argument
+	if !p.is(`(`) {
+		return args
+	}
+	p.next()
+	for !p.is(`)`) {
+		arg := &Argument{Name: p.name()}
+		p.expect(`:`)
+		arg.Value = p.value(isConst)
+		args = append(args, arg)
+	}
+	p.next()
+	return args
+}
+
+func (p *parser) directives() []*Directive {
+	var dirs []*Directive
+	for p.is(`@`) {
+		p.next()
+		dirs = append(dirs, &Directive{Name: p.name(), Arguments: p.arguments(false)})
+	}
+	return dirs
+}
+
+func (p *parser) value(isConst bool) interface{} {
+	tok := p.tok
+	switch tok.kind {
+	case tokenInt:
+		p.next()
+		v, err := strconv.ParseInt(tok.value, 10, 64)
+		if err != nil {
+			p.fail(`wrong integer %s`, tok.value)
+		}
+		return v
+	case tokenFloat:
+		p.next()
+		v, err := strconv.ParseFloat(tok.value, 64)
+		if err != nil {
+			p.fail(`wrong float %s`, tok.value)
+		}
+		return v
+	case tokenString:
+		p.next()
+		return tok.value
+	case tokenName:
+		p.next()
+		switch tok.value {
+		case `true`:
+			return true
+		case `false`:
+			return false
+		case `null`:
+			return nil
+		}
+		return &Enum{Name: tok.value}
+	}
+	switch {
+	case p.is(`$`):
+		if isConst {
+			p.unexpected()
+		}
+		p.next()
+		return &Variable{Name: p.name()}
+	case p.is(`[`):
+		p.next()
+		list := make([]interface{}, 0)
+		for !p.is(`]`) {
+			list = append(list, p.value(isConst))
+		}
+		p.next()
+		return list
+	case p.is(`{`):
+		p.next()
+		obj := types.NewMap()
+		for !p.is(`}`) {
+			name := p.name()
+			p.expect(`:`)
+			obj.Set(name, p.value(isConst))
+		}
+		p.next()
+		return obj
+	}
+	p.unexpected()
+	return nil
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// next reads the next token
+func (p *parser) next() {
+	src := p.src
+	for p.pos < len(src) {
+		c := src[p.pos]
+		if c == '\n' {
+			p.pos++
+			p.line++
+			p.start = p.pos
+		} else if c == ' ' || c == '\t' || c == '\r' || c == ',' {
+			p.pos++
+		} else if c == '#' {
+			for p.pos < len(src) && src[p.pos] != '\n' {
+				p.pos++
+			}
+		} else if strings.HasPrefix(src[p.pos:], "\xef\xbb\xbf") {
+			p.pos += 3
+		} else {
+			break
+		}
+	}
+	p.tok = token{line: p.line, col: p.pos - p.start + 1}
+	if p.pos >= len(src) {
+		p.tok.kind = tokenEOF
+		return
+	}
+	c := src[p.pos]
+	switch {
+	case strings.HasPrefix(src[p.pos:], `...`):
+		p.tok.kind, p.tok.value = tokenPunct, `...`
+		p.pos += 3
+	case strings.IndexByte(`!$&():=@[]{}|`, c) >= 0:
+		p.tok.kind, p.tok.value = tokenPunct, string(c)
+		p.pos++
+	case isNameStart(c):
+		off := p.pos
+		for p.pos < len(src) && (isNameStart(src[p.pos]) || isDigit(src[p.pos])) {
+			p.pos++
+		}
+		p.tok.kind, p.tok.value = tokenName, src[off:p.pos]
+	case c == '-' || isDigit(c):
+		p.number()
+	case strings.HasPrefix(src[p.pos:], `"""`):
+		p.blockString()
+	case c == '"':
+		p.str()
+	default:
+		r, _ := utf8.DecodeRuneInString(src[p.pos:])
+		p.fail(`unexpected character %q`, r)
+	}
+}
+
+func (p *parser) number() {
+	src := p.src
+	off := p.pos
+	p.tok.kind = tokenInt
+	if src[p.pos] == '-' {
+		p.pos++
+	}
+	digits := func() {
+		start := p.pos
+		for p.pos < len(src) && isDigit(src[p.pos]) {
+			p.pos++
+		}
+		if start == p.pos {
+			p.fail(`wrong number`)
+		}
+	}
+	digits()
+	if p.pos < len(src) && src[p.pos] == '.' {
+		p.pos++
+		p.tok.kind = tokenFloat
+		digits()
+	}
+	if p.pos < len(src) && (src[p.pos] == 'e' || src[p.pos] == 'E') {
+		p.pos++
+		p.tok.kind = tokenFloat
+		if p.pos < len(src) && (src[p.pos] == '+' || src[p.pos] == '-') {
+			p.pos++
+		}
+		digits()
+	}
+	p.tok.value = src[off:p.pos]
+}
+
+func (p *parser) str() {
+	src := p.src
+	var out strings.Builder
+	p.pos++
+	for {
+		if p.pos >= len(src) || src[p.pos] == '\n' {
+			p.fail(`unterminated string`)
+		}
+		c := src[p.pos]
+		if c == '"' {
+			p.pos++
+			break
+		}
+		if c != '\\' {
+			out.WriteByte(c)
+			p.pos++
+			continue
+		}
+		if p.pos+1 >= len(src) {
+			p.fail(`unterminated string`)
+		}
+		esc := src[p.pos+1]
+		p.pos += 2
+		switch esc {
+		case '"', '\\', '/':
+			out.WriteByte(esc)
+		case 'b':
+			out.WriteByte('\b')
+		case 'f':
+			out.WriteByte('\f')
+		case 'n':
+			out.WriteByte('\n')
+		case 'r':
+			out.WriteByte('\r')
+		case 't':
+			out.WriteByte('\t')
+		case 'u':
+			if p.pos+4 > len(src) {
+				p.fail(`wrong unicode escape`)
+			}
+			code, err := strconv.ParseUint(src[p.pos:p.pos+4], 16, 32)
+			if err != nil {
+				p.fail(`wrong unicode escape`)
+			}
+			out.WriteRune(rune(code))
+			p.pos += 4
+		default:
+			p.fail(`wrong escape \%c`, esc)
+		}
+	}
+	p.tok.kind, p.tok.value = tokenString, out.String()
+}
+
+func (p *parser) blockString() {
+	src := p.src
+	p.pos += 3
+	end := strings.Index(src[p.pos:], `"""`)
+	for end > 0 && src[p.pos+end-1] == '\\' {
+		next := strings.Index(src[p.pos+end+1:], `"""`)
+		if next < 0 {
+			end = -1
+			break
+		}
+		end += next + 1
+	}
+	if end < 0 {
+		p.fail(`unterminated string`)
+	}
+	raw := src[p.pos : p.pos+end]
+	p.line += strings.Count(raw, "\n")
+	if off := strings.LastIndexByte(raw, '\n'); off >= 0 {
+		p.start = p.pos + off + 1
+	}
+	p.pos += end + 3
+	p.tok.kind, p.tok.value = tokenString, blockStringValue(strings.Replace(raw, `\"""`, `"""`, -1))
+}
+
+// blockStringValue removes the common indentation and the leading and trailing blank lines
+func blockStringValue(raw string) string {
+	lines := strings.Split(strings.Replace(raw, "\r\n", "\n", -1), "\n")
+	indent := -1
+	for _, line := range lines[1:] {
+		trimmed := strings.TrimLeft(line, " \t")
+		if len(trimmed) == 0 {
+			continue
+		}
+		if n := len(line) - len(trimmed); indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	if indent > 0 {
+		for i := 1; i < len(lines); i++ {
+			if len(lines[i]) >= indent {
+				lines[i] = lines[i][indent:]
+			} else {
+				lines[i] = strings.TrimLeft(lines[i], " \t")
+			}
+		}
+	}
+	for len(lines) > 0 && len(strings.TrimSpace(lines[0])) == 0 {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && len(strings.TrimSpace(lines[len(lines)-1])) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,114 @@
+// Copyright (C) 2017, 2018, 2019 EGAAS S.A.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or (at
+// your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but
+// WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301, USA.
+
+package graphql
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	doc, err := Parse(`
+	# list of the members
+	query Members($limit: Int = 10, $where: JSON!) {
+		members_list(limit: $limit, where: $where, order: [{id: -1}, name]) {
+			count
+			list { ...member @include(if: true) }
+		}
+		first: members(id: "1") { id, ... on members { image_id @skip(if: true) } }
+	}
+	fragment member on members { id member_name text: description }`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	op, err := doc.Operation(``)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var where interface{}
+	json.Unmarshal([]byte(`{"member_name": {"$begin": "Jo"}}`), &where)
+	vars, err := op.VariableValues(map[string]interface{}{`where`: where})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := doc.CollectFields(`Query`, op.SelectionSet, vars)
+	if len(fields) != 2 || fields[1].ResponseKey() != `first` || fields[1].Name != `members` {
+		t.Fatalf(`wrong fields %v`, fields)
+	}
+	args, _ := json.Marshal(Arguments(fields[0].Arguments, vars))
+	if string(args) != `{"limit":10,"order":[{"id":-1},"name"],"where":{"member_name":{"$begin":"Jo"}}}` {
+		t.Errorf(`wrong arguments %s`, args)
+	}
+	list := doc.CollectFields(`members_list`, fields[0].SelectionSet, vars)[1]
+	var keys []string
+	for _, field := range doc.CollectFields(`members`, list.SelectionSet, vars) {
+		keys = append(keys, field.ResponseKey())
+	}
+	if len(keys) != 3 || keys[2] != `text` {
+		t.Errorf(`wrong fragment fields %v`, keys)
+	}
+	if len(doc.CollectFields(`members`, fields[1].SelectionSet, vars)) != 1 {
+		t.Errorf(`@skip is not applied`)
+	}
+	if _, err = op.VariableValues(nil); err == nil {
+		t.Errorf(`required variable is not checked`)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for src, msg := range map[string]string{
+		`{ members(id: 1 }`:            `syntax error: unexpected "}" at 1:17`,
+		"{\n  members { }\n}":          `syntax error: empty selection set at 2:13`,
+		`{ text(value: "abc) }`:        `syntax error: unterminated string at 1:15`,
+		`fragment f on T { id }`:       `document does not contain any operations`,
+		`query Q($v: Int = $w) { id }`: `syntax error: unexpected "$" at 1:19`,
+		`{ ...a } fragment a on T { id ...b } fragment b on T { t { ...a } }`: `fragment a spreads itself`,
+		`{ ...a } fragment a on T { ... on T { ...a } }`:                      `fragment a spreads itself`,
+		`{ ...a }`: `unknown fragment a`,
+	} {
+		if _, err := Parse(src); err == nil || err.Error() != msg {
+			t.Errorf(`%s: wrong error %v`, src, err)
+		}
+	}
+	doc, err := Parse(`{ text(value: """
+	  first
+	    second \""" """, escaped: "aA\n") }`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	args := Arguments(doc.Operations[0].SelectionSet[0].(*Field).Arguments, nil)
+	if args[`value`] != "first\n  second \"\"\" " || args[`escaped`] != "aA\n" {
+		t.Errorf(`wrong strings %q`, args)
+	}
+}
+
+func TestCheckLimits(t *testing.T) {
+	doc, err := Parse(`{ a { b { c } } d: a { ...f } } fragment f on T { b { c } e }`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	set := doc.Operations[0].SelectionSet
+	if err = doc.CheckLimits(set, 3, 7); err != nil {
+		t.Error(err)
+	}
+	if err = doc.CheckLimits(set, 2, 7); err == nil || err.Error() != `query depth exceeds 2` {
+		t.Errorf(`wrong depth error %v`, err)
+	}
+	if err = doc.CheckLimits(set, 3, 6); err == nil || err.Error() != `query has more than 6 fields` {
+		t.Errorf(`wrong fields error %v`, err)
+	}
+}
@@ -5,6 +5,62 @@ package migration
 var firstEcosystemContractsSQL = `
 INSERT INTO "1_contracts" (id, name, value, conditions, app_id, ecosystem)
 VALUES
+	(next_id('1_contracts'), 'ApplyProposals', 'contract ApplyProposals {
+    action {
+        var list array
+        list = ProposalsReady()
+        var i int
+        while i < Len(list) {
+            var item map
+            item = list[i]
+            if item["kind"] == "system" {
+                var params map
+                params["Name"] = item["name"]
+                params["Value"] = item["value"]
+                CallContract("@1UpdateSysParam", params)
+            }
+            ProposalApply(Int(item["ecosystem"]), Int(item["id"]))
+            i = i + 1
+        }
+    }
+}
+', 'ContractConditions("MainCondition")', '1', '1'),
+	(next_id('1_contracts'), 'AssetApproveOperator', 'contract AssetApproveOperator {
+    data {
+        Operator string
+        Approved bool
+    }
+
+    conditions {
+        $operator = AddressToId($Operator)
+        if $operator == 0 {
+            warning Sprintf("Operator %%s is invalid", $Operator)
+        }
+    }
+
+    action {
+        AssetApproveOperator($operator, $Approved)
+    }
+}
+', 'ContractConditions("MainCondition")', '1', '1'),
+	(next_id('1_contracts'), 'AssetTransfer', 'contract AssetTransfer {
+    data {
+        AssetId int
+        Recipient string
+    }
+
+    conditions {
+        $recipient = AddressToId($Recipient)
+        if $recipient == 0 {
+            warning Sprintf("Recipient %%s is invalid", $Recipient)
+        }
+    }
+
+    action {
+        AssetTransfer($AssetId, $recipient)
+    }
+}
+', 'ContractConditions("MainCondition")', '1', '1'),
 	(next_id('1_contracts'), 'BindWallet', 'contract BindWallet {
 	data {
 		Id  int
@@ -292,6 +348,13 @@ VALUES
             ValidateCondition($Conditions, $ecosystem_id)
         }
         $ValidateCount = preparePageValidateCount($ValidateCount)
+        if $Value {
+            var lint string
+            lint = LintTemplate($Value)
+            if lint {
+                warning Sprintf("Template error %%s", lint)
+            }
+        }
     }
 
     action {
@@ -319,6 +382,45 @@ VALUES
         }
     }
 }
+', 'ContractConditions("MainCondition")', '1', '1'),
+	(next_id('1_contracts'), 'EditRateLimit', 'contract EditRateLimit {
+    data {
+        Id int
+        MaxCalls int "optional"
+        Period int "optional"
+        Conditions string "optional"
+        Deleted int "optional"
+    }
+
+    conditions {
+        RowConditions("rate_limits", $Id, false)
+        if $Conditions {
+            ValidateCondition($Conditions, $ecosystem_id)
+        }
+        if $MaxCalls < 0 || $Period < 0 {
+            warning "MaxCalls and Period cannot be negative"
+        }
+    }
+
+    action {
+        var pars map
+        if $MaxCalls > 0 {
+            pars["max_calls"] = $MaxCalls
+        }
+        if $Period > 0 {
+            pars["period"] = $Period
+        }
+        if $Conditions {
+            pars["conditions"] = $Conditions
+        }
+        if $Deleted == 1 {
+            pars["deleted"] = 1
+        }
+        if pars {
+            DBUpdate("rate_limits", $Id, pars)
+        }
+    }
+}
 ', 'ContractConditions("MainCondition")', '1', '1'),
 	(next_id('1_contracts'), 'EditTable', 'contract EditTable {
     data {
@@ -355,6 +457,65 @@ VALUES
         PermTable($Name, JSONEncode($Permissions))
     }
 }
+', 'ContractConditions("MainCondition")', '1', '1'),
+	(next_id('1_contracts'), 'EscrowApprove', 'contract EscrowApprove {
+    data {
+        EscrowId int
+    }
+
+    action {
+        EscrowApprove($EscrowId)
+    }
+}
+', 'ContractConditions("MainCondition")', '1', '1'),
+	(next_id('1_contracts'), 'EscrowLock', 'contract EscrowLock {
+    data {
+        Recipient string
+        Arbiter string "optional"
+        Amount money
+        RefundBlock int "optional"
+        RefundTime int "optional"
+        Comment string "optional"
+    }
+
+    conditions {
+        $recipient = AddressToId($Recipient)
+        if $recipient == 0 {
+            warning Sprintf("Recipient %%s is invalid", $Recipient)
+        }
+        $arbiter = 0
+        if Size($Arbiter) > 0 {
+            $arbiter = AddressToId($Arbiter)
+            if $arbiter == 0 {
+                warning Sprintf("Arbiter %%s is invalid", $Arbiter)
+            }
+        }
+    }
+
+    action {
+        $result = EscrowLock($recipient, $arbiter, $Amount, $RefundBlock, $RefundTime, $Comment)
+    }
+}
+', 'ContractConditions("MainCondition")', '1', '1'),
+	(next_id('1_contracts'), 'EscrowRefund', 'contract EscrowRefund {
+    data {
+        EscrowId int
+    }
+
+    action {
+        EscrowRefund($EscrowId)
+    }
+}
+', 'ContractConditions("MainCondition")', '1', '1'),
+	(next_id('1_contracts'), 'EscrowRelease', 'contract EscrowRelease {
+    data {
+        EscrowId int
+    }
+
+    action {
+        EscrowRelease($EscrowId)
+    }
+}
 ', 'ContractConditions("MainCondition")', '1', '1'),
 	(next_id('1_contracts'), 'Import', 'contract Import {
     data {
@@ -552,6 +713,34 @@ VALUES
         }
     }
 }
+', 'ContractConditions("MainCondition")', '1', '1'),
+	(next_id('1_contracts'), 'NewAPIKey', 'contract NewAPIKey {
+    data {
+        Name string
+        KeyHash string
+        Account string "optional"
+        RoleId int "optional"
+        Routes string
+        Expire int "optional"
+    }
+
+    conditions {
+        $owner = $key_id
+        if $Account {
+            $owner = AddressToId($Account)
+            if $owner == 0 {
+                warning Sprintf("Account %%s is not valid", $Account)
+            }
+            if $owner != $key_id {
+                ContractConditions("MainCondition")
+            }
+        }
+    }
+
+    action {
+        $result = APIKeyCreate($Name, $KeyHash, $owner, $RoleId, $Routes, $Expire)
+    }
+}
 ', 'ContractConditions("MainCondition")', '1', '1'),
 	(next_id('1_contracts'), 'NewAppParam', 'contract NewAppParam {
     data {
@@ -601,6 +790,28 @@ VALUES
         $result = DBInsert("applications", {name: $Name,conditions: $Conditions})
     }
 }
+', 'ContractConditions("MainCondition")', '1', '1'),
+	(next_id('1_contracts'), 'NewAsset', 'contract NewAsset {
+    data {
+        Name string
+        Recipient string "optional"
+        BinaryId int "optional"
+    }
+
+    conditions {
+        $recipient = $key_id
+        if Size($Recipient) > 0 {
+            $recipient = AddressToId($Recipient)
+            if $recipient == 0 {
+                warning Sprintf("Recipient %%s is invalid", $Recipient)
+            }
+        }
+    }
+
+    action {
+        $result = AssetMint($Name, $recipient, $BinaryId)
+    }
+}
 ', 'ContractConditions("MainCondition")', '1', '1'),
 	(next_id('1_contracts'), 'NewBadBlock', 'contract NewBadBlock {
 	data {
@@ -634,6 +845,11 @@ VALUES
         if DBFind("blocks").Columns("id").Where({name:$Name}).One("id") {
             warning Sprintf( "Block %%s already exists", $Name)
         }
+        var lint string
+        lint = LintTemplate($Value)
+        if lint {
+            warning Sprintf("Template error %%s", lint)
+        }
     }
 
     action {
@@ -773,6 +989,12 @@ VALUES
             warning Sprintf( "Page %%s already exists", $Name)
         }
 
+        var lint string
+        lint = LintTemplate($Value)
+        if lint {
+            warning Sprintf("Template error %%s", lint)
+        }
+
         $ValidateCount = preparePageValidateCount($ValidateCount)
 
         if $ValidateMode {
@@ -791,6 +1013,53 @@ VALUES
         return SysParamInt("page_price")
     }
 }
+', 'ContractConditions("MainCondition")', '1', '1'),
+	(next_id('1_contracts'), 'NewProposal', 'contract NewProposal {
+    data {
+        Kind string
+        Name string
+        Value string
+        RoleId int "optional"
+        Quorum int
+        Threshold int
+        Duration int
+        Timelock int "optional"
+    }
+
+    action {
+        $result = ProposalCreate($Kind, $Name, $Value, $RoleId, $Quorum, $Threshold, $Duration, $Timelock)
+    }
+}
+', 'ContractConditions("MainCondition")', '1', '1'),
+	(next_id('1_contracts'), 'NewRateLimit', 'contract NewRateLimit {
+    data {
+        Contract string
+        MaxCalls int
+        Period int
+        Conditions string
+    }
+
+    conditions {
+        if $Contract != "*" && !GetContractByName($Contract) {
+            warning Sprintf("Contract %%s has not been found", $Contract)
+        }
+        if $MaxCalls <= 0 {
+            warning "MaxCalls must be greater than 0"
+        }
+        if $Period <= 0 {
+            warning "Period must be greater than 0"
+        }
+        ValidateCondition($Conditions, $ecosystem_id)
+        if DBFind("rate_limits").Columns("id").Where({"contract": $Contract, "deleted": 0}).One("id") {
+            warning Sprintf("Rate limit for %%s already exists", $Contract)
+        }
+    }
+
+    action {
+        $result = DBInsert("rate_limits", {contract: $Contract, max_calls: $MaxCalls,
+              period: $Period, conditions: $Conditions})
+    }
+}
 ', 'ContractConditions("MainCondition")', '1', '1'),
 	(next_id('1_contracts'), 'NewTable', 'contract NewTable {
     data {
@@ -813,6 +1082,18 @@ VALUES
         return SysParamInt("table_price")
     }
 }
+', 'ContractConditions("MainCondition")', '1', '1'),
+	(next_id('1_contracts'), 'NewToken', 'contract NewToken {
+    data {
+        Symbol string
+        Name string
+        Decimals int
+    }
+
+    action {
+        $result = TokenCreate($Symbol, $Name, $Decimals)
+    }
+}
 ', 'ContractConditions("MainCondition")', '1', '1'),
 	(next_id('1_contracts'), 'NewUser', 'contract NewUser {
 	data {
@@ -861,6 +1142,167 @@ VALUES
         }
 	}
 }
+', 'ContractConditions("MainCondition")', '1', '1'),
+	(next_id('1_contracts'), 'ProposalVote', 'contract ProposalVote {
+    data {
+        ProposalId int
+        Accept bool
+    }
+
+    action {
+        ProposalVote($ProposalId, $Accept)
+    }
+}
+', 'ContractConditions("MainCondition")', '1', '1'),
+	(next_id('1_contracts'), 'RenderBinary', 'contract RenderBinary {
+    data {
+        ApplicationId int
+        Name string
+        Template string
+        Params string "optional"
+    }
+    conditions {
+        $Id = Int(DBFind("@1binaries").Columns("id").Where({"app_id": $ApplicationId,
+                "account": $account_id, "name": $Name, "ecosystem": $ecosystem_id}).One("id"))
+        if $Id == 0 {
+            if $ApplicationId == 0 {
+                warning LangRes("@1aid_cannot_zero", "en")
+            }
+        }
+    }
+    action {
+        var pars map
+        var doc bytes
+        var hash string
+        if $Params {
+            pars = JSONDecode($Params)
+        }
+        doc = RenderHTML($Template, pars)
+        hash = Hash(doc)
+        if $Id != 0 {
+            DBUpdate("@1binaries", $Id, {"data": doc, "hash": hash, "mime_type": "text/html"})
+        } else {
+            $Id = DBInsert("@1binaries", {"app_id": $ApplicationId, "account": $account_id,
+                "name": $Name, "data": doc, "hash": hash, "mime_type": "text/html", "ecosystem": $ecosystem_id})
+        }
+        $result = $Id
+    }
+}
+', 'ContractConditions("MainCondition")', '1', '1'),
+	(next_id('1_contracts'), 'RevokeAPIKey', 'contract RevokeAPIKey {
+    data {
+        Id int
+    }
+
+    conditions {
+        var row map
+        row = DBRow("api_keys").Columns("key_id,creator").WhereId($Id)
+        if !row {
+            warning Sprintf("API key %%d has not been found", $Id)
+        }
+        if Int(row["key_id"]) != $key_id && Int(row["creator"]) != $key_id {
+            ContractConditions("MainCondition")
+        }
+    }
+
+    action {
+        APIKeyRevoke($Id)
+    }
+}
+', 'ContractConditions("MainCondition")', '1', '1'),
+	(next_id('1_contracts'), 'TokenApprove', 'contract TokenApprove {
+    data {
+        TokenId int
+        Spender string
+        Amount money
+    }
+
+    conditions {
+        $spender = AddressToId($Spender)
+        if $spender == 0 {
+            warning Sprintf("Spender %%s is invalid", $Spender)
+        }
+    }
+
+    action {
+        TokenApprove($TokenId, $spender, $Amount)
+    }
+}
+', 'ContractConditions("MainCondition")', '1', '1'),
+	(next_id('1_contracts'), 'TokenBurn', 'contract TokenBurn {
+    data {
+        TokenId int
+        Amount money
+    }
+
+    action {
+        TokenBurn($TokenId, $Amount)
+    }
+}
+', 'ContractConditions("MainCondition")', '1', '1'),
+	(next_id('1_contracts'), 'TokenMint', 'contract TokenMint {
+    data {
+        TokenId int
+        Recipient string
+        Amount money
+    }
+
+    conditions {
+        $recipient = AddressToId($Recipient)
+        if $recipient == 0 {
+            warning Sprintf("Recipient %%s is invalid", $Recipient)
+        }
+    }
+
+    action {
+        TokenMint($TokenId, $recipient, $Amount)
+    }
+}
+', 'ContractConditions("MainCondition")', '1', '1'),
+	(next_id('1_contracts'), 'TokenTransfer', 'contract TokenTransfer {
+    data {
+        TokenId int
+        Recipient string
+        Amount money
+        Comment string "optional"
+    }
+
+    conditions {
+        $recipient = AddressToId($Recipient)
+        if $recipient == 0 {
+            warning Sprintf("Recipient %%s is invalid", $Recipient)
+        }
+    }
+
+    action {
+        TokenTransfer($TokenId, $recipient, $Amount, $Comment)
+    }
+}
+', 'ContractConditions("MainCondition")', '1', '1'),
+	(next_id('1_contracts'), 'TokenTransferFrom', 'contract TokenTransferFrom {
+    data {
+        TokenId int
+        Owner string
+        Recipient string
+        Amount money
+        Comment string "optional"
+    }
+
+    conditions {
+        $owner = AddressToId($Owner)
+        if $owner == 0 {
+            warning Sprintf("Owner %%s is invalid", $Owner)
+        }
+        $recipient = AddressToId($Recipient)
+        if $recipient == 0 {
+            warning Sprintf("Recipient %%s is invalid", $Recipient)
+        }
+    }
+
+    action {
+        TokenTransferFrom($TokenId, $owner, $recipient, $Amount, $Comment)
+    }
+}
 ', 'ContractConditions("MainCondition")', '1', '1'),
 	(next_id('1_contracts'), 'UnbindWallet', 'contract UnbindWallet {
 	data {
@@ -0,0 +1,397 @@
+// Copyright (C) 2017, 2018, 2019 EGAAS S.A.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or (at
+// your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but
+// WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301, USA.
+
+package updates
+
+var M300 = `
+	DROP DOMAIN IF EXISTS "richtext" CASCADE;
+	CREATE DOMAIN "richtext" AS text;
+
+	ALTER TABLE "1_history" ADD COLUMN "token_id" bigint NOT NULL DEFAULT '0';
+	CREATE INDEX "1_history_index_token" ON "1_history" (ecosystem, token_id);
+	UPDATE "1_tables" SET columns = columns || '{"token_id": "false"}'::jsonb
+	WHERE ecosystem = '1' AND name = 'history';
+
+	DROP TABLE IF EXISTS "1_rate_limits";
+	CREATE TABLE "1_rate_limits" (
+	"id" bigint NOT NULL DEFAULT '0',
+	"contract" varchar(255) NOT NULL DEFAULT '',
+	"max_calls" bigint NOT NULL DEFAULT '0',
+	"period" bigint NOT NULL DEFAULT '0',
+	"conditions" text NOT NULL DEFAULT '',
+	"deleted" bigint NOT NULL DEFAULT '0',
+	"ecosystem" bigint NOT NULL DEFAULT '1'
+	);
+	ALTER TABLE ONLY "1_rate_limits" ADD CONSTRAINT "1_rate_limits_pkey" PRIMARY KEY ("id");
+	CREATE INDEX "1_rate_limits_index_contract" ON "1_rate_limits" (ecosystem, contract);
+
+	DROP TABLE IF EXISTS "1_rate_limit_usage";
+	CREATE TABLE "1_rate_limit_usage" (
+	"id" bigint NOT NULL DEFAULT '0',
+	"contract" varchar(255) NOT NULL DEFAULT '',
+	"key_id" bigint NOT NULL DEFAULT '0',
+	"block_id" bigint NOT NULL DEFAULT '0',
+	"time" bigint NOT NULL DEFAULT '0',
+	"ecosystem" bigint NOT NULL DEFAULT '1'
+	);
+	ALTER TABLE ONLY "1_rate_limit_usage" ADD CONSTRAINT "1_rate_limit_usage_pkey" PRIMARY KEY ("id");
+	CREATE INDEX "1_rate_limit_usage_index_key" ON "1_rate_limit_usage" (ecosystem, key_id, contract, time);
+
+	DROP TABLE IF EXISTS "1_tokens";
+	CREATE TABLE "1_tokens" (
+	"id" bigint NOT NULL DEFAULT '0',
+	"symbol" varchar(32) NOT NULL DEFAULT '',
+	"name" varchar(255) NOT NULL DEFAULT '',
+	"decimals" bigint NOT NULL DEFAULT '0',
+	"owner" bigint NOT NULL DEFAULT '0',
+	"total_supply" decimal(30) NOT NULL DEFAULT '0' CHECK (total_supply >= 0),
+	"ecosystem" bigint NOT NULL DEFAULT '1',
+	UNIQUE (ecosystem, symbol)
+	);
+	ALTER TABLE ONLY "1_tokens" ADD CONSTRAINT "1_tokens_pkey" PRIMARY KEY ("id");
+
+	DROP TABLE IF EXISTS "1_token_balances";
+	CREATE TABLE "1_token_balances" (
+	"id" bigint NOT NULL DEFAULT '0',
+	"token_id" bigint NOT NULL DEFAULT '0',
+	"key_id" bigint NOT NULL DEFAULT '0',
+	"amount" decimal(30) NOT NULL DEFAULT '0' CHECK (amount >= 0),
+	"ecosystem" bigint NOT NULL DEFAULT '1',
+	UNIQUE (ecosystem, token_id, key_id)
+	);
+	ALTER TABLE ONLY "1_token_balances" ADD CONSTRAINT "1_token_balances_pkey" PRIMARY KEY ("id");
+	CREATE INDEX "1_token_balances_index_key" ON "1_token_balances" (ecosystem, key_id);
+
+	DROP TABLE IF EXISTS "1_token_allowances";
+	CREATE TABLE "1_token_allowances" (
+	"id" bigint NOT NULL DEFAULT '0',
+	"token_id" bigint NOT NULL DEFAULT '0',
+	"owner_id" bigint NOT NULL DEFAULT '0',
+	"spender_id" bigint NOT NULL DEFAULT '0',
+	"amount" decimal(30) NOT NULL DEFAULT '0' CHECK (amount >= 0),
+	"ecosystem" bigint NOT NULL DEFAULT '1',
+	UNIQUE (ecosystem, token_id, owner_id, spender_id)
+	);
+	ALTER TABLE ONLY "1_token_allowances" ADD CONSTRAINT "1_token_allowances_pkey" PRIMARY KEY ("id");
+
+	DROP TABLE IF EXISTS "1_assets";
+	CREATE TABLE "1_assets" (
+	"id" bigint NOT NULL DEFAULT '0',
+	"name" varchar(255) NOT NULL DEFAULT '',
+	"binary_id" bigint NOT NULL DEFAULT '0',
+	"owner" bigint NOT NULL DEFAULT '0',
+	"creator" bigint NOT NULL DEFAULT '0',
+	"created_at" bigint NOT NULL DEFAULT '0',
+	"ecosystem" bigint NOT NULL DEFAULT '1'
+	);
+	ALTER TABLE ONLY "1_assets" ADD CONSTRAINT "1_assets_pkey" PRIMARY KEY ("id");
+	CREATE INDEX "1_assets_index_owner" ON "1_assets" (ecosystem, owner);
+
+	DROP TABLE IF EXISTS "1_asset_operators";
+	CREATE TABLE "1_asset_operators" (
+	"id" bigint NOT NULL DEFAULT '0',
+	"owner_id" bigint NOT NULL DEFAULT '0',
+	"operator_id" bigint NOT NULL DEFAULT '0',
+	"approved" bigint NOT NULL DEFAULT '0',
+	"ecosystem" bigint NOT NULL DEFAULT '1',
+	UNIQUE (ecosystem, owner_id, operator_id)
+	);
+	ALTER TABLE ONLY "1_asset_operators" ADD CONSTRAINT "1_asset_operators_pkey" PRIMARY KEY ("id");
+
+	DROP TABLE IF EXISTS "1_escrows";
+	CREATE TABLE "1_escrows" (
+	"id" bigint NOT NULL DEFAULT '0',
+	"sender_id" bigint NOT NULL DEFAULT '0',
+	"recipient_id" bigint NOT NULL DEFAULT '0',
+	"arbiter_id" bigint NOT NULL DEFAULT '0',
+	"amount" decimal(30) NOT NULL DEFAULT '0' CHECK (amount >= 0),
+	"refund_block" bigint NOT NULL DEFAULT '0',
+	"refund_time" bigint NOT NULL DEFAULT '0',
+	"approved" bigint NOT NULL DEFAULT '0',
+	"status" bigint NOT NULL DEFAULT '0',
+	"comment" text NOT NULL DEFAULT '',
+	"created_at" bigint NOT NULL DEFAULT '0',
+	"ecosystem" bigint NOT NULL DEFAULT '1'
+	);
+	ALTER TABLE ONLY "1_escrows" ADD CONSTRAINT "1_escrows_pkey" PRIMARY KEY ("id");
+	CREATE INDEX "1_escrows_index_sender" ON "1_escrows" (ecosystem, sender_id, status);
+	CREATE INDEX "1_escrows_index_recipient" ON "1_escrows" (ecosystem, recipient_id, status);
+
+	DROP TABLE IF EXISTS "1_proposals";
+	CREATE TABLE "1_proposals" (
+	"id" bigint NOT NULL DEFAULT '0',
+	"kind" varchar(32) NOT NULL DEFAULT '',
+	"name" varchar(255) NOT NULL DEFAULT '',
+	"value" text NOT NULL DEFAULT '',
+	"creator" bigint NOT NULL DEFAULT '0',
+	"role_id" bigint NOT NULL DEFAULT '0',
+	"quorum" bigint NOT NULL DEFAULT '0',
+	"threshold" bigint NOT NULL DEFAULT '0',
+	"deadline" bigint NOT NULL DEFAULT '0',
+	"timelock" bigint NOT NULL DEFAULT '0',
+	"votes_for" bigint NOT NULL DEFAULT '0',
+	"votes_against" bigint NOT NULL DEFAULT '0',
+	"voters" bigint NOT NULL DEFAULT '0',
+	"status" bigint NOT NULL DEFAULT '0',
+	"created_at" bigint NOT NULL DEFAULT '0',
+	"ecosystem" bigint NOT NULL DEFAULT '1'
+	);
+	ALTER TABLE ONLY "1_proposals" ADD CONSTRAINT "1_proposals_pkey" PRIMARY KEY ("id");
+	CREATE INDEX "1_proposals_index_status" ON "1_proposals" (status, deadline);
+
+	DROP TABLE IF EXISTS "1_proposal_votes";
+	CREATE TABLE "1_proposal_votes" (
+	"id" bigint NOT NULL DEFAULT '0',
+	"proposal_id" bigint NOT NULL DEFAULT '0',
+	"key_id" bigint NOT NULL DEFAULT '0',
+	"vote" bigint NOT NULL DEFAULT '0',
+	"created_at" bigint NOT NULL DEFAULT '0',
+	"ecosystem" bigint NOT NULL DEFAULT '1',
+	UNIQUE (ecosystem, proposal_id, key_id)
+	);
+	ALTER TABLE ONLY "1_proposal_votes" ADD CONSTRAINT "1_proposal_votes_pkey" PRIMARY KEY ("id");
+
+	DROP TABLE IF EXISTS "1_api_keys";
+	CREATE TABLE "1_api_keys" (
+	"id" bigint NOT NULL DEFAULT '0',
+	"name" varchar(255) NOT NULL DEFAULT '',
+	"key_hash" varchar(64) NOT NULL DEFAULT '',
+	"key_id" bigint NOT NULL DEFAULT '0',
+	"role_id" bigint NOT NULL DEFAULT '0',
+	"routes" text NOT NULL DEFAULT '',
+	"expire" bigint NOT NULL DEFAULT '0',
+	"creator" bigint NOT NULL DEFAULT '0',
+	"created_at" bigint NOT NULL DEFAULT '0',
+	"revoked" bigint NOT NULL DEFAULT '0',
+	"ecosystem" bigint NOT NULL DEFAULT '1',
+	UNIQUE (key_hash)
+	);
+	ALTER TABLE ONLY "1_api_keys" ADD CONSTRAINT "1_api_keys_pkey" PRIMARY KEY ("id");
+	CREATE INDEX "1_api_keys_index_key" ON "1_api_keys" (ecosystem, key_id);
+
+	INSERT INTO "1_tables" ("id", "name", "permissions","columns", "conditions", "ecosystem") VALUES
+    (next_id('1_tables'), 'rate_limits',
+        '{
+            "insert": "ContractAccess(\"@1NewRateLimit\")",
+            "update": "ContractAccess(\"@1EditRateLimit\")",
+            "new_column": "ContractConditions(\"@1AdminCondition\")"
+        }',
+        '{
+            "contract": "false",
+            "max_calls": "ContractAccess(\"@1EditRateLimit\")",
+            "period": "ContractAccess(\"@1EditRateLimit\")",
+            "conditions": "ContractAccess(\"@1EditRateLimit\")",
+            "deleted": "ContractAccess(\"@1EditRateLimit\")",
+            "ecosystem": "false"
+        }',
+        'ContractConditions("@1AdminCondition")', '1'
+    ),
+    (next_id('1_tables'), 'tokens',
+        '{
+            "insert": "false",
+            "update": "false",
+            "new_column": "ContractConditions(\"@1AdminCondition\")"
+        }',
+        '{
+            "symbol": "false",
+            "name": "false",
+            "decimals": "false",
+            "owner": "false",
+            "total_supply": "false",
+            "ecosystem": "false"
+        }',
+        'ContractConditions("@1AdminCondition")', '1'
+    ),
+    (next_id('1_tables'), 'token_balances',
+        '{
+            "insert": "false",
+            "update": "false",
+            "new_column": "ContractConditions(\"@1AdminCondition\")"
+        }',
+        '{
+            "token_id": "false",
+            "key_id": "false",
+            "amount": "false",
+            "ecosystem": "false"
+        }',
+        'ContractConditions("@1AdminCondition")', '1'
+    ),
+    (next_id('1_tables'), 'token_allowances',
+        '{
+            "insert": "false",
+            "update": "false",
+            "new_column": "ContractConditions(\"@1AdminCondition\")"
+        }',
+        '{
+            "token_id": "false",
+            "owner_id": "false",
+            "spender_id": "false",
+            "amount": "false",
+            "ecosystem": "false"
+        }',
+        'ContractConditions("@1AdminCondition")', '1'
+    ),
+    (next_id('1_tables'), 'assets',
+        '{
+            "insert": "false",
+            "update": "false",
+            "new_column": "ContractConditions(\"@1AdminCondition\")"
+        }',
+        '{
+            "name": "false",
+            "binary_id": "false",
+            "owner": "false",
+            "creator": "false",
+            "created_at": "false",
+            "ecosystem": "false"
+        }',
+        'ContractConditions("@1AdminCondition")', '1'
+    ),
+    (next_id('1_tables'), 'asset_operators',
+        '{
+            "insert": "false",
+            "update": "false",
+            "new_column": "ContractConditions(\"@1AdminCondition\")"
+        }',
+        '{
+            "owner_id": "false",
+            "operator_id": "false",
+            "approved": "false",
+            "ecosystem": "false"
+        }',
+        'ContractConditions("@1AdminCondition")', '1'
+    ),
+    (next_id('1_tables'), 'escrows',
+        '{
+            "insert": "false",
+            "update": "false",
+            "new_column": "ContractConditions(\"@1AdminCondition\")"
+        }',
+        '{
+            "sender_id": "false",
+            "recipient_id": "false",
+            "arbiter_id": "false",
+            "amount": "false",
+            "refund_block": "false",
+            "refund_time": "false",
+            "approved": "false",
+            "status": "false",
+            "comment": "false",
+            "created_at": "false",
+            "ecosystem": "false"
+        }',
+        'ContractConditions("@1AdminCondition")', '1'
+    ),
+    (next_id('1_tables'), 'proposals',
+        '{
+            "insert": "false",
+            "update": "false",
+            "new_column": "ContractConditions(\"@1AdminCondition\")"
+        }',
+        '{
+            "kind": "false",
+            "name": "false",
+            "value": "false",
+            "creator": "false",
+            "role_id": "false",
+            "quorum": "false",
+            "threshold": "false",
+            "deadline": "false",
+            "timelock": "false",
+            "votes_for": "false",
+            "votes_against": "false",
+            "voters": "false",
+            "status": "false",
+            "created_at": "false",
+            "ecosystem": "false"
+        }',
+        'ContractConditions("@1AdminCondition")', '1'
+    ),
+    (next_id('1_tables'), 'proposal_votes',
+        '{
+            "insert": "false",
+            "update": "false",
+            "new_column": "ContractConditions(\"@1AdminCondition\")"
+        }',
+        '{
+            "proposal_id": "false",
+            "key_id": "false",
+            "vote": "false",
+            "created_at": "false",
+            "ecosystem": "false"
+        }',
+        'ContractConditions("@1AdminCondition")', '1'
+    ),
+    (next_id('1_tables'), 'api_keys',
+        '{
+            "insert": "false",
+            "update": "false",
+            "new_column": "ContractConditions(\"@1AdminCondition\")"
+        }',
+        '{
+            "name": "false",
+            "key_hash": "false",
+            "key_id": "false",
+            "role_id": "false",
+            "routes": "false",
+            "expire": "false",
+            "creator": "false",
+            "created_at": "false",
+            "revoked": "false",
+            "ecosystem": "false"
+        }',
+        'ContractConditions("@1AdminCondition")', '1'
+    );
+
+	DROP TABLE IF EXISTS "tx_callbacks";
+	CREATE TABLE "tx_callbacks" (
+	"hash" bytea  NOT NULL DEFAULT '',
+	"url" varchar(255)  NOT NULL DEFAULT '',
+	"time" int  NOT NULL DEFAULT '0',
+	"attempts" int  NOT NULL DEFAULT '0',
+	"next_time" int  NOT NULL DEFAULT '0'
+	);
+	ALTER TABLE ONLY "tx_callbacks" ADD CONSTRAINT "tx_callbacks_pkey" PRIMARY KEY (hash);
+	CREATE INDEX "tx_callbacks_index_next_time" ON "tx_callbacks" (next_time);
+
+	DROP TABLE IF EXISTS "revoked_tokens";
+	CREATE TABLE "revoked_tokens" (
+	"id" varchar(64)  NOT NULL DEFAULT '',
+	"expire" int  NOT NULL DEFAULT '0'
+	);
+	ALTER TABLE ONLY "revoked_tokens" ADD CONSTRAINT "revoked_tokens_pkey" PRIMARY KEY (id);
+	CREATE INDEX "revoked_tokens_index_expire" ON "revoked_tokens" (expire);
+
+	DROP TABLE IF EXISTS "tx_index";
+	CREATE TABLE "tx_index" (
+	"hash" bytea  NOT NULL DEFAULT '',
+	"block_id" bigint  NOT NULL DEFAULT '0',
+	"key_id" bigint  NOT NULL DEFAULT '0',
+	"ecosystem" bigint  NOT NULL DEFAULT '0',
+	"contract" varchar(255)  NOT NULL DEFAULT '',
+	"time" bigint  NOT NULL DEFAULT '0'
+	);
+	ALTER TABLE ONLY "tx_index" ADD CONSTRAINT "tx_index_pkey" PRIMARY KEY (hash);
+	CREATE INDEX "tx_index_index_key" ON "tx_index" (key_id, block_id);
+	CREATE INDEX "tx_index_index_contract" ON "tx_index" (contract, block_id);
+	CREATE INDEX "tx_index_index_ecosystem" ON "tx_index" (ecosystem, block_id);
+
+	DROP TABLE IF EXISTS "tx_index_start";
+	CREATE TABLE "tx_index_start" (
+	"block_id" bigint  NOT NULL DEFAULT '0'
+	);
+	INSERT INTO "tx_index_start" (block_id) SELECT coalesce(max(id), 0) + 1 FROM "block_chain";
+`
@@ -0,0 +1,84 @@
+// Copyright (C) 2017, 2018, 2019 EGAAS S.A.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or (at
+// your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but
+// WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301, USA.
+
+package model
+
+import "strings"
+
+const (
+	tableAPIKeys = "1_api_keys"
+
+	// APIKeyAnyRoute is the route of API key which allows all routes
+	APIKeyAnyRoute = "*"
+)
+
+// APIKey represents record of 1_api_keys table
+type APIKey struct {
+	ecosystem int64
+	ID        int64  `gorm:"primary_key;not null" json:"id"`
+	Name      string `gorm:"not null;size:255" json:"name"`
+	KeyHash   string `gorm:"not null;size:64" json:"key_hash"`
+	KeyID     int64  `gorm:"not null" json:"key_id"`
+	RoleID    int64  `gorm:"not null" json:"role_id"`
+	Routes    string `gorm:"not null" json:"routes"`
+	Expire    int64  `gorm:"not null" json:"expire"`
+	Creator   int64  `gorm:"not null" json:"creator"`
+	CreatedAt int64  `gorm:"not null" json:"created_at"`
+	Revoked   int64  `gorm:"not null" json:"revoked"`
+	Ecosystem int64  `gorm:"not null" json:"ecosystem"`
+}
+
+// SetTablePrefix is setting table prefix
+func (k *APIKey) SetTablePrefix(prefix int64) *APIKey {
+	k.ecosystem = prefix
+	return k
+}
+
+// TableName returns name of table
+func (k *APIKey) TableName() string {
+	if k.ecosystem == 0 {
+		k.ecosystem = 1
+	}
+	return tableAPIKeys
+}
+
+// Get is retrieving the API key of the ecosystem by id
+func (k *APIKey) Get(transaction *DbTransaction, id int64) (bool, error) {
+	return isFound(GetDB(transaction).Where("ecosystem = ? and id = ?", k.ecosystem, id).First(k))
+}
+
+// GetByHash is retrieving the API key by the hash, the hash is unique for all ecosystems
+func (k *APIKey) GetByHash(transaction *DbTransaction, hash string) (bool, error) {
+	return isFound(GetDB(transaction).Where("key_hash = ?", hash).First(k))
+}
+
+// IsActive returns true if the key isn't revoked and isn't expired at the specified time
+func (k *APIKey) IsActive(now int64) bool {
+	return k.Revoked == 0 && (k.Expire == 0 || k.Expire > now)
+}
+
+// AllowRoute returns true if the route template is allowed for the key.
+// The route of the key can end with * to allow all routes with this prefix
+func (k *APIKey) AllowRoute(route string) bool {
+	for _, item := range strings.Split(k.Routes, ",") {
+		item = strings.TrimSpace(item)
+		if item == route || (strings.HasSuffix(item, APIKeyAnyRoute) &&
+			strings.HasPrefix(route, strings.TrimSuffix(item, APIKeyAnyRoute))) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+// Copyright (C) 2017, 2018, 2019 EGAAS S.A.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or (at
+// your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but
+// WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301, USA.
+
+package model
+
+const (
+	tableAssets         = "1_assets"
+	tableAssetOperators = "1_asset_operators"
+)
+
+// Asset represents record of 1_assets table
+type Asset struct {
+	ecosystem int64
+	ID        int64  `gorm:"primary_key;not null" json:"id"`
+	Name      string `gorm:"not null;size:255" json:"name"`
+	BinaryID  int64  `gorm:"not null" json:"binary_id"`
+	Owner     int64  `gorm:"not null" json:"owner"`
+	Creator   int64  `gorm:"not null" json:"creator"`
+	CreatedAt int64  `gorm:"not null" json:"created_at"`
+}
+
+// SetTablePrefix is setting table prefix
+func (a *Asset) SetTablePrefix(prefix int64) *Asset {
+	a.ecosystem = prefix
+	return a
+}
+
+// TableName returns name of table
+func (a *Asset) TableName() string {
+	if a.ecosystem == 0 {
+		a.ecosystem = 1
+	}
+	return tableAssets
+}
+
+// Get is retrieving the asset by id
+func (a *Asset) Get(transaction *DbTransaction, id int64) (bool, error) {
+	return isFound(GetDB(transaction).Where("ecosystem = ? and id = ?", a.ecosystem, id).First(a))
+}
+
+// AssetOperator represents record of 1_asset_operators table
+type AssetOperator struct {
+	ecosystem  int64
+	ID         int64 `gorm:"primary_key;not null"`
+	OwnerID    int64 `gorm:"not null"`
+	OperatorID int64 `gorm:"not null"`
+	Approved   int64 `gorm:"not null"`
+}
+
+// SetTablePrefix is setting table prefix
+func (ao *AssetOperator) SetTablePrefix(prefix int64) *AssetOperator {
+	ao.ecosystem = prefix
+	return ao
+}
+
+// TableName returns name of table
+func (ao *AssetOperator) TableName() string {
+	if ao.ecosystem == 0 {
+		ao.ecosystem = 1
+	}
+	return tableAssetOperators
+}
+
+// Get is retrieving the operator record of the owner
+func (ao *AssetOperator) Get(transaction *DbTransaction, ownerID, operatorID int64) (bool, error) {
+	return isFound(GetDB(transaction).Where("ecosystem = ? and owner_id = ? and operator_id = ?",
+		ao.ecosystem, ownerID, operatorID).First(ao))
+}
@@ -64,6 +64,12 @@ func (b *Binary) Link() string {
 	return fmt.Sprintf(`/data/%s/%d/%s/%s`, b.TableName(), b.ID, "data", b.Hash)
 }
 
+// GetInfo is retrieving name and hash of the binary of the ecosystem by id
+func (b *Binary) GetInfo(transaction *DbTransaction, id int64) (bool, error) {
+	return isFound(GetDB(transaction).Where("ecosystem=? and id=?", b.ecosystem, id).
+		Select("id,name,hash,mime_type").First(b))
+}
+
 // GetByID is retrieving model from db by id
 func (b *Binary) GetByID(id int64) (bool, error) {
 	return isFound(DBConn.Where("id=?", id).First(b))
@@ -45,6 +45,11 @@ func (bi *BlockInterface) Get(name string) (bool, error) {
 	return isFound(DBConn.Where("ecosystem=? and name = ?", bi.ecosystem, name).First(bi))
 }
 
+// GetTransaction is retrieving model from database using transaction
+func (bi *BlockInterface) GetTransaction(transaction *DbTransaction, name string) (bool, error) {
+	return isFound(GetDB(transaction).Where("ecosystem=? and name = ?", bi.ecosystem, name).First(bi))
+}
+
 // GetByApp returns all interface blocks belonging to selected app
 func (bi *BlockInterface) GetByApp(appID int64, ecosystemID int64) ([]BlockInterface, error) {
 	var result []BlockInterface
@@ -314,14 +314,19 @@ func CreateIndex(transaction *DbTransaction, indexName, tableName, onColumn stri
 
 // GetColumnDataTypeCharMaxLength is returns max length of table column
 func GetColumnDataTypeCharMaxLength(tableName, columnName string) (map[string]string, error) {
-	return GetOneRow(`select data_type,character_maximum_length from
+	return GetOneRow(`select coalesce(domain_name, data_type) as data_type,character_maximum_length from
 			 information_schema.columns where table_name = ? AND column_name = ?`,
 		tableName, columnName).String()
 }
 
 // GetAllColumnTypes returns column types for table
 func GetAllColumnTypes(tblname string) ([]map[string]string, error) {
-	return GetAll(`SELECT column_name, data_type
+	return GetAllColumnTypesTx(nil, tblname)
+}
+
+// GetAllColumnTypesTx returns column types for table within the transaction
+func GetAllColumnTypesTx(transaction *DbTransaction, tblname string) ([]map[string]string, error) {
+	return GetAllTx(transaction, `SELECT column_name, coalesce(domain_name, data_type) as data_type
 		FROM information_schema.columns
 		WHERE table_name = ?
 		ORDER BY ordinal_position ASC`, -1, tblname)
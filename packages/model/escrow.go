@@ -0,0 +1,76 @@
+// Copyright (C) 2017, 2018, 2019 EGAAS S.A.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or (at
+// your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but
+// WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301, USA.
+
+package model
+
+import (
+	"github.com/shopspring/decimal"
+)
+
+const tableEscrows = "1_escrows"
+
+// The statuses of escrow
+const (
+	EscrowLocked = iota
+	EscrowReleased
+	EscrowRefunded
+)
+
+// Escrow represents record of 1_escrows table
+type Escrow struct {
+	ecosystem   int64
+	ID          int64           `gorm:"primary_key;not null" json:"id"`
+	SenderID    int64           `gorm:"not null" json:"sender_id"`
+	RecipientID int64           `gorm:"not null" json:"recipient_id"`
+	ArbiterID   int64           `gorm:"not null" json:"arbiter_id"`
+	Amount      decimal.Decimal `gorm:"not null" json:"amount"`
+	RefundBlock int64           `gorm:"not null" json:"refund_block"`
+	RefundTime  int64           `gorm:"not null" json:"refund_time"`
+	Approved    int64           `gorm:"not null" json:"approved"`
+	Status      int64           `gorm:"not null" json:"status"`
+	Comment     string          `gorm:"not null" json:"comment"`
+	CreatedAt   int64           `gorm:"not null" json:"created_at"`
+}
+
+// SetTablePrefix is setting table prefix
+func (e *Escrow) SetTablePrefix(prefix int64) *Escrow {
+	e.ecosystem = prefix
+	return e
+}
+
+// TableName returns name of table
+func (e *Escrow) TableName() string {
+	if e.ecosystem == 0 {
+		e.ecosystem = 1
+	}
+	return tableEscrows
+}
+
+// Get is retrieving the escrow by id
+func (e *Escrow) Get(transaction *DbTransaction, id int64) (bool, error) {
+	return isFound(GetDB(transaction).Where("ecosystem = ? and id = ?", e.ecosystem, id).First(e))
+}
+
+// GetLockedAmount returns the sum of funds which are locked in escrows by the key
+func GetLockedAmount(transaction *DbTransaction, ecosystem, keyID int64) (decimal.Decimal, error) {
+	var res struct {
+		Amount decimal.Decimal
+	}
+	err := GetDB(transaction).Table(tableEscrows).Select("COALESCE(SUM(amount), 0) as amount").
+		Where("ecosystem = ? and sender_id = ? and status = ?", ecosystem, keyID, EscrowLocked).
+		Scan(&res).Error
+	return res.Amount, err
+}
@@ -75,7 +75,7 @@ func GetExcessCommonTokenMovementPerDay(tx *DbTransaction) (amount decimal.Decim
 
 	var res result
 	err = db.Table("1_history").Select("SUM(amount) as amount").
-		Where("to_timestamp(created_at) > NOW() - interval '24 hours' AND amount > 0").Scan(&res).Error
+		Where("to_timestamp(created_at) > NOW() - interval '24 hours' AND amount > 0 AND token_id = 0").Scan(&res).Error
 
 	return res.Amount, err
 }
@@ -85,7 +85,7 @@ func GetExcessFromToTokenMovementPerDay(tx *DbTransaction) (excess []APLTransfer
 	db := GetDB(tx)
 	err = db.Table("1_history").
 		Select("sender_id, recipient_id, SUM(amount) amount").
-		Where("to_timestamp(created_at) > NOW() - interval '24 hours' AND amount > 0").
+		Where("to_timestamp(created_at) > NOW() - interval '24 hours' AND amount > 0 AND token_id = 0").
 		Group("sender_id, recipient_id").
 		Having("SUM(amount) > ?", consts.FromToPerDayLimit).
 		Scan(&excess).Error
@@ -98,7 +98,7 @@ func GetExcessTokenMovementQtyPerBlock(tx *DbTransaction, blockID int64) (excess
 	db := GetDB(tx)
 	err = db.Table("1_history").
 		Select("sender_id, count(*) tx_count").
-		Where("block_id = ? AND amount > ?", blockID, 0).
+		Where("block_id = ? AND amount > ? AND token_id = 0", blockID, 0).
 		Group("sender_id").
 		Having("count(*) > ?", consts.TokenMovementQtyPerBlockLimit).
 		Scan(&excess).Error
@@ -58,6 +58,11 @@ func (m *Key) Get(wallet int64) (bool, error) {
 	return isFound(DBConn.Where("id = ? and ecosystem = ?", wallet, m.ecosystem).First(m))
 }
 
+// GetTransaction is retrieving model from database using transaction
+func (m *Key) GetTransaction(transaction *DbTransaction, wallet int64) (bool, error) {
+	return isFound(GetDB(transaction).Where("id = ? and ecosystem = ?", wallet, m.ecosystem).First(m))
+}
+
 func (m *Key) AccountKeyID() int64 {
 	if m.accountKeyID == 0 {
 		m.accountKeyID = converter.StringToAddress(m.AccountID)
@@ -70,16 +75,19 @@ func KeyTableName(prefix int64) string {
 	return fmt.Sprintf("%d_keys", prefix)
 }
 
-// GetKeysCount returns common count of keys
-func GetKeysCount() (int64, error) {
+// GetKeysCount returns common count of keys matching the where condition
+func GetKeysCount(where string) (int64, error) {
 	var cnt int64
-	row := DBConn.Raw(`SELECT count(*) key_count FROM "1_keys" WHERE ecosystem = 1`).Select("key_count").Row()
+	if len(where) > 0 {
+		where = ` AND (` + where + `)`
+	}
+	row := DBConn.Raw(`SELECT count(*) key_count FROM "1_keys" WHERE ecosystem = 1` + where).Select("key_count").Row()
 	err := row.Scan(&cnt)
 	return cnt, err
 }
 
 // GetKeys returns a list of keys records
-func GetKeys(prefix int64, order string, limit int64, offset int64) ([]Key) {
+func GetKeys(prefix int64, where, order string, limit int64, offset int64) ([]Key) {
 	if prefix == 0 {
 		prefix = 1
 	}
@@ -93,6 +101,10 @@ func GetKeys(prefix int64, order string, limit int64, offset int64) ([]Key) {
 	    offset = -1
 	}
 	var keys []Key
-	DBConn.Table(KeyTableName(prefix)).Order(order).Limit(limit).Offset(offset).Find(&keys)
+	query := DBConn.Table(KeyTableName(prefix))
+	if len(where) > 0 {
+		query = query.Where(where)
+	}
+	query.Order(order).Limit(limit).Offset(offset).Find(&keys)
 	return keys
 }
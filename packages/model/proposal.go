@@ -0,0 +1,147 @@
+// Copyright (C) 2017, 2018, 2019 EGAAS S.A.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or (at
+// your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but
+// WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301, USA.
+
+package model
+
+const (
+	tableProposals     = "1_proposals"
+	tableProposalVotes = "1_proposal_votes"
+)
+
+// The kinds of the changed parameters
+const (
+	ProposalSystem    = "system"
+	ProposalEcosystem = "ecosystem"
+)
+
+// The statuses of proposal
+const (
+	ProposalVoting = iota
+	ProposalAccepted
+	ProposalApplied
+	ProposalRejected
+)
+
+// Proposal represents record of 1_proposals table
+type Proposal struct {
+	ecosystem    int64
+	ID           int64  `gorm:"primary_key;not null" json:"id"`
+	Kind         string `gorm:"not null;size:32" json:"kind"`
+	Name         string `gorm:"not null;size:255" json:"name"`
+	Value        string `gorm:"not null" json:"value"`
+	Creator      int64  `gorm:"not null" json:"creator"`
+	RoleID       int64  `gorm:"not null" json:"role_id"`
+	Quorum       int64  `gorm:"not null" json:"quorum"`
+	Threshold    int64  `gorm:"not null" json:"threshold"`
+	Deadline     int64  `gorm:"not null" json:"deadline"`
+	Timelock     int64  `gorm:"not null" json:"timelock"`
+	VotesFor     int64  `gorm:"not null" json:"votes_for"`
+	VotesAgainst int64  `gorm:"not null" json:"votes_against"`
+	Voters       int64  `gorm:"not null" json:"voters"`
+	Status       int64  `gorm:"not null" json:"status"`
+	CreatedAt    int64  `gorm:"not null" json:"created_at"`
+	Ecosystem    int64  `gorm:"not null" json:"ecosystem"`
+}
+
+// SetTablePrefix is setting table prefix
+func (p *Proposal) SetTablePrefix(prefix int64) *Proposal {
+	p.ecosystem = prefix
+	return p
+}
+
+// TableName returns name of table
+func (p *Proposal) TableName() string {
+	if p.ecosystem == 0 {
+		p.ecosystem = 1
+	}
+	return tableProposals
+}
+
+// Get is retrieving the proposal by id
+func (p *Proposal) Get(transaction *DbTransaction, id int64) (bool, error) {
+	return isFound(GetDB(transaction).Where("ecosystem = ? and id = ?", p.ecosystem, id).First(p))
+}
+
+// GetList returns the proposals of the ecosystem. Negative status means all proposals
+func (p *Proposal) GetList(status, offset, limit int64) ([]Proposal, int64, error) {
+	var (
+		list  []Proposal
+		count int64
+	)
+	query := DBConn.Table(p.TableName()).Where("ecosystem = ?", p.ecosystem)
+	if status >= 0 {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Order("id desc").Offset(offset).Limit(limit).Find(&list).Error
+	return list, count, err
+}
+
+// GetExpiredProposals returns the proposals of all ecosystems which voting is over
+func GetExpiredProposals(transaction *DbTransaction, now int64) ([]Proposal, error) {
+	var list []Proposal
+	err := GetDB(transaction).Where("status = ? and deadline <= ?", ProposalVoting, now).
+		Order("id").Find(&list).Error
+	return list, err
+}
+
+// GetReadyProposals returns the accepted proposals of all ecosystems which timelock is over
+func GetReadyProposals(transaction *DbTransaction, now int64) ([]Proposal, error) {
+	var list []Proposal
+	err := GetDB(transaction).Where("status = ? and deadline + timelock <= ?", ProposalAccepted, now).
+		Order("id").Find(&list).Error
+	return list, err
+}
+
+// ProposalVote represents record of 1_proposal_votes table
+type ProposalVote struct {
+	ecosystem  int64
+	ID         int64 `gorm:"primary_key;not null" json:"id"`
+	ProposalID int64 `gorm:"not null" json:"proposal_id"`
+	KeyID      int64 `gorm:"not null" json:"key_id"`
+	Vote       int64 `gorm:"not null" json:"vote"`
+	CreatedAt  int64 `gorm:"not null" json:"created_at"`
+}
+
+// SetTablePrefix is setting table prefix
+func (pv *ProposalVote) SetTablePrefix(prefix int64) *ProposalVote {
+	pv.ecosystem = prefix
+	return pv
+}
+
+// TableName returns name of table
+func (pv *ProposalVote) TableName() string {
+	if pv.ecosystem == 0 {
+		pv.ecosystem = 1
+	}
+	return tableProposalVotes
+}
+
+// Get is retrieving the vote of the key
+func (pv *ProposalVote) Get(transaction *DbTransaction, proposalID, keyID int64) (bool, error) {
+	return isFound(GetDB(transaction).Where("ecosystem = ? and proposal_id = ? and key_id = ?",
+		pv.ecosystem, proposalID, keyID).First(pv))
+}
+
+// GetByProposal returns all votes of the proposal
+func (pv *ProposalVote) GetByProposal(proposalID int64) ([]ProposalVote, error) {
+	var list []ProposalVote
+	err := DBConn.Table(pv.TableName()).Where("ecosystem = ? and proposal_id = ?", pv.ecosystem, proposalID).
+		Order("id").Find(&list).Error
+	return list, err
+}
@@ -0,0 +1,121 @@
+// Copyright (C) 2017, 2018, 2019 EGAAS S.A.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or (at
+// your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but
+// WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301, USA.
+
+package model
+
+const (
+	tableRateLimits     = "1_rate_limits"
+	tableRateLimitUsage = "1_rate_limit_usage"
+
+	// RateLimitAnyContract is the name of the rate limit which applies to all contracts
+	RateLimitAnyContract = "*"
+)
+
+// RateLimit represents record of 1_rate_limits table
+type RateLimit struct {
+	ecosystem  int64
+	ID         int64  `gorm:"primary_key;not null"`
+	Contract   string `gorm:"not null;size:255"`
+	MaxCalls   int64  `gorm:"not null"`
+	Period     int64  `gorm:"not null"`
+	Conditions string `gorm:"not null"`
+	Deleted    int64  `gorm:"not null"`
+}
+
+// SetTablePrefix is setting table prefix
+func (rl *RateLimit) SetTablePrefix(prefix int64) *RateLimit {
+	rl.ecosystem = prefix
+	return rl
+}
+
+// TableName returns name of table
+func (rl *RateLimit) TableName() string {
+	if rl.ecosystem == 0 {
+		rl.ecosystem = 1
+	}
+	return tableRateLimits
+}
+
+// GetByContracts returns active rate limits of the ecosystem for the specified contract names
+func (rl *RateLimit) GetByContracts(transaction *DbTransaction, names []string) ([]RateLimit, error) {
+	limits := make([]RateLimit, 0)
+	err := GetDB(transaction).Table(rl.TableName()).
+		Where("ecosystem = ? AND deleted = 0 AND contract IN (?)", rl.ecosystem, names).
+		Order("id").Find(&limits).Error
+	if err != nil {
+		return nil, err
+	}
+	return limits, nil
+}
+
+// RateLimitUsage represents record of 1_rate_limit_usage table
+type RateLimitUsage struct {
+	ID        int64  `gorm:"primary_key;not null"`
+	Contract  string `gorm:"not null;size:255"`
+	KeyID     int64  `gorm:"not null"`
+	BlockID   int64  `gorm:"not null"`
+	Time      int64  `gorm:"not null"`
+	Ecosystem int64  `gorm:"not null"`
+}
+
+// TableName returns name of table
+func (RateLimitUsage) TableName() string {
+	return tableRateLimitUsage
+}
+
+// Create is creating record of model
+func (ru *RateLimitUsage) Create(transaction *DbTransaction) error {
+	var err error
+	if ru.ID, err = GetNextID(transaction, ru.TableName()); err != nil {
+		return err
+	}
+	return GetDB(transaction).Create(ru).Error
+}
+
+// Restore is inserting the deleted record of model with the same id
+func (ru *RateLimitUsage) Restore(transaction *DbTransaction) error {
+	return GetDB(transaction).Create(ru).Error
+}
+
+// GetExpiredRateLimitUsage returns the calls of the contract made by the key not later than the specified time
+func GetExpiredRateLimitUsage(transaction *DbTransaction, ecosystem, keyID int64, contract string, before int64) ([]RateLimitUsage, error) {
+	usage := make([]RateLimitUsage, 0)
+	err := GetDB(transaction).Table(tableRateLimitUsage).
+		Where("ecosystem = ? AND key_id = ? AND contract = ? AND time <= ?", ecosystem, keyID, contract, before).
+		Order("id").Find(&usage).Error
+	if err != nil {
+		return nil, err
+	}
+	return usage, nil
+}
+
+// DeleteRateLimitUsage is deleting the records with the specified ids
+func DeleteRateLimitUsage(transaction *DbTransaction, ids []int64) error {
+	return GetDB(transaction).Exec(`DELETE FROM "`+tableRateLimitUsage+`" WHERE id IN (?)`, ids).Error
+}
+
+// CountRateLimitUsage returns the number of calls of the contract made by the key since the specified time.
+// If contract equals RateLimitAnyContract then calls of all contracts are counted.
+func CountRateLimitUsage(transaction *DbTransaction, ecosystem, keyID int64, contract string, since int64) (int64, error) {
+	var count int64
+	query := GetDB(transaction).Table(tableRateLimitUsage).
+		Where("ecosystem = ? AND key_id = ? AND time > ?", ecosystem, keyID, since)
+	if contract != RateLimitAnyContract {
+		query = query.Where("contract = ?", contract)
+	}
+	err := query.Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,61 @@
+// Copyright (C) 2017, 2018, 2019 EGAAS S.A.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or (at
+// your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but
+// WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301, USA.
+
+package model
+
+import (
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+const uniqueViolation = `unique_violation`
+
+// ErrTokenRevoked is returned if the token is already in the revocation list
+var ErrTokenRevoked = errors.New("token is already revoked")
+
+// RevokedToken is the identifier of the revoked authorization token of API.
+// The table is shared by API nodes which use the same database
+type RevokedToken struct {
+	ID     string `gorm:"primary_key;not null"`
+	Expire int64  `gorm:"not null"`
+}
+
+// TableName returns name of table
+func (rt *RevokedToken) TableName() string {
+	return "revoked_tokens"
+}
+
+// Create is adding the token to the revocation list, ErrTokenRevoked is returned
+// if the token has been revoked already
+func (rt *RevokedToken) Create() error {
+	err := DBConn.Create(rt).Error
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == uniqueViolation {
+		return ErrTokenRevoked
+	}
+	return err
+}
+
+// IsTokenRevoked returns true if the token with the identifier has been revoked
+func IsTokenRevoked(id string) (bool, error) {
+	return isFound(DBConn.Where("id = ?", id).First(&RevokedToken{}))
+}
+
+// DeleteExpiredRevokedTokens deletes the tokens which are expired at the time,
+// the tokens without the expiration are kept
+func DeleteExpiredRevokedTokens(now int64) error {
+	return DBConn.Exec(`DELETE FROM "revoked_tokens" WHERE expire > 0 AND expire < ?`, now).Error
+}
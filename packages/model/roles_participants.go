@@ -66,6 +66,13 @@ func MemberHasRole(tx *DbTransaction, role, ecosys int64, account string) (bool,
 	return count > 0, nil
 }
 
+// CountRoleMembers returns the number of active members of the role
+func CountRoleMembers(tx *DbTransaction, role, ecosys int64) (count int64, err error) {
+	err = GetDB(tx).Table("1_roles_participants").Where(`ecosystem=? and role->>'id' = ? and deleted = 0`,
+		ecosys, converter.Int64ToStr(role)).Count(&count).Error
+	return
+}
+
 // GetMemberRoles return map[id]name all roles assign to member in ecosystem
 func GetMemberRoles(tx *DbTransaction, ecosys int64, account string) (roles []int64, err error) {
 	query := `SELECT role->>'id' as "id" 
@@ -43,6 +43,21 @@ func (rt *RollbackTx) GetBlockRollbackTransactions(dbTransaction *DbTransaction,
 	return rollbackTransactions, err
 }
 
+// GetBlockTables returns the names of tables which have been changed in the block
+func (rt *RollbackTx) GetBlockTables(dbTransaction *DbTransaction, blockID int64) ([]string, error) {
+	var tables []string
+	err := GetDB(dbTransaction).Model(rt).Where("block_id = ?", blockID).Pluck("DISTINCT table_name", &tables).Error
+	return tables, err
+}
+
+// GetBlockRows returns the names of tables and the identifiers of rows which have been changed in the block
+func (rt *RollbackTx) GetBlockRows(dbTransaction *DbTransaction, blockID int64) ([]RollbackTx, error) {
+	var rows []RollbackTx
+	err := GetDB(dbTransaction).Select("table_name, table_id").Where("block_id = ?", blockID).
+		Order("id asc").Find(&rows).Error
+	return rows, err
+}
+
 // GetRollbackTxsByTableIDAndTableName returns records of rollback by table name and id
 func (rt *RollbackTx) GetRollbackTxsByTableIDAndTableName(tableID, tableName string, limit int) (*[]RollbackTx, error) {
 	rollbackTx := new([]RollbackTx)
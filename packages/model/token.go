@@ -0,0 +1,121 @@
+// Copyright (C) 2017, 2018, 2019 EGAAS S.A.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or (at
+// your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but
+// WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301, USA.
+
+package model
+
+import (
+	"github.com/shopspring/decimal"
+)
+
+const (
+	tableTokens          = "1_tokens"
+	tableTokenBalances   = "1_token_balances"
+	tableTokenAllowances = "1_token_allowances"
+)
+
+// Token represents record of 1_tokens table
+type Token struct {
+	ecosystem   int64
+	ID          int64           `gorm:"primary_key;not null" json:"id"`
+	Symbol      string          `gorm:"not null;size:32" json:"symbol"`
+	Name        string          `gorm:"not null;size:255" json:"name"`
+	Decimals    int64           `gorm:"not null" json:"decimals"`
+	Owner       int64           `gorm:"not null" json:"owner"`
+	TotalSupply decimal.Decimal `gorm:"not null" json:"total_supply"`
+}
+
+// SetTablePrefix is setting table prefix
+func (t *Token) SetTablePrefix(prefix int64) *Token {
+	t.ecosystem = prefix
+	return t
+}
+
+// TableName returns name of table
+func (t *Token) TableName() string {
+	if t.ecosystem == 0 {
+		t.ecosystem = 1
+	}
+	return tableTokens
+}
+
+// Get is retrieving the token by id
+func (t *Token) Get(transaction *DbTransaction, id int64) (bool, error) {
+	return isFound(GetDB(transaction).Where("ecosystem = ? and id = ?", t.ecosystem, id).First(t))
+}
+
+// GetBySymbol is retrieving the token by symbol
+func (t *Token) GetBySymbol(transaction *DbTransaction, symbol string) (bool, error) {
+	return isFound(GetDB(transaction).Where("ecosystem = ? and symbol = ?", t.ecosystem, symbol).First(t))
+}
+
+// TokenBalance represents record of 1_token_balances table
+type TokenBalance struct {
+	ecosystem int64
+	ID        int64           `gorm:"primary_key;not null"`
+	TokenID   int64           `gorm:"not null"`
+	KeyID     int64           `gorm:"not null"`
+	Amount    decimal.Decimal `gorm:"not null"`
+}
+
+// SetTablePrefix is setting table prefix
+func (tb *TokenBalance) SetTablePrefix(prefix int64) *TokenBalance {
+	tb.ecosystem = prefix
+	return tb
+}
+
+// TableName returns name of table
+func (tb *TokenBalance) TableName() string {
+	if tb.ecosystem == 0 {
+		tb.ecosystem = 1
+	}
+	return tableTokenBalances
+}
+
+// Get is retrieving the balance of the key
+func (tb *TokenBalance) Get(transaction *DbTransaction, tokenID, keyID int64) (bool, error) {
+	return isFound(GetDB(transaction).Where("ecosystem = ? and token_id = ? and key_id = ?",
+		tb.ecosystem, tokenID, keyID).First(tb))
+}
+
+// TokenAllowance represents record of 1_token_allowances table
+type TokenAllowance struct {
+	ecosystem int64
+	ID        int64           `gorm:"primary_key;not null"`
+	TokenID   int64           `gorm:"not null"`
+	OwnerID   int64           `gorm:"not null"`
+	SpenderID int64           `gorm:"not null"`
+	Amount    decimal.Decimal `gorm:"not null"`
+}
+
+// SetTablePrefix is setting table prefix
+func (ta *TokenAllowance) SetTablePrefix(prefix int64) *TokenAllowance {
+	ta.ecosystem = prefix
+	return ta
+}
+
+// TableName returns name of table
+func (ta *TokenAllowance) TableName() string {
+	if ta.ecosystem == 0 {
+		ta.ecosystem = 1
+	}
+	return tableTokenAllowances
+}
+
+// Get is retrieving the amount which the spender is allowed to withdraw from the owner
+func (ta *TokenAllowance) Get(transaction *DbTransaction, tokenID, ownerID, spenderID int64) (bool, error) {
+	return isFound(GetDB(transaction).Where("ecosystem = ? and token_id = ? and owner_id = ? and spender_id = ?",
+		ta.ecosystem, tokenID, ownerID, spenderID).First(ta))
+}
@@ -0,0 +1,69 @@
+// Copyright (C) 2017, 2018, 2019 EGAAS S.A.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or (at
+// your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but
+// WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301, USA.
+
+package model
+
+// TxCallback is the url which is notified about the final status of the transaction
+type TxCallback struct {
+	Hash     []byte `gorm:"primary_key;not null"`
+	Url      string `gorm:"not null"`
+	Time     int64  `gorm:"not null"`
+	Attempts int64  `gorm:"not null"`
+	NextTime int64  `gorm:"not null"`
+}
+
+// TxCallbackStatus is the callback joined with the status of its transaction
+type TxCallbackStatus struct {
+	TxCallback
+	BlockID int64
+	Error   string
+}
+
+// TableName returns name of table
+func (tc *TxCallback) TableName() string {
+	return "tx_callbacks"
+}
+
+// Save is creating or replacing the callback of the transaction
+func (tc *TxCallback) Save() error {
+	return DBConn.Save(tc).Error
+}
+
+// GetReadyTxCallbacks returns callbacks of the processed transactions which can be sent at now
+func GetReadyTxCallbacks(now int64, limit int) (list []TxCallbackStatus, err error) {
+	err = DBConn.Raw(`SELECT c.hash, c.url, c.time, c.attempts, c.next_time, s.block_id, s.error
+		FROM "tx_callbacks" as c
+		INNER JOIN "transactions_status" as s ON s.hash = c.hash
+		WHERE c.next_time <= ? AND (s.block_id > 0 OR s.error <> '')
+		ORDER BY c.next_time LIMIT ?`, now, limit).Scan(&list).Error
+	return
+}
+
+// DeleteTxCallback deletes the callback of the transaction
+func DeleteTxCallback(hash []byte) error {
+	return DBConn.Exec(`DELETE FROM "tx_callbacks" WHERE hash = ?`, hash).Error
+}
+
+// DeleteExpiredTxCallbacks deletes callbacks which have been waiting for the status since the time
+func DeleteExpiredTxCallbacks(time int64) error {
+	return DBConn.Exec(`DELETE FROM "tx_callbacks" WHERE time < ?`, time).Error
+}
+
+// SetTxCallbackAttempt saves the number of failed attempts and the time of the next one
+func SetTxCallbackAttempt(hash []byte, attempts, nextTime int64) error {
+	return DBConn.Exec(`UPDATE "tx_callbacks" SET attempts = ?, next_time = ? WHERE hash = ?`,
+		attempts, nextTime, hash).Error
+}
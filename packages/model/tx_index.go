@@ -0,0 +1,97 @@
+// Copyright (C) 2017, 2018, 2019 EGAAS S.A.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or (at
+// your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but
+// WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301, USA.
+
+package model
+
+// TxIndex is the index of the transactions of the blocks by the sender and by the contract.
+// The records are created and deleted together with the blocks
+type TxIndex struct {
+	Hash      []byte `gorm:"primary_key;not null"`
+	BlockID   int64  `gorm:"not null"`
+	KeyID     int64  `gorm:"not null"`
+	Ecosystem int64  `gorm:"not null"`
+	Contract  string `gorm:"not null"`
+	Time      int64  `gorm:"not null"`
+}
+
+// TxIndexFilter is the conditions of the search in the index, zero values are ignored
+type TxIndexFilter struct {
+	KeyID     int64
+	Ecosystem int64
+	Contract  string
+	From      int64 // minimal time of the block
+	To        int64 // maximal time of the block
+}
+
+// TableName returns name of table
+func (ti *TxIndex) TableName() string {
+	return "tx_index"
+}
+
+// TxIndexStart is the first block of the index, the earlier blocks were played before the index appeared
+type TxIndexStart struct {
+	BlockID int64 `gorm:"not null"`
+}
+
+// TableName returns name of table
+func (ts *TxIndexStart) TableName() string {
+	return "tx_index_start"
+}
+
+// Get is retrieving model from database
+func (ts *TxIndexStart) Get() (bool, error) {
+	return isFound(DBConn.First(ts))
+}
+
+// Create is creating record of model
+func (ti *TxIndex) Create(transaction *DbTransaction) error {
+	return GetDB(transaction).Create(ti).Error
+}
+
+// DeleteTxIndexByHash is deleting the index of the transaction
+func DeleteTxIndexByHash(transaction *DbTransaction, hash []byte) error {
+	return GetDB(transaction).Exec(`DELETE FROM "tx_index" WHERE hash = ?`, hash).Error
+}
+
+// GetTxIndexList returns the indexed transactions matching the filter and the total count of them.
+// The latest transactions go first
+func GetTxIndexList(filter TxIndexFilter, offset, limit int64) ([]TxIndex, int64, error) {
+	var (
+		list  []TxIndex
+		count int64
+	)
+	query := DBConn.Table("tx_index")
+	if filter.KeyID != 0 {
+		query = query.Where("key_id = ?", filter.KeyID)
+	}
+	if filter.Ecosystem != 0 {
+		query = query.Where("ecosystem = ?", filter.Ecosystem)
+	}
+	if len(filter.Contract) > 0 {
+		query = query.Where("contract = ?", filter.Contract)
+	}
+	if filter.From != 0 {
+		query = query.Where("time >= ?", filter.From)
+	}
+	if filter.To != 0 {
+		query = query.Where("time <= ?", filter.To)
+	}
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Order("block_id desc, hash").Offset(offset).Limit(limit).Find(&list).Error
+	return list, count, err
+}
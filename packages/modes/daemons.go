@@ -41,6 +41,7 @@ func (f BlockchainDaemonsListsFactory) GetDaemonsList() []string {
 		"Confirmations",
 		"Scheduler",
 		"ExternalNetwork",
+		"TxCallbacks",
 	}
 }
 
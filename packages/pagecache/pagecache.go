@@ -0,0 +1,184 @@
+// Copyright (C) 2017, 2018, 2019 EGAAS S.A.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or (at
+// your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but
+// WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301, USA.
+
+package pagecache
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/AplaProject/go-apla/packages/conf"
+)
+
+const defaultSize = 1000
+
+// Key identifies the rendered page
+type Key struct {
+	Name      string
+	Ecosystem string
+	// KeyID is a part of the key because the read conditions of the tables and columns
+	// can give different results for the users with the same role
+	KeyID  string
+	Role   string
+	Lang   string
+	Params string
+}
+
+// Stats contains the statistics of the cache
+type Stats struct {
+	Enabled bool  `json:"enabled"`
+	Size    int   `json:"size"`
+	Hits    int64 `json:"hits"`
+	Misses  int64 `json:"misses"`
+}
+
+type entry struct {
+	key    Key
+	value  interface{}
+	tables []string
+}
+
+type pageCache struct {
+	sync.Mutex
+	items  map[Key]*list.Element
+	tables map[string]map[Key]bool
+	order  *list.List
+	hits   int64
+	misses int64
+	// generation is increased by every invalidation, the page rendered before it can be stale
+	generation uint64
+}
+
+var cache = newCache()
+
+func newCache() *pageCache {
+	return &pageCache{
+		items:  make(map[Key]*list.Element),
+		tables: make(map[string]map[Key]bool),
+		order:  list.New(),
+	}
+}
+
+// Enabled returns true if the cache of pages is turned on
+func Enabled() bool {
+	return conf.Config.PageCache.Enabled
+}
+
+func maxSize() int {
+	if conf.Config.PageCache.Size > 0 {
+		return conf.Config.PageCache.Size
+	}
+	return defaultSize
+}
+
+// Get returns the cached page
+func Get(key Key) (interface{}, bool) {
+	if !Enabled() {
+		return nil, false
+	}
+	cache.Lock()
+	defer cache.Unlock()
+	item, ok := cache.items[key]
+	if !ok {
+		cache.misses++
+		return nil, false
+	}
+	cache.hits++
+	cache.order.MoveToFront(item)
+	return item.Value.(*entry).value, true
+}
+
+// Generation returns the current generation of the cache. It must be taken before reading
+// the data of the page and passed to Set
+func Generation() uint64 {
+	cache.Lock()
+	defer cache.Unlock()
+	return cache.generation
+}
+
+// Set stores the page with the list of tables which have been read while rendering.
+// The page is not stored if the cache has been invalidated since the generation
+func Set(key Key, value interface{}, tables []string, generation uint64) {
+	if !Enabled() {
+		return
+	}
+	cache.Lock()
+	defer cache.Unlock()
+	if generation != cache.generation {
+		return
+	}
+	if item, ok := cache.items[key]; ok {
+		cache.remove(item)
+	}
+	for cache.order.Len() >= maxSize() {
+		cache.remove(cache.order.Back())
+	}
+	cache.items[key] = cache.order.PushFront(&entry{key: key, value: value, tables: tables})
+	for _, table := range tables {
+		if cache.tables[table] == nil {
+			cache.tables[table] = make(map[Key]bool)
+		}
+		cache.tables[table][key] = true
+	}
+}
+
+func (c *pageCache) remove(item *list.Element) {
+	e := item.Value.(*entry)
+	for _, table := range e.tables {
+		delete(c.tables[table], e.key)
+		if len(c.tables[table]) == 0 {
+			delete(c.tables, table)
+		}
+	}
+	delete(c.items, e.key)
+	c.order.Remove(item)
+}
+
+// Invalidate removes the pages which have read any of the tables
+func Invalidate(tables []string) {
+	cache.Lock()
+	defer cache.Unlock()
+	cache.generation++
+	for _, table := range tables {
+		for key := range cache.tables[table] {
+			if item, ok := cache.items[key]; ok {
+				cache.remove(item)
+			}
+		}
+	}
+}
+
+// Clear removes all pages from the cache
+func Clear() {
+	cache.Lock()
+	defer cache.Unlock()
+	cache.generation++
+	cache.items = make(map[Key]*list.Element)
+	cache.tables = make(map[string]map[Key]bool)
+	cache.order.Init()
+}
+
+// GetStats returns the statistics of the cache
+func GetStats() Stats {
+	cache.Lock()
+	defer cache.Unlock()
+	return Stats{
+		Enabled: Enabled(),
+		Size:    cache.order.Len(),
+		Hits:    cache.hits,
+		Misses:  cache.misses,
+	}
+}
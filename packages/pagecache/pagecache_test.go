@@ -0,0 +1,79 @@
+// Copyright (C) 2017, 2018, 2019 EGAAS S.A.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or (at
+// your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but
+// WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301, USA.
+
+package pagecache
+
+import (
+	"testing"
+
+	"github.com/AplaProject/go-apla/packages/conf"
+)
+
+func TestPageCache(t *testing.T) {
+	conf.Config.PageCache = conf.PageCacheConfig{Enabled: true, Size: 2}
+	defer func() {
+		conf.Config.PageCache = conf.PageCacheConfig{}
+		cache = newCache()
+	}()
+
+	first := Key{Name: `first`, Ecosystem: `1`}
+	second := Key{Name: `second`, Ecosystem: `1`}
+	third := Key{Name: `first`, Ecosystem: `1`, Lang: `ru`}
+
+	if _, ok := Get(first); ok {
+		t.Error(`empty cache returns page`)
+	}
+	Set(first, `first page`, []string{`1_pages`, `1_mytable`}, Generation())
+	Set(second, `second page`, []string{`1_pages`}, Generation())
+	if val, ok := Get(first); !ok || val.(string) != `first page` {
+		t.Errorf(`wrong cached page %v`, val)
+	}
+
+	Invalidate([]string{`1_mytable`})
+	if _, ok := Get(first); ok {
+		t.Error(`page has not been invalidated`)
+	}
+	if _, ok := Get(second); !ok {
+		t.Error(`page has been invalidated by another table`)
+	}
+
+	Set(first, `first page`, []string{`1_pages`}, Generation())
+	Set(third, `third page`, []string{`1_pages`}, Generation())
+	if _, ok := Get(second); ok {
+		t.Error(`the least recently used page has not been removed`)
+	}
+	stats := GetStats()
+	if stats.Size != 2 || stats.Hits != 2 || stats.Misses != 3 {
+		t.Errorf(`wrong stats %+v`, stats)
+	}
+
+	Invalidate([]string{`1_pages`})
+	if stats = GetStats(); stats.Size != 0 || len(cache.tables) != 0 {
+		t.Errorf(`cache is not empty %+v`, stats)
+	}
+
+	Set(Key{Name: `first`, Ecosystem: `1`, KeyID: `100`}, `first page`, []string{`1_pages`}, Generation())
+	if _, ok := Get(Key{Name: `first`, Ecosystem: `1`, KeyID: `200`}); ok {
+		t.Error(`page of another user has been returned`)
+	}
+
+	gen := Generation()
+	Invalidate([]string{`1_mytable`})
+	Set(second, `stale page`, []string{`1_mytable`}, gen)
+	if _, ok := Get(second); ok {
+		t.Error(`page rendered before the invalidation has been stored`)
+	}
+}
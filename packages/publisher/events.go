@@ -0,0 +1,99 @@
+// Copyright (C) 2017, 2018, 2019 EGAAS S.A.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or (at
+// your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but
+// WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301, USA.
+
+package publisher
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+
+	"github.com/AplaProject/go-apla/packages/consts"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ChannelBlocks is the channel of the new blocks
+const ChannelBlocks = "blocks"
+
+// BlockEvent is published when the block has been added to the blockchain
+type BlockEvent struct {
+	ID           int64  `json:"id"`
+	Hash         string `json:"hash"`
+	Time         int64  `json:"time"`
+	KeyID        int64  `json:"key_id"`
+	NodePosition int64  `json:"node_position"`
+	TxCount      int    `json:"tx_count"`
+}
+
+// TxStatusEvent is published when the transaction has been processed
+type TxStatusEvent struct {
+	Hash    string `json:"hash"`
+	BlockID int64  `json:"block_id"`
+	Error   string `json:"error,omitempty"`
+}
+
+// RowsEvent is published when the rows of the table have been changed by the block
+type RowsEvent struct {
+	Table   string   `json:"table"`
+	BlockID int64    `json:"block_id"`
+	IDs     []string `json:"ids"`
+}
+
+// ClientChannel returns the channel of the notifications of the user
+func ClientChannel(userID int64) string {
+	return "client" + strconv.FormatInt(userID, 10)
+}
+
+// TxChannel returns the channel of the statuses of the user transactions
+func TxChannel(userID int64) string {
+	return "transactions" + strconv.FormatInt(userID, 10)
+}
+
+// TableChannel returns the channel of the changes of the table
+func TableChannel(table string) string {
+	return "table." + table
+}
+
+func publishJSON(channel string, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.JSONMarshallError, "error": err}).Error("marshalling event")
+		return
+	}
+	if err = Publish(channel, data); err != nil {
+		log.WithFields(log.Fields{"type": consts.IOError, "error": err, "channel": channel}).Error("publishing event")
+	}
+}
+
+// PublishBlock sends the information about the new block
+func PublishBlock(event BlockEvent) {
+	publishJSON(ChannelBlocks, event)
+}
+
+// PublishTxStatus sends the status of the transaction to its sender
+func PublishTxStatus(userID int64, hash []byte, blockID int64, errText string) {
+	publishJSON(TxChannel(userID), TxStatusEvent{
+		Hash:    hex.EncodeToString(hash),
+		BlockID: blockID,
+		Error:   errText,
+	})
+}
+
+// PublishRows sends the identifiers of the changed rows of the table
+func PublishRows(event RowsEvent) {
+	publishJSON(TableChannel(event.Table), event)
+}
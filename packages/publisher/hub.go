@@ -0,0 +1,143 @@
+// Copyright (C) 2017, 2018, 2019 EGAAS S.A.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or (at
+// your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but
+// WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301, USA.
+
+package publisher
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/AplaProject/go-apla/packages/consts"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const subscriberBuffer = 64
+
+// Hub is the backend which delivers the messages to the subscribers connected to the node
+type Hub struct {
+	sync.RWMutex
+	channels map[string]map[*Subscriber]bool
+}
+
+// Subscriber receives the messages of the channels it is subscribed to
+type Subscriber struct {
+	hub      *Hub
+	send     chan []byte
+	channels map[string]bool
+	closed   bool
+}
+
+type hubMessage struct {
+	Channel string          `json:"channel"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// NewHub returns the new hub
+func NewHub() *Hub {
+	return &Hub{channels: make(map[string]map[*Subscriber]bool)}
+}
+
+// NewSubscriber returns the subscriber of the hub
+func (h *Hub) NewSubscriber() *Subscriber {
+	return &Subscriber{
+		hub:      h,
+		send:     make(chan []byte, subscriberBuffer),
+		channels: make(map[string]bool),
+	}
+}
+
+// HasSubscribers returns true if the channel has subscribers
+func (h *Hub) HasSubscribers(channel string) bool {
+	h.RLock()
+	defer h.RUnlock()
+	return len(h.channels[channel]) > 0
+}
+
+// Publish sends the data to the subscribers of the channel. The message is dropped for
+// the subscribers which don't read the messages in time
+func (h *Hub) Publish(channel string, data []byte) error {
+	h.RLock()
+	defer h.RUnlock()
+	subs := h.channels[channel]
+	if len(subs) == 0 {
+		return nil
+	}
+	msg, err := json.Marshal(hubMessage{Channel: channel, Data: data})
+	if err != nil {
+		return err
+	}
+	for sub := range subs {
+		select {
+		case sub.send <- msg:
+		default:
+			log.WithFields(log.Fields{"type": consts.WebSocketError, "channel": channel}).Warning("subscriber queue is full")
+		}
+	}
+	return nil
+}
+
+// Messages returns the queue of the messages. It is closed when the subscriber is closed
+func (s *Subscriber) Messages() <-chan []byte {
+	return s.send
+}
+
+// Subscribe adds the subscription to the channel
+func (s *Subscriber) Subscribe(channel string) {
+	s.hub.Lock()
+	defer s.hub.Unlock()
+	if s.closed {
+		return
+	}
+	subs := s.hub.channels[channel]
+	if subs == nil {
+		subs = make(map[*Subscriber]bool)
+		s.hub.channels[channel] = subs
+	}
+	subs[s] = true
+	s.channels[channel] = true
+}
+
+// Unsubscribe removes the subscription to the channel
+func (s *Subscriber) Unsubscribe(channel string) {
+	s.hub.Lock()
+	defer s.hub.Unlock()
+	s.unsubscribe(channel)
+}
+
+func (s *Subscriber) unsubscribe(channel string) {
+	if subs := s.hub.channels[channel]; subs != nil {
+		delete(subs, s)
+		if len(subs) == 0 {
+			delete(s.hub.channels, channel)
+		}
+	}
+	delete(s.channels, channel)
+}
+
+// Close removes all subscriptions and closes the queue of the messages
+func (s *Subscriber) Close() {
+	s.hub.Lock()
+	defer s.hub.Unlock()
+	if s.closed {
+		return
+	}
+	for channel := range s.channels {
+		s.unsubscribe(channel)
+	}
+	s.closed = true
+	close(s.send)
+}
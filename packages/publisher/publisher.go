@@ -47,17 +47,61 @@ func (cn *ClientsChannels) Get(id int64) string {
 	return cn.storage[id]
 }
 
+// Backend delivers the published messages to the subscribers of the channels
+type Backend interface {
+	Publish(channel string, data []byte) error
+}
+
+type centrifugoBackend struct {
+	client *gocent.Client
+}
+
+func (cb centrifugoBackend) Publish(channel string, data []byte) error {
+	ok, err := cb.client.Publish(channel, data)
+	if err == nil && !ok {
+		err = fmt.Errorf("centrifugo has not published to %s", channel)
+	}
+	return err
+}
+
 var (
 	clientsChannels   = ClientsChannels{storage: make(map[int64]string)}
 	centrifugoTimeout = time.Second * 5
 	publisher         *gocent.Client
 	config            conf.CentrifugoConfig
+
+	hub      = NewHub()
+	backends = []Backend{hub}
 )
 
-// InitCentrifugo client
+// InitCentrifugo client. Centrifugo is used as the additional backend if its URL is specified
 func InitCentrifugo(cfg conf.CentrifugoConfig) {
 	config = cfg
+	if len(cfg.URL) == 0 {
+		return
+	}
 	publisher = gocent.NewClient(cfg.URL, cfg.Secret, centrifugoTimeout)
+	AddBackend(centrifugoBackend{client: publisher})
+}
+
+// AddBackend adds the backend which receives all published messages
+func AddBackend(backend Backend) {
+	backends = append(backends, backend)
+}
+
+// NewSubscriber returns the subscriber of the built-in websocket hub
+func NewSubscriber() *Subscriber {
+	return hub.NewSubscriber()
+}
+
+// Publish sends the data to the channel through all backends
+func Publish(channel string, data []byte) (err error) {
+	for _, backend := range backends {
+		if errPublish := backend.Publish(channel, data); errPublish != nil {
+			err = errPublish
+		}
+	}
+	return
 }
 
 func GetHMACSign(userID int64) (string, string, error) {
@@ -74,9 +118,10 @@ func GetHMACSign(userID int64) (string, string, error) {
 	return result, timestamp, nil
 }
 
-// Write is publishing data to server
+// Write is publishing data to the channel of the user
 func Write(userID int64, data string) (bool, error) {
-	return publisher.Publish("client"+strconv.FormatInt(userID, 10), []byte(data))
+	err := Publish(ClientChannel(userID), []byte(data))
+	return err == nil, err
 }
 
 // GetStats returns Stats
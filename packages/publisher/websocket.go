@@ -0,0 +1,236 @@
+// Copyright (C) 2017, 2018, 2019 EGAAS S.A.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or (at
+// your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but
+// WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301, USA.
+
+package publisher
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WebSocket opcodes and close codes defined by RFC 6455
+const (
+	OpText   = 1
+	OpBinary = 2
+	OpClose  = 8
+	OpPing   = 9
+	OpPong   = 10
+
+	CloseNormal   = 1000
+	CloseProtocol = 1002
+	CloseTooBig   = 1009
+
+	wsGUID          = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+	wsMaxMessage    = 64 << 10
+	wsWriteDeadline = 10 * time.Second
+)
+
+var (
+	errWSHandshake = errors.New(`wrong websocket handshake`)
+	errWSProtocol  = errors.New(`websocket protocol error`)
+	errWSTooBig    = errors.New(`websocket message is too big`)
+)
+
+// Conn is the server side of the websocket connection
+type Conn struct {
+	conn net.Conn
+	rd   *bufio.Reader
+	wmu  sync.Mutex
+}
+
+func headerContains(header http.Header, name, value string) bool {
+	for _, item := range header[http.CanonicalHeaderKey(name)] {
+		for _, token := range strings.Split(item, `,`) {
+			if strings.EqualFold(strings.TrimSpace(token), value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// IsWebSocket returns true if the request is the websocket handshake
+func IsWebSocket(r *http.Request) bool {
+	return headerContains(r.Header, `Connection`, `upgrade`) && headerContains(r.Header, `Upgrade`, `websocket`)
+}
+
+// Upgrade switches the connection of the request to the websocket protocol
+func Upgrade(w http.ResponseWriter, r *http.Request) (*Conn, error) {
+	key := r.Header.Get(`Sec-WebSocket-Key`)
+	if r.Method != http.MethodGet || !IsWebSocket(r) || len(key) == 0 ||
+		r.Header.Get(`Sec-WebSocket-Version`) != `13` {
+		return nil, errWSHandshake
+	}
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		return nil, errWSHandshake
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, err
+	}
+	hash := sha1.Sum([]byte(key + wsGUID))
+	response := "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + base64.StdEncoding.EncodeToString(hash[:]) + "\r\n\r\n"
+	conn.SetWriteDeadline(time.Now().Add(wsWriteDeadline))
+	if _, err = conn.Write([]byte(response)); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &Conn{conn: conn, rd: rw.Reader}, nil
+}
+
+// WriteMessage sends the message as a single frame
+func (c *Conn) WriteMessage(opcode int, data []byte) error {
+	header := make([]byte, 2, 10)
+	header[0] = 0x80 | byte(opcode)
+	switch {
+	case len(data) < 126:
+		header[1] = byte(len(data))
+	case len(data) <= 0xffff:
+		header[1] = 126
+		header = append(header, 0, 0)
+		binary.BigEndian.PutUint16(header[2:], uint16(len(data)))
+	default:
+		header[1] = 127
+		header = append(header, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(header[2:], uint64(len(data)))
+	}
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	c.conn.SetWriteDeadline(time.Now().Add(wsWriteDeadline))
+	if _, err := c.conn.Write(append(header, data...)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Close sends the close frame and closes the connection
+func (c *Conn) Close(code int) error {
+	data := make([]byte, 2)
+	binary.BigEndian.PutUint16(data, uint16(code))
+	c.WriteMessage(OpClose, data)
+	return c.conn.Close()
+}
+
+func (c *Conn) readFrame() (fin bool, opcode int, data []byte, err error) {
+	var head [2]byte
+	if _, err = io.ReadFull(c.rd, head[:]); err != nil {
+		return
+	}
+	fin = head[0]&0x80 != 0
+	opcode = int(head[0] & 0x0f)
+	if head[0]&0x70 != 0 || head[1]&0x80 == 0 {
+		// reserved bits are not negotiated and the client frames must be masked
+		err = errWSProtocol
+		return
+	}
+	size := uint64(head[1] & 0x7f)
+	switch size {
+	case 126:
+		var ext [2]byte
+		if _, err = io.ReadFull(c.rd, ext[:]); err != nil {
+			return
+		}
+		size = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err = io.ReadFull(c.rd, ext[:]); err != nil {
+			return
+		}
+		size = binary.BigEndian.Uint64(ext[:])
+	}
+	if size > wsMaxMessage {
+		err = errWSTooBig
+		return
+	}
+	var mask [4]byte
+	if _, err = io.ReadFull(c.rd, mask[:]); err != nil {
+		return
+	}
+	data = make([]byte, size)
+	if _, err = io.ReadFull(c.rd, data); err != nil {
+		return
+	}
+	for i := range data {
+		data[i] ^= mask[i%4]
+	}
+	return
+}
+
+// ReadMessage returns the next text or binary message. Control frames are processed
+// internally, io.EOF is returned when the client has closed the connection
+func (c *Conn) ReadMessage() (int, []byte, error) {
+	var (
+		opcode  int
+		message []byte
+	)
+	for {
+		fin, op, data, err := c.readFrame()
+		if err != nil {
+			switch err {
+			case errWSProtocol:
+				c.Close(CloseProtocol)
+			case errWSTooBig:
+				c.Close(CloseTooBig)
+			}
+			return 0, nil, err
+		}
+		switch op {
+		case OpClose:
+			c.Close(CloseNormal)
+			return 0, nil, io.EOF
+		case OpPing:
+			if err = c.WriteMessage(OpPong, data); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case OpPong:
+			continue
+		case 0:
+			if opcode == 0 {
+				c.Close(CloseProtocol)
+				return 0, nil, errWSProtocol
+			}
+		case OpText, OpBinary:
+			if opcode != 0 {
+				c.Close(CloseProtocol)
+				return 0, nil, errWSProtocol
+			}
+			opcode = op
+		default:
+			c.Close(CloseProtocol)
+			return 0, nil, errWSProtocol
+		}
+		if len(message)+len(data) > wsMaxMessage {
+			c.Close(CloseTooBig)
+			return 0, nil, errWSTooBig
+		}
+		message = append(message, data...)
+		if fin {
+			return opcode, message, nil
+		}
+	}
+}
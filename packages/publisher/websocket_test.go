@@ -0,0 +1,152 @@
+// Copyright (C) 2017, 2018, 2019 EGAAS S.A.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or (at
+// your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but
+// WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301, USA.
+
+package publisher
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type testClient struct {
+	conn net.Conn
+	rd   *bufio.Reader
+}
+
+func dialTest(t *testing.T, url string) *testClient {
+	conn, err := net.Dial("tcp", strings.TrimPrefix(url, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Write([]byte("GET / HTTP/1.1\r\nHost: test\r\nConnection: keep-alive, Upgrade\r\nUpgrade: websocket\r\n" +
+		"Sec-WebSocket-Version: 13\r\nSec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n\r\n"))
+	rd := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(rd, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols ||
+		resp.Header.Get("Sec-WebSocket-Accept") != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatalf("wrong handshake %v", resp)
+	}
+	return &testClient{conn: conn, rd: rd}
+}
+
+func (c *testClient) write(opcode int, fin bool, data string) {
+	head := []byte{byte(opcode), 0x80 | byte(len(data))}
+	if fin {
+		head[0] |= 0x80
+	}
+	mask := []byte{1, 2, 3, 4}
+	payload := []byte(data)
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	c.conn.Write(append(append(head, mask...), payload...))
+}
+
+func (c *testClient) read(t *testing.T) (int, string) {
+	var head [2]byte
+	if _, err := io.ReadFull(c.rd, head[:]); err != nil {
+		t.Fatal(err)
+	}
+	size := int(head[1] & 0x7f)
+	if size == 126 {
+		var ext [2]byte
+		io.ReadFull(c.rd, ext[:])
+		size = int(binary.BigEndian.Uint16(ext[:]))
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(c.rd, data); err != nil {
+		t.Fatal(err)
+	}
+	return int(head[0] & 0x0f), string(data)
+}
+
+func TestWebSocket(t *testing.T) {
+	h := NewHub()
+	subscribed := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := Upgrade(w, r)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		sub := h.NewSubscriber()
+		defer sub.Close()
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		sub.Subscribe(string(data))
+		close(subscribed)
+		conn.WriteMessage(OpText, <-sub.Messages())
+		if _, _, err = conn.ReadMessage(); err != io.EOF {
+			t.Errorf("wrong error %v", err)
+		}
+	}))
+	defer srv.Close()
+
+	client := dialTest(t, srv.URL)
+	client.write(OpPing, true, "hi")
+	client.write(OpText, false, "blo")
+	client.write(0, true, "cks")
+	if op, data := client.read(t); op != OpPong || data != "hi" {
+		t.Errorf("wrong pong %d %s", op, data)
+	}
+	<-subscribed
+	if !h.HasSubscribers("blocks") {
+		t.Fatal("subscription has not been added")
+	}
+	h.Publish("other", []byte(`{"id":1}`))
+	h.Publish("blocks", []byte(`{"id":2}`))
+	if op, data := client.read(t); op != OpText || data != `{"channel":"blocks","data":{"id":2}}` {
+		t.Errorf("wrong message %d %s", op, data)
+	}
+	client.write(OpClose, true, "")
+	if op, _ := client.read(t); op != OpClose {
+		t.Errorf("wrong close %d", op)
+	}
+}
+
+func TestHub(t *testing.T) {
+	h := NewHub()
+	sub := h.NewSubscriber()
+	sub.Subscribe("a")
+	sub.Subscribe("b")
+	sub.Unsubscribe("a")
+	for i := 0; i < subscriberBuffer+1; i++ {
+		h.Publish("b", []byte(`1`))
+	}
+	if len(sub.Messages()) != subscriberBuffer {
+		t.Errorf("wrong queue length %d", len(sub.Messages()))
+	}
+	sub.Close()
+	if h.HasSubscribers("a") || h.HasSubscribers("b") {
+		t.Error("subscriptions have not been removed")
+	}
+	sub.Subscribe("a")
+	if h.HasSubscribers("a") {
+		t.Error("closed subscriber has been subscribed")
+	}
+}
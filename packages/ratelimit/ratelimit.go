@@ -0,0 +1,176 @@
+// Copyright (C) 2017, 2018, 2019 EGAAS S.A.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or (at
+// your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but
+// WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301, USA.
+
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AplaProject/go-apla/packages/conf"
+)
+
+const (
+	// cleanPeriod is the period of removing the buckets of inactive clients
+	cleanPeriod = time.Minute
+	// IPGroup is the group of the statistics for the requests rejected by the limit of the address
+	IPGroup = `ip`
+)
+
+// Stats contains the statistics of the limiter
+type Stats struct {
+	Enabled  bool             `json:"enabled"`
+	Clients  int              `json:"clients"`
+	Rejected int64            `json:"rejected"`
+	Groups   map[string]int64 `json:"groups"`
+}
+
+// bucket is the token bucket of the client, one token is taken by every request
+type bucket struct {
+	tokens float64
+	rate   float64
+	burst  float64
+	last   time.Time
+}
+
+func (b *bucket) refill(now time.Time) {
+	b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+}
+
+type limiter struct {
+	sync.Mutex
+	buckets   map[string]*bucket
+	rejected  map[string]int64
+	lastClean time.Time
+	now       func() time.Time
+}
+
+var limits = newLimiter()
+
+func newLimiter() *limiter {
+	return &limiter{
+		buckets:  make(map[string]*bucket),
+		rejected: make(map[string]int64),
+		now:      time.Now,
+	}
+}
+
+// Enabled returns true if the limiter of requests is turned on
+func Enabled() bool {
+	return conf.Config.RateLimit.Enabled
+}
+
+// getLimit returns the limit of the route group, the default limit is used if the group isn't specified
+func getLimit(group string) conf.RouteLimit {
+	cfg := conf.Config.RateLimit
+	limit := conf.RouteLimit{Rate: cfg.Rate, Burst: cfg.Burst}
+	for name, item := range cfg.Routes {
+		// the keys of the config are case insensitive
+		if strings.EqualFold(name, group) {
+			limit = item
+			break
+		}
+	}
+	if limit.Burst <= 0 {
+		limit.Burst = 1
+	}
+	return limit
+}
+
+// Allow takes the token of the client for the request of the route group.
+// If the request is rejected then it returns the time after which the request can be repeated.
+// Zero rate of the group means that requests are not limited
+func Allow(ip string, keyID int64, group string) (bool, time.Duration) {
+	if !Enabled() {
+		return true, 0
+	}
+	limit := getLimit(group)
+	if limit.Rate <= 0 {
+		return true, 0
+	}
+	return limits.allow(fmt.Sprintf("%s|%d|%s", ip, keyID, strings.ToLower(group)), group, limit)
+}
+
+// AllowIP takes the token of the address for the request. It is checked before the authorization
+// so that the requests with fake tokens don't reach the database. Zero rate means that requests are not limited
+func AllowIP(ip string) (bool, time.Duration) {
+	if !Enabled() {
+		return true, 0
+	}
+	limit := conf.Config.RateLimit.IP
+	if limit.Rate <= 0 {
+		return true, 0
+	}
+	if limit.Burst <= 0 {
+		limit.Burst = 1
+	}
+	return limits.allow(IPGroup+`|`+ip, IPGroup, limit)
+}
+
+func (l *limiter) allow(key, group string, limit conf.RouteLimit) (bool, time.Duration) {
+	l.Lock()
+	defer l.Unlock()
+
+	now := l.now()
+	l.clean(now)
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), last: now}
+		l.buckets[key] = b
+	}
+	b.rate, b.burst = limit.Rate, float64(limit.Burst)
+	b.refill(now)
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	l.rejected[group]++
+	return false, time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+}
+
+// clean removes the full buckets because they are the same as the new ones
+func (l *limiter) clean(now time.Time) {
+	if now.Sub(l.lastClean) < cleanPeriod {
+		return
+	}
+	l.lastClean = now
+	for key, b := range l.buckets {
+		b.refill(now)
+		if b.tokens >= b.burst {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// GetStats returns the statistics of the limiter
+func GetStats() Stats {
+	limits.Lock()
+	defer limits.Unlock()
+	stats := Stats{
+		Enabled: Enabled(),
+		Clients: len(limits.buckets),
+		Groups:  make(map[string]int64, len(limits.rejected)),
+	}
+	for group, count := range limits.rejected {
+		stats.Groups[group] = count
+		stats.Rejected += count
+	}
+	return stats
+}
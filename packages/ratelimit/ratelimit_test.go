@@ -0,0 +1,115 @@
+// Copyright (C) 2017, 2018, 2019 EGAAS S.A.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or (at
+// your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but
+// WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301, USA.
+
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AplaProject/go-apla/packages/conf"
+)
+
+func TestRateLimit(t *testing.T) {
+	conf.Config.RateLimit = conf.RateLimitConfig{Enabled: true, Rate: 1, Burst: 2,
+		Routes: map[string]conf.RouteLimit{
+			`sendtx`:  {Rate: 0.5, Burst: 1},
+			`metrics`: {Rate: 0},
+		}}
+	now := time.Unix(1000, 0)
+	limits = newLimiter()
+	limits.now = func() time.Time {
+		return now
+	}
+	defer func() {
+		conf.Config.RateLimit = conf.RateLimitConfig{}
+		limits = newLimiter()
+	}()
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := Allow(`127.0.0.1`, 1, `list`); !ok {
+			t.Errorf(`request %d has been rejected`, i)
+		}
+	}
+	if ok, retry := Allow(`127.0.0.1`, 1, `list`); ok || retry != time.Second {
+		t.Errorf(`wrong limit of burst %v %v`, ok, retry)
+	}
+	if ok, _ := Allow(`127.0.0.1`, 2, `list`); !ok {
+		t.Error(`another key has been rejected`)
+	}
+	if ok, _ := Allow(`127.0.0.2`, 1, `list`); !ok {
+		t.Error(`another ip has been rejected`)
+	}
+
+	if ok, _ := Allow(`127.0.0.1`, 1, `sendTx`); !ok {
+		t.Error(`another group has been rejected`)
+	}
+	if ok, retry := Allow(`127.0.0.1`, 1, `sendTx`); ok || retry != 2*time.Second {
+		t.Errorf(`wrong limit of group %v %v`, ok, retry)
+	}
+	for i := 0; i < 10; i++ {
+		if ok, _ := Allow(`127.0.0.1`, 1, `metrics`); !ok {
+			t.Error(`unlimited group has been rejected`)
+		}
+	}
+
+	now = now.Add(time.Second)
+	if ok, _ := Allow(`127.0.0.1`, 1, `list`); !ok {
+		t.Error(`token has not been refilled`)
+	}
+
+	stats := GetStats()
+	if stats.Rejected != 2 || stats.Groups[`list`] != 1 || stats.Groups[`sendTx`] != 1 {
+		t.Errorf(`wrong stats %+v`, stats)
+	}
+	now = now.Add(time.Hour)
+	Allow(`127.0.0.1`, 1, `list`)
+	if stats = GetStats(); stats.Clients != 1 {
+		t.Errorf(`inactive clients have not been removed %+v`, stats)
+	}
+}
+
+func TestRateLimitIP(t *testing.T) {
+	conf.Config.RateLimit = conf.RateLimitConfig{Enabled: true, Rate: 1, Burst: 1,
+		IP: conf.RouteLimit{Rate: 1, Burst: 2}}
+	now := time.Unix(1000, 0)
+	limits = newLimiter()
+	limits.now = func() time.Time {
+		return now
+	}
+	defer func() {
+		conf.Config.RateLimit = conf.RateLimitConfig{}
+		limits = newLimiter()
+	}()
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := AllowIP(`127.0.0.1`); !ok {
+			t.Errorf(`request %d has been rejected`, i)
+		}
+	}
+	if ok, retry := AllowIP(`127.0.0.1`); ok || retry != time.Second {
+		t.Errorf(`wrong limit of address %v %v`, ok, retry)
+	}
+	if ok, _ := Allow(`127.0.0.1`, 1, `list`); !ok {
+		t.Error(`limit of address is shared with the client`)
+	}
+	if ok, _ := AllowIP(`127.0.0.2`); !ok {
+		t.Error(`another ip has been rejected`)
+	}
+	if stats := GetStats(); stats.Groups[IPGroup] != 1 {
+		t.Errorf(`wrong stats %+v`, stats)
+	}
+}
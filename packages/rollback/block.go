@@ -24,6 +24,7 @@ import (
 	"github.com/AplaProject/go-apla/packages/block"
 	"github.com/AplaProject/go-apla/packages/consts"
 	"github.com/AplaProject/go-apla/packages/model"
+	"github.com/AplaProject/go-apla/packages/pagecache"
 	"github.com/AplaProject/go-apla/packages/transaction"
 	"github.com/AplaProject/go-apla/packages/utils"
 
@@ -95,7 +96,11 @@ func RollbackBlock(data []byte) error {
 		return err
 	}
 
-	return dbTransaction.Commit()
+	if err = dbTransaction.Commit(); err != nil {
+		return err
+	}
+	pagecache.Clear()
+	return nil
 }
 
 func rollbackBlock(dbTransaction *model.DbTransaction, block *block.Block) error {
@@ -115,6 +120,10 @@ func rollbackBlock(dbTransaction *model.DbTransaction, block *block.Block) error
 			logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("deleting log transactions by hash")
 			return err
 		}
+		if err = model.DeleteTxIndexByHash(dbTransaction, t.TxHash); err != nil {
+			logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("deleting transaction index by hash")
+			return err
+		}
 
 		ts := &model.TransactionStatus{}
 		err = ts.UpdateBlockID(dbTransaction, 0, t.TxHash)
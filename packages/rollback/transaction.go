@@ -95,6 +95,10 @@ func rollbackTransaction(txHash []byte, dbTransaction *model.DbTransaction, logg
 				smart.SysRollbackDeleteColumn(dbTransaction, sysData)
 			case "DeleteTable":
 				smart.SysRollbackDeleteTable(dbTransaction, sysData)
+			case "DeleteRateLimitUsage":
+				if err := smart.SysRollbackDeleteRateLimitUsage(dbTransaction, sysData); err != nil {
+					return err
+				}
 			}
 			continue
 		}
@@ -0,0 +1,125 @@
+// Copyright (C) 2017, 2018, 2019 EGAAS S.A.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or (at
+// your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but
+// WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301, USA.
+
+package smart
+
+import (
+	"encoding/hex"
+	"strings"
+
+	"github.com/AplaProject/go-apla/packages/consts"
+	"github.com/AplaProject/go-apla/packages/converter"
+	"github.com/AplaProject/go-apla/packages/model"
+)
+
+const apiKeyHashSize = 64
+
+func checkAPIKeyRoutes(routes string) error {
+	for _, route := range strings.Split(routes, `,`) {
+		route = strings.TrimSpace(route)
+		if route != model.APIKeyAnyRoute && !strings.HasPrefix(route, `/`) {
+			return logErrorfShort(eAPIKeyRoute, route, consts.InvalidObject)
+		}
+	}
+	return nil
+}
+
+// APIKeyCreate issues the API key which gives the access to the routes of API on behalf of the account
+// with the specified role. Only the hash of the key is stored, the key is generated by the issuer.
+// Routes are the comma separated list of route templates, * at the end of the route allows
+// all routes with this prefix. Zero expire means the key doesn't expire
+func APIKeyCreate(sc *SmartContract, name, keyHash string, keyID, roleID int64, routes string,
+	expire int64) (int64, error) {
+	if sc.OBS {
+		return 0, ErrNotImplementedOnOBS
+	}
+	if err := validateAccess(`APIKeyCreate`, sc, nNewAPIKey); err != nil {
+		return 0, err
+	}
+	name = strings.TrimSpace(name)
+	if len(name) == 0 || len(name) > 255 {
+		return 0, errAPIKeyName
+	}
+	keyHash = strings.ToLower(keyHash)
+	if _, err := hex.DecodeString(keyHash); err != nil || len(keyHash) != apiKeyHashSize {
+		return 0, errAPIKeyHash
+	}
+	if err := checkAPIKeyRoutes(routes); err != nil {
+		return 0, err
+	}
+	if expire < 0 || (expire > 0 && expire <= sc.BlockData.Time) {
+		return 0, errAPIKeyExpire
+	}
+	ecosystemID := sc.TxSmart.EcosystemID
+	key := &model.Key{}
+	key.SetTablePrefix(ecosystemID)
+	found, err := key.GetTransaction(sc.DbTransaction, keyID)
+	if err != nil {
+		return 0, logErrorDB(err, "getting key")
+	}
+	if !found || key.Deleted == 1 {
+		return 0, errAPIKeyAccount
+	}
+	if roleID != 0 {
+		ok, err := model.MemberHasRole(sc.DbTransaction, roleID, ecosystemID, converter.AddressToString(keyID))
+		if err != nil {
+			return 0, logErrorDB(err, "checking role of member")
+		}
+		if !ok {
+			return 0, errAPIKeyRole
+		}
+	}
+	apiKey := &model.APIKey{}
+	if found, err = apiKey.GetByHash(sc.DbTransaction, keyHash); err != nil {
+		return 0, logErrorDB(err, "getting api key")
+	}
+	if found {
+		return 0, errAPIKeyExists
+	}
+	apiKey.SetTablePrefix(ecosystemID)
+	_, id, err := sc.insert([]string{`name`, `key_hash`, `key_id`, `role_id`, `routes`, `expire`,
+		`creator`, `created_at`, `ecosystem`},
+		[]interface{}{name, keyHash, keyID, roleID, routes, expire,
+			sc.TxSmart.KeyID, sc.BlockData.Time, ecosystemID}, apiKey.TableName())
+	if err != nil {
+		return 0, err
+	}
+	return converter.StrToInt64(id), nil
+}
+
+// APIKeyRevoke revokes the API key of the ecosystem
+func APIKeyRevoke(sc *SmartContract, id int64) error {
+	if sc.OBS {
+		return ErrNotImplementedOnOBS
+	}
+	if err := validateAccess(`APIKeyRevoke`, sc, nRevokeAPIKey); err != nil {
+		return err
+	}
+	apiKey := &model.APIKey{}
+	found, err := apiKey.SetTablePrefix(sc.TxSmart.EcosystemID).Get(sc.DbTransaction, id)
+	if err != nil {
+		return logErrorDB(err, "getting api key")
+	}
+	if !found {
+		return logErrorfShort(eAPIKeyNotFound, id, consts.NotFound)
+	}
+	if apiKey.Revoked != 0 {
+		return logErrorfShort(eAPIKeyRevoked, id, consts.InvalidObject)
+	}
+	_, _, err = sc.update([]string{`revoked`}, []interface{}{sc.BlockData.Time}, apiKey.TableName(),
+		`id`, apiKey.ID)
+	return err
+}
@@ -0,0 +1,152 @@
+// Copyright (C) 2017, 2018, 2019 EGAAS S.A.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or (at
+// your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but
+// WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301, USA.
+
+package smart
+
+import (
+	"github.com/AplaProject/go-apla/packages/consts"
+	"github.com/AplaProject/go-apla/packages/converter"
+	"github.com/AplaProject/go-apla/packages/model"
+)
+
+func getAsset(sc *SmartContract, assetID int64) (*model.Asset, error) {
+	asset := &model.Asset{}
+	found, err := asset.SetTablePrefix(sc.TxSmart.EcosystemID).Get(sc.DbTransaction, assetID)
+	if err != nil {
+		return nil, logErrorDB(err, "getting asset")
+	}
+	if !found {
+		return nil, logErrorfShort(eAssetNotFound, assetID, consts.NotFound)
+	}
+	return asset, nil
+}
+
+func isAssetOperator(sc *SmartContract, ownerID, operatorID int64) (bool, error) {
+	operator := &model.AssetOperator{}
+	found, err := operator.SetTablePrefix(sc.TxSmart.EcosystemID).Get(sc.DbTransaction, ownerID, operatorID)
+	if err != nil {
+		return false, logErrorDB(err, "getting asset operator")
+	}
+	return found && operator.Approved == 1, nil
+}
+
+// AssetMint creates a new unique asset of the recipient. The asset can be linked to the record of binaries
+// which contains its metadata
+func AssetMint(sc *SmartContract, name string, recipientID, binaryID int64) (int64, error) {
+	if sc.OBS {
+		return 0, ErrNotImplementedOnOBS
+	}
+	if err := validateAccess(`AssetMint`, sc, nNewAsset); err != nil {
+		return 0, err
+	}
+	if recipientID == 0 {
+		return 0, errAssetRecipient
+	}
+	if binaryID != 0 {
+		binary := &model.Binary{}
+		binary.SetTablePrefix(converter.Int64ToStr(sc.TxSmart.EcosystemID))
+		found, err := binary.GetInfo(sc.DbTransaction, binaryID)
+		if err != nil {
+			return 0, logErrorDB(err, "getting binary")
+		}
+		if !found {
+			return 0, logErrorfShort(eItemNotFound, binaryID, consts.NotFound)
+		}
+	}
+	asset := &model.Asset{}
+	asset.SetTablePrefix(sc.TxSmart.EcosystemID)
+	_, id, err := sc.insert([]string{`name`, `binary_id`, `owner`, `creator`, `created_at`, `ecosystem`},
+		[]interface{}{name, binaryID, recipientID, sc.TxSmart.KeyID, sc.BlockData.Time,
+			sc.TxSmart.EcosystemID}, asset.TableName())
+	if err != nil {
+		return 0, err
+	}
+	return converter.StrToInt64(id), nil
+}
+
+// AssetTransfer changes the owner of the asset. The key of the transaction must be the owner
+// of the asset or its approved operator
+func AssetTransfer(sc *SmartContract, assetID, recipientID int64) error {
+	if sc.OBS {
+		return ErrNotImplementedOnOBS
+	}
+	if err := validateAccess(`AssetTransfer`, sc, nAssetTransfer); err != nil {
+		return err
+	}
+	if recipientID == 0 {
+		return errAssetRecipient
+	}
+	asset, err := getAsset(sc, assetID)
+	if err != nil {
+		return err
+	}
+	if asset.Owner != sc.TxSmart.KeyID {
+		approved, err := isAssetOperator(sc, asset.Owner, sc.TxSmart.KeyID)
+		if err != nil {
+			return err
+		}
+		if !approved {
+			return errAccessDenied
+		}
+	}
+	_, _, err = sc.update([]string{`owner`}, []interface{}{recipientID}, asset.TableName(), `id`, asset.ID)
+	return err
+}
+
+// AssetApproveOperator allows or denies the operator to transfer all assets of the key of the transaction
+func AssetApproveOperator(sc *SmartContract, operatorID int64, approved bool) error {
+	if sc.OBS {
+		return ErrNotImplementedOnOBS
+	}
+	if err := validateAccess(`AssetApproveOperator`, sc, nAssetApproveOperator); err != nil {
+		return err
+	}
+	if operatorID == 0 || operatorID == sc.TxSmart.KeyID {
+		return errAssetOperator
+	}
+	var value int64
+	if approved {
+		value = 1
+	}
+	operator := &model.AssetOperator{}
+	found, err := operator.SetTablePrefix(sc.TxSmart.EcosystemID).Get(sc.DbTransaction,
+		sc.TxSmart.KeyID, operatorID)
+	if err != nil {
+		return logErrorDB(err, "getting asset operator")
+	}
+	if found {
+		_, _, err = sc.update([]string{`approved`}, []interface{}{value}, operator.TableName(),
+			`id`, operator.ID)
+		return err
+	}
+	_, _, err = sc.insert([]string{`owner_id`, `operator_id`, `approved`, `ecosystem`},
+		[]interface{}{sc.TxSmart.KeyID, operatorID, value, sc.TxSmart.EcosystemID}, operator.TableName())
+	return err
+}
+
+// AssetOwner returns the owner of the asset
+func AssetOwner(sc *SmartContract, assetID int64) (int64, error) {
+	asset, err := getAsset(sc, assetID)
+	if err != nil {
+		return 0, err
+	}
+	return asset.Owner, nil
+}
+
+// IsAssetOperator returns true if the operator is allowed to transfer assets of the owner
+func IsAssetOperator(sc *SmartContract, ownerID, operatorID int64) (bool, error) {
+	return isAssetOperator(sc, ownerID, operatorID)
+}
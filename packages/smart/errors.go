@@ -47,6 +47,21 @@ const (
 	eColumnNotDeleted    = `Column %s cannot be deleted`
 	eRollbackContract    = `Wrong rollback of the latest contract %d != %d`
 	eExternalNet         = `External network %s is not defined`
+	eTokenNotFound       = `Token %d has not been found`
+	eTokenExists         = `Token %s already exists`
+	eTokenSymbol         = `Symbol %s must only contain latin, digit and '_', '-' characters`
+	eTokenDecimals       = `Decimals %d is out of range`
+	eAssetNotFound       = `Asset %d has not been found`
+	eEscrowNotFound      = `Escrow %d has not been found`
+	eEscrowClosed        = `Escrow %d is already closed`
+	eProposalNotFound    = `Proposal %d has not been found`
+	eProposalClosed      = `Voting for proposal %d is closed`
+	eProposalVoted       = `Proposal %d has already been voted`
+	eProposalKind        = `Unknown kind of proposal %s`
+	eProposalValidator   = `Contract %s which checks the value of the system parameter has not been found`
+	eAPIKeyNotFound      = `API key %d has not been found`
+	eAPIKeyRevoked       = `API key %d is already revoked`
+	eAPIKeyRoute         = `Route %s of API key is incorrect`
 )
 
 var (
@@ -87,4 +102,25 @@ var (
 	errNotValidUTF        = errors.New(`Result is not valid utf-8 string`)
 	errFloat              = errors.New(`incorrect float value`)
 	errFloatResult        = errors.New(`incorrect float result`)
+	errTokenAmount        = errors.New(`Token amount must be greater than 0`)
+	errTokenAllowance     = errors.New(`Allowance is not enough`)
+	errTokenRecipient     = errors.New(`Recipient of tokens is undefined`)
+	errTokenSpender       = errors.New(`Spender of tokens is incorrect`)
+	errAssetRecipient     = errors.New(`Recipient of asset is undefined`)
+	errAssetOperator      = errors.New(`Operator of assets is incorrect`)
+	errEscrowDeadline     = errors.New(`Refund block or time of escrow must be specified`)
+	errEscrowParties      = errors.New(`Sender, recipient and arbiter of escrow must be different`)
+	errEscrowKey          = errors.New(`Key of escrow has not been found`)
+	errEscrowNotExpired   = errors.New(`Escrow cannot be refunded before the deadline`)
+	errProposalSystem     = errors.New(`System parameters can be voted only by full nodes in the first ecosystem`)
+	errProposalRole       = errors.New(`Role of voters must be specified`)
+	errProposalPercent    = errors.New(`Quorum and threshold must be from 1 to 100`)
+	errProposalPeriod     = errors.New(`Incorrect duration or timelock of proposal`)
+	errAPIKeyName         = errors.New(`Name of API key is incorrect`)
+	errAPIKeyHash         = errors.New(`Hash of API key must be 64 hex characters`)
+	errAPIKeyExists       = errors.New(`API key with the same hash already exists`)
+	errAPIKeyRole         = errors.New(`Account of API key doesn't have the role`)
+	errAPIKeyAccount      = errors.New(`Account of API key has not been found`)
+	errAPIKeyExpire       = errors.New(`Expiration time of API key has passed`)
+	errTemplateRenderer   = errors.New(`Template renderer is undefined`)
 )
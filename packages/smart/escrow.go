@@ -0,0 +1,202 @@
+// Copyright (C) 2017, 2018, 2019 EGAAS S.A.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or (at
+// your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but
+// WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301, USA.
+
+package smart
+
+import (
+	"fmt"
+
+	"github.com/AplaProject/go-apla/packages/consts"
+	"github.com/AplaProject/go-apla/packages/converter"
+	"github.com/AplaProject/go-apla/packages/model"
+
+	"github.com/shopspring/decimal"
+)
+
+func getEscrow(sc *SmartContract, escrowID int64) (*model.Escrow, error) {
+	escrow := &model.Escrow{}
+	found, err := escrow.SetTablePrefix(sc.TxSmart.EcosystemID).Get(sc.DbTransaction, escrowID)
+	if err != nil {
+		return nil, logErrorDB(err, "getting escrow")
+	}
+	if !found {
+		return nil, logErrorfShort(eEscrowNotFound, escrowID, consts.NotFound)
+	}
+	if escrow.Status != model.EscrowLocked {
+		return nil, logErrorfShort(eEscrowClosed, escrowID, consts.InvalidObject)
+	}
+	return escrow, nil
+}
+
+func getEscrowKey(sc *SmartContract, keyID int64) (*model.Key, error) {
+	key := &model.Key{}
+	found, err := key.SetTablePrefix(sc.TxSmart.EcosystemID).GetTransaction(sc.DbTransaction, keyID)
+	if err != nil {
+		return nil, logErrorDB(err, "getting key")
+	}
+	if !found {
+		return nil, logErrorValue(errEscrowKey, consts.NotFound, "escrow key", converter.Int64ToStr(keyID))
+	}
+	if key.Deleted == 1 {
+		return nil, errDeletedKey
+	}
+	return key, nil
+}
+
+// closeEscrow sets the final status of the escrow and pays the locked amount to the key
+func (sc *SmartContract) closeEscrow(escrow *model.Escrow, status, keyID int64, action string) error {
+	if _, _, err := sc.update([]string{`status`}, []interface{}{status}, escrow.TableName(),
+		`id`, escrow.ID); err != nil {
+		return err
+	}
+	if _, _, err := sc.update([]string{`+amount`}, []interface{}{escrow.Amount},
+		model.KeyTableName(sc.TxSmart.EcosystemID), `id`, keyID); err != nil {
+		return err
+	}
+	return sc.insertHistory(0, 0, keyID, escrow.Amount, fmt.Sprintf(`Escrow %d %s`, escrow.ID, action))
+}
+
+// EscrowLock moves the amount from the balance of the key of the transaction to the escrow.
+// The funds can be refunded after the block refundBlock or the time refundTime
+func EscrowLock(sc *SmartContract, recipientID, arbiterID int64, value interface{},
+	refundBlock, refundTime int64, comment string) (int64, error) {
+	if sc.OBS {
+		return 0, ErrNotImplementedOnOBS
+	}
+	if err := validateAccess(`EscrowLock`, sc, nEscrowLock); err != nil {
+		return 0, err
+	}
+	amount, err := checkTokenAmount(value)
+	if err != nil {
+		return 0, err
+	}
+	if refundBlock <= 0 && refundTime <= 0 {
+		return 0, errEscrowDeadline
+	}
+	senderID := sc.TxSmart.KeyID
+	if recipientID == 0 || recipientID == senderID || arbiterID == senderID || arbiterID == recipientID {
+		return 0, errEscrowParties
+	}
+	sender, err := getEscrowKey(sc, senderID)
+	if err != nil {
+		return 0, err
+	}
+	balance, err := decimal.NewFromString(sender.Amount)
+	if err != nil {
+		return 0, logErrorValue(err, consts.ConversionError, "converting key amount", sender.Amount)
+	}
+	if balance.LessThan(amount) {
+		return 0, logErrorValue(errCurrentBalance, consts.NoFunds, "escrow balance", converter.Int64ToStr(senderID))
+	}
+	if _, err = getEscrowKey(sc, recipientID); err != nil {
+		return 0, err
+	}
+	if arbiterID != 0 {
+		if _, err = getEscrowKey(sc, arbiterID); err != nil {
+			return 0, err
+		}
+	}
+
+	if _, _, err = sc.update([]string{`-amount`}, []interface{}{amount},
+		model.KeyTableName(sc.TxSmart.EcosystemID), `id`, senderID); err != nil {
+		return 0, err
+	}
+	escrow := &model.Escrow{}
+	escrow.SetTablePrefix(sc.TxSmart.EcosystemID)
+	_, id, err := sc.insert([]string{`sender_id`, `recipient_id`, `arbiter_id`, `amount`, `refund_block`,
+		`refund_time`, `comment`, `created_at`, `ecosystem`},
+		[]interface{}{senderID, recipientID, arbiterID, amount, refundBlock, refundTime, comment,
+			sc.BlockData.Time, sc.TxSmart.EcosystemID}, escrow.TableName())
+	if err != nil {
+		return 0, err
+	}
+	if err = sc.insertHistory(0, senderID, 0, amount, fmt.Sprintf(`Escrow %s lock`, id)); err != nil {
+		return 0, err
+	}
+	return converter.StrToInt64(id), nil
+}
+
+// EscrowApprove confirms the escrow by its arbiter. After that the funds can be released
+func EscrowApprove(sc *SmartContract, escrowID int64) error {
+	if sc.OBS {
+		return ErrNotImplementedOnOBS
+	}
+	if err := validateAccess(`EscrowApprove`, sc, nEscrowApprove); err != nil {
+		return err
+	}
+	escrow, err := getEscrow(sc, escrowID)
+	if err != nil {
+		return err
+	}
+	if escrow.ArbiterID == 0 || escrow.ArbiterID != sc.TxSmart.KeyID {
+		return errAccessDenied
+	}
+	_, _, err = sc.update([]string{`approved`}, []interface{}{1}, escrow.TableName(), `id`, escrow.ID)
+	return err
+}
+
+// EscrowRelease pays the locked funds to the recipient. Without arbiter only the sender can release
+// the escrow. If there is the arbiter then the escrow must be approved by it or released by it
+func EscrowRelease(sc *SmartContract, escrowID int64) error {
+	if sc.OBS {
+		return ErrNotImplementedOnOBS
+	}
+	if err := validateAccess(`EscrowRelease`, sc, nEscrowRelease); err != nil {
+		return err
+	}
+	escrow, err := getEscrow(sc, escrowID)
+	if err != nil {
+		return err
+	}
+	keyID := sc.TxSmart.KeyID
+	if escrow.ArbiterID == 0 {
+		if keyID != escrow.SenderID {
+			return errAccessDenied
+		}
+	} else if keyID != escrow.ArbiterID && !(escrow.Approved == 1 &&
+		(keyID == escrow.SenderID || keyID == escrow.RecipientID)) {
+		return errAccessDenied
+	}
+	return sc.closeEscrow(escrow, model.EscrowReleased, escrow.RecipientID, `release`)
+}
+
+// EscrowRefund returns the locked funds to the sender. The sender can do it when the deadline is reached,
+// the arbiter can refund the escrow at any time before its release
+func EscrowRefund(sc *SmartContract, escrowID int64) error {
+	if sc.OBS {
+		return ErrNotImplementedOnOBS
+	}
+	if err := validateAccess(`EscrowRefund`, sc, nEscrowRefund); err != nil {
+		return err
+	}
+	escrow, err := getEscrow(sc, escrowID)
+	if err != nil {
+		return err
+	}
+	keyID := sc.TxSmart.KeyID
+	switch {
+	case escrow.ArbiterID != 0 && keyID == escrow.ArbiterID:
+	case keyID == escrow.SenderID:
+		expired := (escrow.RefundBlock > 0 && sc.BlockData.BlockID >= escrow.RefundBlock) ||
+			(escrow.RefundTime > 0 && sc.BlockData.Time >= escrow.RefundTime)
+		if !expired {
+			return errEscrowNotExpired
+		}
+	default:
+		return errAccessDenied
+	}
+	return sc.closeEscrow(escrow, model.EscrowRefunded, escrow.SenderID, `refund`)
+}
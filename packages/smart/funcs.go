@@ -185,6 +185,7 @@ var (
 		"DBUpdate":    {},
 		"DBUpdateExt": {},
 		"SetPubKey":   {},
+		"RenderHTML":  {},
 	}
 	extendCost = map[string]int64{
 		"AddressToId":                  10,
@@ -223,6 +224,8 @@ var (
 		"TrimSpace":                    10,
 		"TableConditions":              100,
 		"ValidateCondition":            30,
+		"LintTemplate":                 50,
+		"RenderHTML":                   100,
 		"ValidateEditContractNewValue": 10,
 		"TransactionInfo":              100,
 		"DelTable":                     100,
@@ -237,6 +240,29 @@ var (
 		"Floor":                        15,
 		"CheckCondition":               10,
 		"SendExternalTransaction":      100,
+		"TokenCreate":                  100,
+		"TokenMint":                    50,
+		"TokenBurn":                    50,
+		"TokenTransfer":                50,
+		"TokenTransferFrom":            50,
+		"TokenApprove":                 30,
+		"TokenAllowance":               10,
+		"TokenBalance":                 10,
+		"AssetMint":                    100,
+		"AssetTransfer":                50,
+		"AssetApproveOperator":         30,
+		"AssetOwner":                   10,
+		"IsAssetOperator":              10,
+		"EscrowLock":                   100,
+		"EscrowApprove":                30,
+		"EscrowRelease":                50,
+		"EscrowRefund":                 50,
+		"ProposalCreate":               100,
+		"ProposalVote":                 50,
+		"ProposalsReady":               100,
+		"ProposalApply":                50,
+		"APIKeyCreate":                 50,
+		"APIKeyRevoke":                 30,
 	}
 	// map for table name to parameter with conditions
 	tableParamConditions = map[string]string{
@@ -257,6 +283,7 @@ var (
 		`double`:    `double precision`,
 		`money`:     `decimal (30, 0) NOT NULL DEFAULT '0'`,
 		`text`:      `text`,
+		`richtext`:  `richtext`,
 	}
 )
 
@@ -320,6 +347,8 @@ func EmbedFuncs(vm *script.VM, vt script.VMType) {
 		"LangRes":                      LangRes,
 		"HasPrefix":                    strings.HasPrefix,
 		"ValidateCondition":            ValidateCondition,
+		"LintTemplate":                 LintTemplate,
+		"RenderHTML":                   RenderHTML,
 		"TrimSpace":                    strings.TrimSpace,
 		"ToLower":                      strings.ToLower,
 		"ToUpper":                      strings.ToUpper,
@@ -374,6 +403,29 @@ func EmbedFuncs(vm *script.VM, vt script.VMType) {
 		"Floor":                        Floor,
 		"CheckCondition":               CheckCondition,
 		"SendExternalTransaction":      SendExternalTransaction,
+		"TokenCreate":                  TokenCreate,
+		"TokenMint":                    TokenMint,
+		"TokenBurn":                    TokenBurn,
+		"TokenTransfer":                TokenTransfer,
+		"TokenTransferFrom":            TokenTransferFrom,
+		"TokenApprove":                 TokenApprove,
+		"TokenAllowance":               TokenAllowance,
+		"TokenBalance":                 TokenBalance,
+		"AssetMint":                    AssetMint,
+		"AssetTransfer":                AssetTransfer,
+		"AssetApproveOperator":         AssetApproveOperator,
+		"AssetOwner":                   AssetOwner,
+		"IsAssetOperator":              IsAssetOperator,
+		"EscrowLock":                   EscrowLock,
+		"EscrowApprove":                EscrowApprove,
+		"EscrowRelease":                EscrowRelease,
+		"EscrowRefund":                 EscrowRefund,
+		"ProposalCreate":               ProposalCreate,
+		"ProposalVote":                 ProposalVote,
+		"ProposalsReady":               ProposalsReady,
+		"ProposalApply":                ProposalApply,
+		"APIKeyCreate":                 APIKeyCreate,
+		"APIKeyRevoke":                 APIKeyRevoke,
 	}
 
 	switch vt {
@@ -409,28 +461,47 @@ func EmbedFuncs(vm *script.VM, vt script.VMType) {
 	}
 
 	vmExtend(vm, &script.ExtendData{Objects: f, AutoPars: map[string]string{
-		`*smart.SmartContract`: `sc`},
+		`*smart.SmartContract`: `sc`, `*script.RunTime`: `rt`},
 		WriteFuncs: map[string]struct{}{
-			"CreateColumn":     {},
-			"CreateTable":      {},
-			"DBInsert":         {},
-			"DBUpdate":         {},
-			"DBUpdateSysParam": {},
-			"DBUpdateExt":      {},
-			"CreateEcosystem":  {},
-			"CreateContract":   {},
-			"UpdateContract":   {},
-			"CreateLanguage":   {},
-			"EditLanguage":     {},
-			"BindWallet":       {},
-			"UnbindWallet":     {},
-			"EditEcosysName":   {},
-			"UpdateNodesBan":   {},
-			"UpdateCron":       {},
-			"CreateOBS":        {},
-			"DeleteOBS":        {},
-			"DelColumn":        {},
-			"DelTable":         {},
+			"CreateColumn":         {},
+			"CreateTable":          {},
+			"DBInsert":             {},
+			"DBUpdate":             {},
+			"DBUpdateSysParam":     {},
+			"DBUpdateExt":          {},
+			"CreateEcosystem":      {},
+			"CreateContract":       {},
+			"UpdateContract":       {},
+			"CreateLanguage":       {},
+			"EditLanguage":         {},
+			"BindWallet":           {},
+			"UnbindWallet":         {},
+			"EditEcosysName":       {},
+			"UpdateNodesBan":       {},
+			"UpdateCron":           {},
+			"CreateOBS":            {},
+			"DeleteOBS":            {},
+			"DelColumn":            {},
+			"DelTable":             {},
+			"TokenCreate":          {},
+			"TokenMint":            {},
+			"TokenBurn":            {},
+			"TokenTransfer":        {},
+			"TokenTransferFrom":    {},
+			"TokenApprove":         {},
+			"AssetMint":            {},
+			"AssetTransfer":        {},
+			"AssetApproveOperator": {},
+			"EscrowLock":           {},
+			"EscrowApprove":        {},
+			"EscrowRelease":        {},
+			"EscrowRefund":         {},
+			"ProposalCreate":       {},
+			"ProposalVote":         {},
+			"ProposalsReady":       {},
+			"ProposalApply":        {},
+			"APIKeyCreate":         {},
+			"APIKeyRevoke":         {},
 		},
 	})
 }
@@ -812,6 +883,9 @@ func DBInsert(sc *SmartContract, tblname string, values *types.Map) (qcost int64
 	if reflect.TypeOf(val[0]) == reflect.TypeOf([]interface{}{}) {
 		val = val[0].([]interface{})
 	}
+	if err = sanitizeRichText(sc, tblname, params, val); err != nil {
+		return
+	}
 	qcost, lastID, err = sc.insert(params, val, tblname)
 	if ind > 0 {
 		qcost *= int64(ind)
@@ -872,8 +946,15 @@ func GetColumns(inColumns interface{}) ([]string, error) {
 }
 
 func GetOrder(tblname string, inOrder interface{}) (string, error) {
+	order, _, err := getOrder(tblname, inOrder)
+	return order, err
+}
+
+// getOrder returns the order expression and the names of the columns specified in inOrder
+func getOrder(tblname string, inOrder interface{}) (string, []string, error) {
 	var (
-		orders []string
+		orders  []string
+		columns []string
 	)
 	cols := types.NewMap()
 
@@ -881,6 +962,7 @@ func GetOrder(tblname string, inOrder interface{}) (string, error) {
 		in = converter.Sanitize(strings.ToLower(in), ``)
 		if len(in) > 0 {
 			cols.Set(in, true)
+			columns = append(columns, in)
 			in = `"` + in + `"`
 			if fmt.Sprint(value) == `-1` {
 				in += ` desc`
@@ -933,9 +1015,9 @@ func GetOrder(tblname string, inOrder interface{}) (string, error) {
 		}
 	}
 	if err := qb.CheckNow(orders...); err != nil {
-		return ``, err
+		return ``, nil, err
 	}
-	return strings.Join(orders, `,`), nil
+	return strings.Join(orders, `,`), columns, nil
 }
 
 // DBSelect returns an array of values of the specified columns when there is selection of data 'offset', 'limit', 'where'
@@ -1046,6 +1128,9 @@ func DBUpdateExt(sc *SmartContract, tblname string, where *types.Map,
 	if err = sc.AccessColumns(tblname, &columns, true); err != nil {
 		return
 	}
+	if err = sanitizeRichText(sc, tblname, columns, val); err != nil {
+		return
+	}
 	qcost, _, err = sc.updateWhere(columns, val, tblname, where)
 	return
 }
@@ -1318,6 +1403,57 @@ func ValidateCondition(sc *SmartContract, condition string, state int64) error {
 	return VMCompileEval(sc.VM, condition, uint32(state))
 }
 
+// TemplateLinter checks the source of the template. It is set by the template package
+// because the template package depends on this one
+var TemplateLinter func(transaction *model.DbTransaction, input string, ecosystemID int64) ([]error, error)
+
+// LintTemplate returns the problems in the source of the page or block separated by new lines.
+// It doesn't fail itself, the contracts decide what to do with the problems
+func LintTemplate(sc *SmartContract, input string) (string, error) {
+	if TemplateLinter == nil {
+		return ``, nil
+	}
+	errs, err := TemplateLinter(sc.DbTransaction, input, sc.TxSmart.EcosystemID)
+	if err != nil {
+		return ``, logErrorDB(err, "checking template")
+	}
+	ret := make([]string, len(errs))
+	for i, item := range errs {
+		ret[i] = item.Error()
+	}
+	return strings.Join(ret, "\n"), nil
+}
+
+// TemplateRenderer converts the template to HTML and returns its cost. It is set by the template package
+var TemplateRenderer func(input string, vars map[string]string, limit int64) ([]byte, int64, error)
+
+// RenderHTML returns the template rendered to HTML. The values of params are available
+// in the template as variables. The functions which read the database are not allowed and
+// the fuel is charged for the calls of the functions and for the size of the result
+func RenderHTML(sc *SmartContract, rt *script.RunTime, input string, params *types.Map) (int64, []byte, error) {
+	if TemplateRenderer == nil {
+		return 0, nil, logErrorShort(errTemplateRenderer, consts.NotFound)
+	}
+	vars := make(map[string]string)
+	if params != nil {
+		for _, key := range params.Keys() {
+			v, _ := params.Get(key)
+			vars[key] = fmt.Sprint(v)
+		}
+	}
+	vars[`ecosystem_id`] = converter.Int64ToStr(sc.TxSmart.EcosystemID)
+	vars[`key_id`] = converter.Int64ToStr(sc.TxSmart.KeyID)
+	if sc.OBS {
+		vars[`obs`] = `true`
+	}
+	// the rendering can't spend more than the fuel which is left
+	out, cost, err := TemplateRenderer(input, vars, rt.Cost())
+	if err != nil {
+		return cost, nil, logErrorShort(err, consts.ParameterExceeded)
+	}
+	return cost, out, nil
+}
+
 // ColumnCondition is contract func
 func ColumnCondition(sc *SmartContract, tableName, name, coltype, permissions string) error {
 	name = converter.EscapeSQL(strings.ToLower(name))
@@ -0,0 +1,284 @@
+// Copyright (C) 2017, 2018, 2019 EGAAS S.A.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or (at
+// your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but
+// WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301, USA.
+
+package smart
+
+import (
+	"github.com/AplaProject/go-apla/packages/conf/syspar"
+	"github.com/AplaProject/go-apla/packages/consts"
+	"github.com/AplaProject/go-apla/packages/converter"
+	"github.com/AplaProject/go-apla/packages/model"
+	"github.com/AplaProject/go-apla/packages/types"
+)
+
+func getProposal(sc *SmartContract, ecosystemID, proposalID int64) (*model.Proposal, error) {
+	proposal := &model.Proposal{}
+	found, err := proposal.SetTablePrefix(ecosystemID).Get(sc.DbTransaction, proposalID)
+	if err != nil {
+		return nil, logErrorDB(err, "getting proposal")
+	}
+	if !found {
+		return nil, logErrorfShort(eProposalNotFound, proposalID, consts.NotFound)
+	}
+	return proposal, nil
+}
+
+// proposalVoters returns the number of keys which can vote for the proposal
+func proposalVoters(sc *SmartContract, proposal *model.Proposal) (int64, error) {
+	if proposal.RoleID == 0 {
+		return int64(len(syspar.GetNodes())), nil
+	}
+	count, err := model.CountRoleMembers(sc.DbTransaction, proposal.RoleID, proposal.Ecosystem)
+	if err != nil {
+		return 0, logErrorDB(err, "counting role members")
+	}
+	return count, nil
+}
+
+func isProposalVoter(sc *SmartContract, proposal *model.Proposal, keyID int64) (bool, error) {
+	if proposal.RoleID == 0 {
+		for _, node := range syspar.GetNodes() {
+			if node.KeyID == keyID {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	ok, err := model.MemberHasRole(sc.DbTransaction, proposal.RoleID, proposal.Ecosystem,
+		converter.AddressToString(keyID))
+	if err != nil {
+		return false, logErrorDB(err, "checking role of member")
+	}
+	return ok, nil
+}
+
+// validateSysParam checks the value of the system parameter by the conditions of the contract
+// with the same name as the parameter. @1UpdateSysParam calls this contract when the parameter is changed
+func validateSysParam(sc *SmartContract, name, value string) error {
+	contract := VMGetContract(sc.VM, name, 1)
+	if contract == nil {
+		return logErrorfShort(eProposalValidator, name, consts.NotFound)
+	}
+	block := contract.GetFunc(`conditions`)
+	if block == nil {
+		return nil
+	}
+	vars := sc.getExtend()
+	(*vars)[`Value`] = value
+	if err := sc.AppendStack(name); err != nil {
+		return err
+	}
+	defer sc.PopStack(name)
+	_, err := VMRun(sc.VM, block, []interface{}{}, vars)
+	return err
+}
+
+// ProposalCreate creates the proposal to change the system or ecosystem parameter.
+// System parameters are voted by the keys of full nodes, ecosystem parameters are voted
+// by the members of the role. Quorum and threshold are specified in percents, duration of voting
+// and timelock before applying are specified in seconds
+func ProposalCreate(sc *SmartContract, kind, name, value string, roleID, quorum, threshold,
+	duration, timelock int64) (int64, error) {
+	if sc.OBS {
+		return 0, ErrNotImplementedOnOBS
+	}
+	if err := validateAccess(`ProposalCreate`, sc, nNewProposal); err != nil {
+		return 0, err
+	}
+	ecosystemID := sc.TxSmart.EcosystemID
+	switch kind {
+	case model.ProposalSystem:
+		if ecosystemID != 1 || roleID != 0 {
+			return 0, errProposalSystem
+		}
+		par := &model.SystemParameter{}
+		found, err := par.GetTransaction(sc.DbTransaction, name)
+		if err != nil {
+			return 0, logErrorDB(err, "system parameter get")
+		}
+		if !found {
+			return 0, logErrorfShort(eParamNotFound, name, consts.NotFound)
+		}
+		if err = validateSysParam(sc, name, value); err != nil {
+			return 0, err
+		}
+	case model.ProposalEcosystem:
+		if roleID <= 0 {
+			return 0, errProposalRole
+		}
+		par := &model.StateParameter{}
+		par.SetTablePrefix(converter.Int64ToStr(ecosystemID))
+		found, err := par.Get(sc.DbTransaction, name)
+		if err != nil {
+			return 0, logErrorDB(err, "ecosystem parameter get")
+		}
+		if !found {
+			return 0, logErrorfShort(eParamNotFound, name, consts.NotFound)
+		}
+	default:
+		return 0, logErrorfShort(eProposalKind, kind, consts.InvalidObject)
+	}
+	if quorum <= 0 || quorum > 100 || threshold <= 0 || threshold > 100 {
+		return 0, errProposalPercent
+	}
+	if duration <= 0 || timelock < 0 {
+		return 0, errProposalPeriod
+	}
+	proposal := &model.Proposal{}
+	proposal.SetTablePrefix(ecosystemID)
+	_, id, err := sc.insert([]string{`kind`, `name`, `value`, `creator`, `role_id`, `quorum`, `threshold`,
+		`deadline`, `timelock`, `created_at`, `ecosystem`},
+		[]interface{}{kind, name, value, sc.TxSmart.KeyID, roleID, quorum, threshold,
+			sc.BlockData.Time + duration, timelock, sc.BlockData.Time, ecosystemID}, proposal.TableName())
+	if err != nil {
+		return 0, err
+	}
+	return converter.StrToInt64(id), nil
+}
+
+// ProposalVote votes for or against the proposal by the key of the transaction
+func ProposalVote(sc *SmartContract, proposalID int64, accept bool) error {
+	if sc.OBS {
+		return ErrNotImplementedOnOBS
+	}
+	if err := validateAccess(`ProposalVote`, sc, nProposalVote); err != nil {
+		return err
+	}
+	proposal, err := getProposal(sc, sc.TxSmart.EcosystemID, proposalID)
+	if err != nil {
+		return err
+	}
+	if proposal.Status != model.ProposalVoting || sc.BlockData.Time >= proposal.Deadline {
+		return logErrorfShort(eProposalClosed, proposalID, consts.InvalidObject)
+	}
+	keyID := sc.TxSmart.KeyID
+	ok, err := isProposalVoter(sc, proposal, keyID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errAccessDenied
+	}
+	vote := &model.ProposalVote{}
+	found, err := vote.SetTablePrefix(proposal.Ecosystem).Get(sc.DbTransaction, proposalID, keyID)
+	if err != nil {
+		return logErrorDB(err, "getting proposal vote")
+	}
+	if found {
+		return logErrorfShort(eProposalVoted, proposalID, consts.DuplicateObject)
+	}
+	var value int64
+	field := `+votes_against`
+	if accept {
+		value = 1
+		field = `+votes_for`
+	}
+	if _, _, err = sc.insert([]string{`proposal_id`, `key_id`, `vote`, `created_at`, `ecosystem`},
+		[]interface{}{proposalID, keyID, value, sc.BlockData.Time, proposal.Ecosystem},
+		vote.TableName()); err != nil {
+		return err
+	}
+	_, _, err = sc.update([]string{field}, []interface{}{1}, proposal.TableName(), `id`, proposal.ID)
+	return err
+}
+
+// ProposalsReady completes the voting of the expired proposals and returns the accepted proposals
+// of all ecosystems which must be applied. The values of the system parameters are checked again
+// and the proposal is rejected if the value is not valid anymore, so it doesn't block the others
+func ProposalsReady(sc *SmartContract) ([]interface{}, error) {
+	if sc.OBS {
+		return nil, ErrNotImplementedOnOBS
+	}
+	if err := validateAccess(`ProposalsReady`, sc, nApplyProposals); err != nil {
+		return nil, err
+	}
+	now := sc.BlockData.Time
+	expired, err := model.GetExpiredProposals(sc.DbTransaction, now)
+	if err != nil {
+		return nil, logErrorDB(err, "getting expired proposals")
+	}
+	for i := range expired {
+		proposal := &expired[i]
+		voters, err := proposalVoters(sc, proposal)
+		if err != nil {
+			return nil, err
+		}
+		total := proposal.VotesFor + proposal.VotesAgainst
+		status := int64(model.ProposalRejected)
+		if voters > 0 && total > 0 && total*100 >= proposal.Quorum*voters &&
+			proposal.VotesFor*100 >= proposal.Threshold*total {
+			status = model.ProposalAccepted
+		}
+		if _, _, err = sc.update([]string{`status`, `voters`}, []interface{}{status, voters},
+			proposal.TableName(), `id`, proposal.ID); err != nil {
+			return nil, err
+		}
+	}
+	ready, err := model.GetReadyProposals(sc.DbTransaction, now)
+	if err != nil {
+		return nil, logErrorDB(err, "getting ready proposals")
+	}
+	result := make([]interface{}, 0, len(ready))
+	for i := range ready {
+		proposal := &ready[i]
+		if proposal.Kind == model.ProposalSystem {
+			if err = validateSysParam(sc, proposal.Name, proposal.Value); err != nil {
+				if _, _, err = sc.update([]string{`status`}, []interface{}{model.ProposalRejected},
+					proposal.TableName(), `id`, proposal.ID); err != nil {
+					return nil, err
+				}
+				continue
+			}
+		}
+		result = append(result, types.LoadMap(map[string]interface{}{
+			`id`:        proposal.ID,
+			`kind`:      proposal.Kind,
+			`name`:      proposal.Name,
+			`value`:     proposal.Value,
+			`ecosystem`: proposal.Ecosystem,
+		}))
+	}
+	return result, nil
+}
+
+// ProposalApply marks the accepted proposal as applied. The ecosystem parameter is changed here,
+// the system parameter must be changed by @1UpdateSysParam contract before the call
+func ProposalApply(sc *SmartContract, ecosystemID, proposalID int64) error {
+	if sc.OBS {
+		return ErrNotImplementedOnOBS
+	}
+	if err := validateAccess(`ProposalApply`, sc, nApplyProposals); err != nil {
+		return err
+	}
+	proposal, err := getProposal(sc, ecosystemID, proposalID)
+	if err != nil {
+		return err
+	}
+	if proposal.Status != model.ProposalAccepted {
+		return logErrorfShort(eProposalClosed, proposalID, consts.InvalidObject)
+	}
+	if proposal.Kind == model.ProposalEcosystem {
+		par := &model.StateParameter{}
+		par.SetTablePrefix(converter.Int64ToStr(proposal.Ecosystem))
+		if _, _, err = sc.updateWhere([]string{`value`}, []interface{}{proposal.Value}, par.TableName(),
+			types.LoadMap(map[string]interface{}{`ecosystem`: proposal.Ecosystem, `name`: proposal.Name})); err != nil {
+			return err
+		}
+	}
+	_, _, err = sc.update([]string{`status`}, []interface{}{model.ProposalApplied}, proposal.TableName(),
+		`id`, proposal.ID)
+	return err
+}
@@ -18,8 +18,12 @@ package queryBuilder
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 	"testing"
 
+	"github.com/AplaProject/go-apla/packages/types"
+
 	log "github.com/sirupsen/logrus"
 )
 
@@ -40,13 +44,12 @@ func (tc TestKeyTableChecker) IsKeyTable(tableName string) bool {
 	return tc.Val
 }
 func TestSqlFields(t *testing.T) {
-	qb := smartQueryBuilder{
+	qb := SQLQueryBuilder{
 		Entry:        log.WithFields(log.Fields{"mod": "test"}),
-		table:        "1_keys",
+		Table:        "1_keys",
 		Fields:       []string{"+amount"},
 		FieldValues:  []interface{}{2912910000000000000},
-		WhereFields:  []string{"id"},
-		WhereValues:  []string{"-6752330173818123413"},
+		Where:        types.LoadMap(map[string]interface{}{"id": "-6752330173818123413"}),
 		KeyTableChkr: TestKeyTableChecker{true},
 	}
 
@@ -58,3 +61,30 @@ func TestSqlFields(t *testing.T) {
 
 	fmt.Println(fields)
 }
+
+func TestWhereColumns(t *testing.T) {
+	where := types.LoadMap(map[string]interface{}{
+		`name`: `John`,
+		`$or`: []interface{}{
+			types.LoadMap(map[string]interface{}{`amount`: types.LoadMap(map[string]interface{}{`$gt`: 10})}),
+			types.LoadMap(map[string]interface{}{`data->city`: `Paris`}),
+		},
+	})
+	cols := WhereColumns(where)
+	sort.Strings(cols)
+	if strings.Join(cols, `,`) != `amount,data,name` {
+		t.Errorf(`wrong columns %v`, cols)
+	}
+	where = types.LoadMap(map[string]interface{}{
+		`name`: types.LoadMap(map[string]interface{}{`$in`: `a'); drop table x; --`}),
+	})
+	QuoteInValues(where)
+	ret, err := GetWhere(where)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if ret != `("name" in ('a''); drop table x; --'))` {
+		t.Errorf(`wrong where %s`, ret)
+	}
+}
@@ -227,3 +227,64 @@ func GetWhere(inWhere *types.Map) (string, error) {
 	}
 	return where, nil
 }
+
+// WhereColumns returns the names of the columns which are used in the condition
+func WhereColumns(inWhere *types.Map) []string {
+	var columns []string
+	if inWhere == nil {
+		return columns
+	}
+	var sub func(v interface{})
+	sub = func(v interface{}) {
+		switch value := v.(type) {
+		case *types.Map:
+			columns = append(columns, WhereColumns(value)...)
+		case []interface{}:
+			for _, item := range value {
+				sub(item)
+			}
+		}
+	}
+	for _, key := range inWhere.Keys() {
+		v, _ := inWhere.Get(key)
+		key = converter.Sanitize(strings.ToLower(key), `->$`)
+		if !strings.HasPrefix(key, `$`) && len(key) > 0 {
+			if off := strings.Index(key, `->`); off >= 0 {
+				key = key[:off]
+			}
+			columns = append(columns, key)
+		}
+		sub(v)
+	}
+	return columns
+}
+
+// QuoteInValues converts the string values of $in and $nin into single item lists
+// so that they are escaped like the other values of the condition
+func QuoteInValues(inWhere *types.Map) {
+	if inWhere == nil {
+		return
+	}
+	var sub func(v interface{})
+	sub = func(v interface{}) {
+		switch value := v.(type) {
+		case *types.Map:
+			QuoteInValues(value)
+		case []interface{}:
+			for _, item := range value {
+				sub(item)
+			}
+		}
+	}
+	for _, key := range inWhere.Keys() {
+		v, _ := inWhere.Get(key)
+		switch strings.ToLower(key) {
+		case `$in`, `$nin`:
+			if value, ok := v.(string); ok && len(value) > 0 {
+				inWhere.Set(key, []interface{}{value})
+				continue
+			}
+		}
+		sub(v)
+	}
+}
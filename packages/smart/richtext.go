@@ -0,0 +1,167 @@
+// Copyright (C) 2017, 2018, 2019 EGAAS S.A.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or (at
+// your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but
+// WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301, USA.
+
+package smart
+
+import (
+	"bytes"
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/AplaProject/go-apla/packages/model"
+)
+
+const columnRichText = `richtext`
+
+var (
+	// richTextTags is the list of allowed tags with their allowed attributes
+	richTextTags = map[string][]string{
+		`a`: {`href`, `title`}, `b`: nil, `blockquote`: nil, `br`: nil, `code`: nil, `em`: nil,
+		`h1`: nil, `h2`: nil, `h3`: nil, `h4`: nil, `h5`: nil, `h6`: nil, `hr`: nil, `i`: nil,
+		`img`: {`src`, `alt`, `title`}, `li`: nil, `ol`: nil, `p`: nil, `pre`: nil, `s`: nil,
+		`span`: {`class`}, `strong`: nil, `sub`: nil, `sup`: nil, `u`: nil, `ul`: nil,
+	}
+	// richTextSkip is the list of tags which are removed together with their content
+	richTextSkip = map[string]bool{
+		`script`: true, `style`: true, `iframe`: true, `object`: true, `embed`: true,
+		`noscript`: true, `template`: true, `textarea`: true, `title`: true,
+	}
+	reRichTag  = regexp.MustCompile(`^<(/?)([a-zA-Z][a-zA-Z0-9]*)((?:\s+[^\s"'<>/=]+(?:\s*=\s*(?:"[^"]*"|'[^']*'|[^\s"'=<>` + "`" + `]+))?)*)\s*/?>`)
+	reRichAttr = regexp.MustCompile(`([^\s"'<>/=]+)(?:\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'=<>` + "`" + `]+)))?`)
+)
+
+// richTextURL returns the link if it is relative or has a safe scheme
+func richTextURL(link string) string {
+	clean := strings.ToLower(strings.Map(func(r rune) rune {
+		if r <= ' ' {
+			return -1
+		}
+		return r
+	}, link))
+	if off := strings.IndexAny(clean, `:/?#`); off >= 0 && clean[off] == ':' {
+		switch clean[:off] {
+		case `http`, `https`, `mailto`:
+		default:
+			return ``
+		}
+	}
+	return strings.TrimSpace(link)
+}
+
+// SanitizeRichText removes all tags and attributes which are not allowed in richtext columns
+func SanitizeRichText(input string) string {
+	var out bytes.Buffer
+	for len(input) > 0 {
+		off := strings.IndexByte(input, '<')
+		if off < 0 {
+			out.WriteString(input)
+			break
+		}
+		out.WriteString(input[:off])
+		input = input[off:]
+		if strings.HasPrefix(input, `<!--`) {
+			if end := strings.Index(input, `-->`); end >= 0 {
+				input = input[end+3:]
+			} else {
+				input = ``
+			}
+			continue
+		}
+		m := reRichTag.FindStringSubmatch(input)
+		if m == nil {
+			out.WriteString(`&lt;`)
+			input = input[1:]
+			continue
+		}
+		input = input[len(m[0]):]
+		name := strings.ToLower(m[2])
+		if richTextSkip[name] {
+			if len(m[1]) == 0 {
+				if loc := regexp.MustCompile(`(?i)</` + name + `\s*>`).FindStringIndex(input); loc != nil {
+					input = input[loc[1]:]
+				} else {
+					input = ``
+				}
+			}
+			continue
+		}
+		allowed, ok := richTextTags[name]
+		if !ok {
+			continue
+		}
+		if len(m[1]) > 0 {
+			out.WriteString(`</` + name + `>`)
+			continue
+		}
+		out.WriteString(`<` + name)
+		for _, attr := range reRichAttr.FindAllStringSubmatch(m[3], -1) {
+			key := strings.ToLower(attr[1])
+			if !containsString(allowed, key) {
+				continue
+			}
+			value := html.UnescapeString(attr[2] + attr[3] + attr[4])
+			if key == `href` || key == `src` {
+				if value = richTextURL(value); len(value) == 0 {
+					continue
+				}
+			}
+			out.WriteString(` ` + key + `="` + html.EscapeString(value) + `"`)
+		}
+		out.WriteString(`>`)
+	}
+	return out.String()
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// sanitizeRichText sanitizes the values of richtext columns before writing them into the table
+func sanitizeRichText(sc *SmartContract, tblname string, columns []string, values []interface{}) error {
+	var markup bool
+	for _, val := range values {
+		if s, ok := val.(string); ok && strings.IndexByte(s, '<') >= 0 {
+			markup = true
+			break
+		}
+	}
+	if !markup {
+		return nil
+	}
+	cols, err := model.GetAllColumnTypesTx(sc.DbTransaction, tblname)
+	if err != nil {
+		return logErrorDB(err, "getting column types")
+	}
+	colTypes := make(map[string]string, len(cols))
+	for _, item := range cols {
+		colTypes[item["column_name"]] = item["data_type"]
+	}
+	for i, col := range columns {
+		if i >= len(values) || colTypes[col] != columnRichText {
+			continue
+		}
+		if s, ok := values[i].(string); ok {
+			values[i] = SanitizeRichText(s)
+		}
+	}
+	return nil
+}
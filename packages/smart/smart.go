@@ -32,6 +32,8 @@ import (
 	"github.com/AplaProject/go-apla/packages/crypto"
 	"github.com/AplaProject/go-apla/packages/model"
 	"github.com/AplaProject/go-apla/packages/script"
+	qb "github.com/AplaProject/go-apla/packages/smart/queryBuilder"
+	"github.com/AplaProject/go-apla/packages/types"
 	"github.com/AplaProject/go-apla/packages/utils"
 
 	"github.com/shopspring/decimal"
@@ -804,6 +806,37 @@ func (sc *SmartContract) CheckAccess(tableName, columns string, ecosystem int64)
 	return
 }
 
+// CheckFilterAccess returns the where and order expressions for the table and checks
+// that the columns used in them can be read
+func (sc *SmartContract) CheckFilterAccess(table string, inWhere *types.Map,
+	inOrder interface{}) (where, order string, err error) {
+	var collist []string
+
+	qb.QuoteInValues(inWhere)
+	if where, err = qb.GetWhere(inWhere); err != nil {
+		return
+	}
+	if order, collist, err = getOrder(table, inOrder); err != nil {
+		return
+	}
+	if !syspar.IsPrivateBlockchain() {
+		return
+	}
+	collist = append(collist, qb.WhereColumns(inWhere)...)
+	if len(collist) == 0 {
+		return
+	}
+	allowed := make([]string, len(collist))
+	copy(allowed, collist)
+	if err = sc.AccessColumns(table, &allowed, false); err != nil {
+		return
+	}
+	if len(allowed) != len(collist) {
+		err = errAccessDenied
+	}
+	return
+}
+
 // AccessRights checks the access right by executing the condition value
 func (sc *SmartContract) AccessRights(condition string, iscondition bool) error {
 	sp := &model.StateParameter{}
@@ -48,6 +48,7 @@ var (
 		"DBUpdateSysParam": {},
 		"DBUpdateExt":      {},
 		"DBSelect":         {},
+		"RenderHTML":       {},
 	}
 
 	extendCostSysParams = map[string]string{
@@ -92,23 +93,41 @@ var (
 )
 
 const (
-	nBindWallet        = "BindWallet"
-	nUnbindWallet      = "UnbindWallet"
-	nEditColumn        = "EditColumn"
-	nEditContract      = "EditContract"
-	nEditEcosystemName = "EditEcosystemName"
-	nEditLang          = "EditLang"
-	nEditLangJoint     = "EditLangJoint"
-	nEditTable         = "EditTable"
-	nImport            = "Import"
-	nNewColumn         = "NewColumn"
-	nNewContract       = "NewContract"
-	nNewEcosystem      = "NewEcosystem"
-	nNewLang           = "NewLang"
-	nNewLangJoint      = "NewLangJoint"
-	nNewTable          = "NewTable"
-	nNewTableJoint     = "NewTableJoint"
-	nNewUser           = "NewUser"
+	nBindWallet           = "BindWallet"
+	nUnbindWallet         = "UnbindWallet"
+	nEditColumn           = "EditColumn"
+	nEditContract         = "EditContract"
+	nEditEcosystemName    = "EditEcosystemName"
+	nEditLang             = "EditLang"
+	nEditLangJoint        = "EditLangJoint"
+	nEditTable            = "EditTable"
+	nImport               = "Import"
+	nNewColumn            = "NewColumn"
+	nNewContract          = "NewContract"
+	nNewEcosystem         = "NewEcosystem"
+	nNewLang              = "NewLang"
+	nNewLangJoint         = "NewLangJoint"
+	nNewTable             = "NewTable"
+	nNewTableJoint        = "NewTableJoint"
+	nNewUser              = "NewUser"
+	nNewToken             = "NewToken"
+	nTokenApprove         = "TokenApprove"
+	nTokenBurn            = "TokenBurn"
+	nTokenMint            = "TokenMint"
+	nTokenTransfer        = "TokenTransfer"
+	nTokenTransferFrom    = "TokenTransferFrom"
+	nNewAsset             = "NewAsset"
+	nAssetTransfer        = "AssetTransfer"
+	nAssetApproveOperator = "AssetApproveOperator"
+	nEscrowLock           = "EscrowLock"
+	nEscrowApprove        = "EscrowApprove"
+	nEscrowRelease        = "EscrowRelease"
+	nEscrowRefund         = "EscrowRefund"
+	nNewProposal          = "NewProposal"
+	nProposalVote         = "ProposalVote"
+	nApplyProposals       = "ApplyProposals"
+	nNewAPIKey            = "NewAPIKey"
+	nRevokeAPIKey         = "RevokeAPIKey"
 )
 
 //SignRes contains the data of the signature
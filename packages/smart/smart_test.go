@@ -93,3 +93,23 @@ func TestCheckAppend(t *testing.T) {
 	_, err := Run(cfunc, nil, &map[string]interface{}{})
 	require.NoError(t, err)
 }
+
+func TestSanitizeRichText(t *testing.T) {
+	cases := []struct {
+		input, want string
+	}{
+		{`<p>Hello <b>world</b></p>`, `<p>Hello <b>world</b></p>`},
+		{`<p onclick="alert(1)" class="x">text</p>`, `<p>text</p>`},
+		{`<script>alert(1)</script>safe`, `safe`},
+		{`<a href="javascript:alert(1)" title='t'>link</a>`, `<a title="t">link</a>`},
+		{`<a href="java&#09;script:alert(1)">link</a>`, `<a>link</a>`},
+		{`<a href="https://apla.io/?a=1&amp;b=2">link</a>`, `<a href="https://apla.io/?a=1&amp;b=2">link</a>`},
+		{`<img src="/img.png" onerror="x()"/>`, `<img src="/img.png">`},
+		{`<div><font color=red>text</font></div>`, `text`},
+		{`1 < 2 <!-- comment -->`, `1 &lt; 2 `},
+		{`<STYLE>p {}</style><EM>em</EM>`, `<em>em</em>`},
+	}
+	for _, item := range cases {
+		require.Equal(t, item.want, SanitizeRichText(item.input), item.input)
+	}
+}
@@ -276,3 +276,18 @@ func SysRollbackDeleteTable(DbTransaction *model.DbTransaction, sysData SysRollD
 	}
 	return nil
 }
+
+// SysRollbackDeleteRateLimitUsage is restoring the deleted calls of the rate limits
+func SysRollbackDeleteRateLimitUsage(DbTransaction *model.DbTransaction, sysData SysRollData) error {
+	var usage []model.RateLimitUsage
+	err := unmarshalJSON([]byte(sysData.Data), &usage, `rollback delete rate limit usage to json`)
+	if err != nil {
+		return err
+	}
+	for i := range usage {
+		if err = usage[i].Restore(DbTransaction); err != nil {
+			return logErrorDB(err, "restoring rate limit usage")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,332 @@
+// Copyright (C) 2017, 2018, 2019 EGAAS S.A.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or (at
+// your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but
+// WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301, USA.
+
+package smart
+
+import (
+	"fmt"
+
+	"github.com/AplaProject/go-apla/packages/consts"
+	"github.com/AplaProject/go-apla/packages/converter"
+	"github.com/AplaProject/go-apla/packages/model"
+	"github.com/AplaProject/go-apla/packages/script"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	maxTokenSymbol   = 32
+	maxTokenDecimals = 18
+)
+
+func checkTokenAmount(v interface{}) (decimal.Decimal, error) {
+	amount, err := script.ValueToDecimal(v)
+	if err != nil {
+		return amount, err
+	}
+	if amount.LessThanOrEqual(decimal.Zero) {
+		return amount, logErrorValue(errTokenAmount, consts.InvalidObject, "token amount", amount.String())
+	}
+	return amount, nil
+}
+
+func getToken(sc *SmartContract, tokenID int64) (*model.Token, error) {
+	token := &model.Token{}
+	found, err := token.SetTablePrefix(sc.TxSmart.EcosystemID).Get(sc.DbTransaction, tokenID)
+	if err != nil {
+		return nil, logErrorDB(err, "getting token")
+	}
+	if !found {
+		return nil, logErrorfShort(eTokenNotFound, tokenID, consts.NotFound)
+	}
+	return token, nil
+}
+
+func getTokenBalance(sc *SmartContract, tokenID, keyID int64) (*model.TokenBalance, bool, error) {
+	balance := &model.TokenBalance{}
+	found, err := balance.SetTablePrefix(sc.TxSmart.EcosystemID).Get(sc.DbTransaction, tokenID, keyID)
+	if err != nil {
+		return nil, false, logErrorDB(err, "getting token balance")
+	}
+	return balance, found, nil
+}
+
+func (sc *SmartContract) addTokenBalance(tokenID, keyID int64, amount decimal.Decimal) error {
+	balance, found, err := getTokenBalance(sc, tokenID, keyID)
+	if err != nil {
+		return err
+	}
+	if found {
+		_, _, err = sc.update([]string{`+amount`}, []interface{}{amount}, balance.TableName(),
+			`id`, balance.ID)
+		return err
+	}
+	_, _, err = sc.insert([]string{`token_id`, `key_id`, `amount`, `ecosystem`},
+		[]interface{}{tokenID, keyID, amount, sc.TxSmart.EcosystemID}, balance.TableName())
+	return err
+}
+
+func (sc *SmartContract) subTokenBalance(tokenID, keyID int64, amount decimal.Decimal) error {
+	balance, found, err := getTokenBalance(sc, tokenID, keyID)
+	if err != nil {
+		return err
+	}
+	if !found || balance.Amount.LessThan(amount) {
+		return logErrorValue(errCurrentBalance, consts.NoFunds, "token balance", converter.Int64ToStr(keyID))
+	}
+	_, _, err = sc.update([]string{`-amount`}, []interface{}{amount}, balance.TableName(), `id`, balance.ID)
+	return err
+}
+
+func (sc *SmartContract) insertHistory(tokenID, senderID, recipientID int64, amount decimal.Decimal,
+	comment string) error {
+	_, _, err := sc.insert(
+		[]string{
+			"sender_id",
+			"recipient_id",
+			"amount",
+			"comment",
+			"block_id",
+			"txhash",
+			"ecosystem",
+			"created_at",
+			"token_id",
+		},
+		[]interface{}{
+			senderID,
+			recipientID,
+			amount,
+			comment,
+			sc.BlockData.BlockID,
+			sc.TxHash,
+			sc.TxSmart.EcosystemID,
+			sc.BlockData.Time,
+			tokenID,
+		},
+		`1_history`)
+	return err
+}
+
+func (sc *SmartContract) moveTokens(tokenID, senderID, recipientID int64, amount decimal.Decimal,
+	comment string) error {
+	if recipientID == 0 {
+		return errTokenRecipient
+	}
+	if _, err := getToken(sc, tokenID); err != nil {
+		return err
+	}
+	if err := sc.subTokenBalance(tokenID, senderID, amount); err != nil {
+		return err
+	}
+	if err := sc.addTokenBalance(tokenID, recipientID, amount); err != nil {
+		return err
+	}
+	return sc.insertHistory(tokenID, senderID, recipientID, amount, comment)
+}
+
+// TokenCreate registers a new token of the ecosystem. The key of the transaction becomes the owner of the token
+func TokenCreate(sc *SmartContract, symbol, name string, decimals int64) (int64, error) {
+	if sc.OBS {
+		return 0, ErrNotImplementedOnOBS
+	}
+	if err := validateAccess(`TokenCreate`, sc, nNewToken); err != nil {
+		return 0, err
+	}
+	if len(symbol) == 0 || len(symbol) > maxTokenSymbol || !converter.IsLatin(symbol) {
+		return 0, logErrorfShort(eTokenSymbol, symbol, consts.InvalidObject)
+	}
+	if decimals < 0 || decimals > maxTokenDecimals {
+		return 0, logErrorfShort(eTokenDecimals, decimals, consts.InvalidObject)
+	}
+	token := &model.Token{}
+	found, err := token.SetTablePrefix(sc.TxSmart.EcosystemID).GetBySymbol(sc.DbTransaction, symbol)
+	if err != nil {
+		return 0, logErrorDB(err, "getting token by symbol")
+	}
+	if found {
+		return 0, logErrorfShort(eTokenExists, symbol, consts.DuplicateObject)
+	}
+	_, id, err := sc.insert([]string{`symbol`, `name`, `decimals`, `owner`, `total_supply`, `ecosystem`},
+		[]interface{}{symbol, name, decimals, sc.TxSmart.KeyID, decimal.Zero, sc.TxSmart.EcosystemID},
+		token.TableName())
+	if err != nil {
+		return 0, err
+	}
+	return converter.StrToInt64(id), nil
+}
+
+// TokenMint issues the amount of tokens to the recipient. Only the owner of the token can mint it
+func TokenMint(sc *SmartContract, tokenID, recipientID int64, value interface{}) error {
+	if sc.OBS {
+		return ErrNotImplementedOnOBS
+	}
+	if err := validateAccess(`TokenMint`, sc, nTokenMint); err != nil {
+		return err
+	}
+	amount, err := checkTokenAmount(value)
+	if err != nil {
+		return err
+	}
+	if recipientID == 0 {
+		return errTokenRecipient
+	}
+	token, err := getToken(sc, tokenID)
+	if err != nil {
+		return err
+	}
+	if token.Owner != sc.TxSmart.KeyID {
+		return errAccessDenied
+	}
+	if _, _, err = sc.update([]string{`+total_supply`}, []interface{}{amount}, token.TableName(),
+		`id`, token.ID); err != nil {
+		return err
+	}
+	if err = sc.addTokenBalance(tokenID, recipientID, amount); err != nil {
+		return err
+	}
+	return sc.insertHistory(tokenID, 0, recipientID, amount, fmt.Sprintf(`Mint of %s`, token.Symbol))
+}
+
+// TokenBurn destroys the amount of tokens from the balance of the key of the transaction
+func TokenBurn(sc *SmartContract, tokenID int64, value interface{}) error {
+	if sc.OBS {
+		return ErrNotImplementedOnOBS
+	}
+	if err := validateAccess(`TokenBurn`, sc, nTokenBurn); err != nil {
+		return err
+	}
+	amount, err := checkTokenAmount(value)
+	if err != nil {
+		return err
+	}
+	token, err := getToken(sc, tokenID)
+	if err != nil {
+		return err
+	}
+	if err = sc.subTokenBalance(tokenID, sc.TxSmart.KeyID, amount); err != nil {
+		return err
+	}
+	if _, _, err = sc.update([]string{`-total_supply`}, []interface{}{amount}, token.TableName(),
+		`id`, token.ID); err != nil {
+		return err
+	}
+	return sc.insertHistory(tokenID, sc.TxSmart.KeyID, 0, amount, fmt.Sprintf(`Burn of %s`, token.Symbol))
+}
+
+// TokenTransfer moves the amount of tokens from the key of the transaction to the recipient
+func TokenTransfer(sc *SmartContract, tokenID, recipientID int64, value interface{}, comment string) error {
+	if sc.OBS {
+		return ErrNotImplementedOnOBS
+	}
+	if err := validateAccess(`TokenTransfer`, sc, nTokenTransfer); err != nil {
+		return err
+	}
+	amount, err := checkTokenAmount(value)
+	if err != nil {
+		return err
+	}
+	return sc.moveTokens(tokenID, sc.TxSmart.KeyID, recipientID, amount, comment)
+}
+
+// TokenApprove allows the spender to withdraw tokens from the key of the transaction up to the amount
+func TokenApprove(sc *SmartContract, tokenID, spenderID int64, value interface{}) error {
+	if sc.OBS {
+		return ErrNotImplementedOnOBS
+	}
+	if err := validateAccess(`TokenApprove`, sc, nTokenApprove); err != nil {
+		return err
+	}
+	amount, err := script.ValueToDecimal(value)
+	if err != nil {
+		return err
+	}
+	if amount.LessThan(decimal.Zero) {
+		return logErrorValue(errTokenAmount, consts.InvalidObject, "token allowance", amount.String())
+	}
+	if spenderID == 0 || spenderID == sc.TxSmart.KeyID {
+		return errTokenSpender
+	}
+	if _, err = getToken(sc, tokenID); err != nil {
+		return err
+	}
+	allowance := &model.TokenAllowance{}
+	found, err := allowance.SetTablePrefix(sc.TxSmart.EcosystemID).Get(sc.DbTransaction, tokenID,
+		sc.TxSmart.KeyID, spenderID)
+	if err != nil {
+		return logErrorDB(err, "getting token allowance")
+	}
+	if found {
+		_, _, err = sc.update([]string{`amount`}, []interface{}{amount}, allowance.TableName(), `id`, allowance.ID)
+		return err
+	}
+	_, _, err = sc.insert([]string{`token_id`, `owner_id`, `spender_id`, `amount`, `ecosystem`},
+		[]interface{}{tokenID, sc.TxSmart.KeyID, spenderID, amount, sc.TxSmart.EcosystemID},
+		allowance.TableName())
+	return err
+}
+
+// TokenTransferFrom moves the amount of tokens from the owner to the recipient using
+// the allowance given to the key of the transaction
+func TokenTransferFrom(sc *SmartContract, tokenID, ownerID, recipientID int64, value interface{},
+	comment string) error {
+	if sc.OBS {
+		return ErrNotImplementedOnOBS
+	}
+	if err := validateAccess(`TokenTransferFrom`, sc, nTokenTransferFrom); err != nil {
+		return err
+	}
+	amount, err := checkTokenAmount(value)
+	if err != nil {
+		return err
+	}
+	allowance := &model.TokenAllowance{}
+	found, err := allowance.SetTablePrefix(sc.TxSmart.EcosystemID).Get(sc.DbTransaction, tokenID,
+		ownerID, sc.TxSmart.KeyID)
+	if err != nil {
+		return logErrorDB(err, "getting token allowance")
+	}
+	if !found || allowance.Amount.LessThan(amount) {
+		return logErrorValue(errTokenAllowance, consts.NoFunds, "token allowance", converter.Int64ToStr(ownerID))
+	}
+	if _, _, err = sc.update([]string{`-amount`}, []interface{}{amount}, allowance.TableName(),
+		`id`, allowance.ID); err != nil {
+		return err
+	}
+	return sc.moveTokens(tokenID, ownerID, recipientID, amount, comment)
+}
+
+// TokenAllowance returns the amount of tokens which the spender is allowed to withdraw from the owner
+func TokenAllowance(sc *SmartContract, tokenID, ownerID, spenderID int64) (decimal.Decimal, error) {
+	allowance := &model.TokenAllowance{}
+	found, err := allowance.SetTablePrefix(sc.TxSmart.EcosystemID).Get(sc.DbTransaction, tokenID,
+		ownerID, spenderID)
+	if err != nil {
+		return decimal.Zero, logErrorDB(err, "getting token allowance")
+	}
+	if !found {
+		return decimal.Zero, nil
+	}
+	return allowance.Amount, nil
+}
+
+// TokenBalance returns the amount of tokens of the key
+func TokenBalance(sc *SmartContract, tokenID, keyID int64) (decimal.Decimal, error) {
+	balance, found, err := getTokenBalance(sc, tokenID, keyID)
+	if err != nil || !found {
+		return decimal.Zero, err
+	}
+	return balance.Amount, nil
+}
@@ -53,6 +53,7 @@ var (
 	errDiv            = errors.New(`dividing by zero`)
 	errPrecIsNegative = errors.New(`precision is negative`)
 	errWhere          = errors.New(`Where has wrong format`)
+	errAccessDenied   = errors.New(`Access denied`)
 )
 
 func parsing(input string, itype int) (*[]token, error) {
@@ -0,0 +1,282 @@
+// Copyright (C) 2017, 2018, 2019 EGAAS S.A.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or (at
+// your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but
+// WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301, USA.
+
+package template
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/AplaProject/go-apla/packages/consts"
+	"github.com/AplaProject/go-apla/packages/converter"
+	"github.com/AplaProject/go-apla/packages/model"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	chartStacked      = `stacked`
+	chartDefaultLimit = 100
+	chartMaxSeries    = 20
+
+	chartLabel  = `label`
+	chartSeries = `series`
+)
+
+var (
+	errChartColumn    = errors.New(`Chart column has not been found`)
+	errChartAggregate = errors.New(`Chart aggregate function must be sum, count, avg, min or max`)
+	errChartBucket    = errors.New(`Chart bucket must be hour, day or month`)
+	errChartSeries    = errors.New(`Chart with Series must have one value field`)
+	errChartFilter    = errors.New(`Chart cannot aggregate the table with the filter of rows`)
+
+	chartAggregates = map[string]bool{`sum`: true, `count`: true, `avg`: true, `min`: true, `max`: true}
+	chartBuckets    = map[string]string{
+		`hour`:  `YYYY-MM-DD HH24:00`,
+		`day`:   `YYYY-MM-DD`,
+		`month`: `YYYY-MM`,
+	}
+)
+
+func chartValue(val string) string {
+	if val == `NULL` {
+		return ``
+	}
+	return val
+}
+
+func chartAttr(par parFunc, name string) string {
+	if val, ok := par.Node.Attr[name].(string); ok {
+		return strings.TrimSpace(val)
+	}
+	return ``
+}
+
+// chartFields returns the list of comma separated column names
+func chartFields(input string) []string {
+	fields := make([]string, 0)
+	for _, item := range strings.Split(input, `,`) {
+		if item = strings.TrimSpace(item); len(item) > 0 {
+			fields = append(fields, item)
+		}
+	}
+	return fields
+}
+
+// chartColumn checks that the column exists and returns the quoted column name
+func chartColumn(columnTypes map[string]string, name string) (string, error) {
+	name = strings.ToLower(converter.Sanitize(name, ``))
+	if _, ok := columnTypes[name]; !ok {
+		return ``, errChartColumn
+	}
+	return `"` + name + `"`, nil
+}
+
+// chartAggregate groups the rows of Table tail in SQL and returns the data node with the result.
+// The label is FieldLabel column or the time buckets of this column, the values are aggregated
+// FieldValue columns or the values of the single FieldValue split by Series column
+func chartAggregate(par parFunc) (*node, error) {
+	state := converter.StrToInt64(getVar(par.Workspace, `ecosystem_id`))
+	tblname := strings.ToLower(converter.ParseTable(chartAttr(par, `table`), state))
+	par.Workspace.readTable(tblname)
+
+	rows, err := model.GetAllColumnTypes(tblname)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting column types from db")
+		return nil, err
+	}
+	columnTypes := make(map[string]string, len(rows))
+	for _, row := range rows {
+		columnTypes[row[columnNameKey]] = row[dataTypeKey]
+	}
+	label := chartAttr(par, `fieldlabel`)
+	values := chartFields(chartAttr(par, `fieldvalue`))
+	series := chartAttr(par, `series`)
+	columns := append([]string{label}, values...)
+	if len(series) > 0 {
+		columns = append(columns, series)
+	}
+	where, whereColumns, err := getWhere(chartAttr(par, `where`))
+	if err != nil {
+		return nil, err
+	}
+	columns = append(columns, whereColumns...)
+	sc := par.Workspace.SmartContract
+	// AccessColumns removes the denied columns from the list instead of returning an error
+	allowed := make([]string, len(columns))
+	copy(allowed, columns)
+	perm, err := sc.AccessTablePerm(tblname, `read`)
+	if err != nil || sc.AccessColumns(tblname, &allowed, false) != nil || len(allowed) != len(columns) {
+		log.WithFields(log.Fields{"table": tblname, "columns": columns}).Error("ACCESS DENIED")
+		return nil, errAccessDenied
+	}
+	if perm != nil && len(perm[`filter`]) > 0 {
+		return nil, errChartFilter
+	}
+
+	labelExp, err := chartColumn(columnTypes, label)
+	if err != nil {
+		return nil, err
+	}
+	if bucket := chartAttr(par, `bucket`); len(bucket) > 0 {
+		format, ok := chartBuckets[bucket]
+		if !ok {
+			return nil, errChartBucket
+		}
+		if model.DataTypeToColumnType(columnTypes[strings.Trim(labelExp, `"`)]) == `number` {
+			labelExp = fmt.Sprintf(`to_timestamp(%s)`, labelExp)
+		}
+		labelExp = fmt.Sprintf(`to_char(date_trunc('%s', %s), '%s')`, bucket, labelExp, format)
+	}
+	aggregate := strings.ToLower(chartAttr(par, `aggregate`))
+	if len(aggregate) == 0 {
+		aggregate = `sum`
+		if len(values) == 0 {
+			aggregate = `count`
+		}
+	}
+	if !chartAggregates[aggregate] {
+		return nil, errChartAggregate
+	}
+	if len(values) == 0 {
+		values = []string{aggregate}
+	}
+	limit := chartDefaultLimit
+	if val := chartAttr(par, `limit`); len(val) > 0 {
+		limit = converter.StrToInt(val)
+	}
+	if limit <= 0 || limit > consts.DBFindLimit {
+		limit = consts.DBFindLimit
+	}
+
+	fields := []string{labelExp + ` as "` + chartLabel + `"`}
+	groupBy := `1`
+	if len(series) > 0 {
+		if len(values) != 1 {
+			return nil, errChartSeries
+		}
+		seriesExp, err := chartColumn(columnTypes, series)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, seriesExp+`::text as "`+chartSeries+`"`)
+		groupBy = `1, 2`
+		limit *= chartMaxSeries
+		if limit > consts.DBFindLimit {
+			limit = consts.DBFindLimit
+		}
+	}
+	for i, value := range values {
+		valueExp := `*`
+		if value != aggregate || aggregate != `count` {
+			if valueExp, err = chartColumn(columnTypes, value); err != nil {
+				return nil, err
+			}
+		}
+		fields = append(fields, fmt.Sprintf(`%s(%s) as "v%d"`, aggregate, valueExp, i))
+	}
+	if len(where) > 0 {
+		where = ` where ` + where
+	}
+	list, err := model.GetAll(`select `+strings.Join(fields, `, `)+` from "`+tblname+`"`+where+
+		` group by `+groupBy+` order by `+groupBy, limit)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("aggregating chart data")
+		return nil, err
+	}
+	var data [][]string
+	if len(series) > 0 {
+		data, values = chartPivot(list)
+	} else {
+		data = make([][]string, 0, len(list))
+		for _, item := range list {
+			row := []string{chartValue(item[chartLabel])}
+			for i := range values {
+				row = append(row, chartValue(item[fmt.Sprintf(`v%d`, i)]))
+			}
+			data = append(data, row)
+		}
+	}
+	par.Node.Attr[`fieldlabel`] = label
+	par.Node.Attr[`fieldvalue`] = strings.Join(values, `,`)
+	if len(values) > 1 {
+		par.Node.Attr[`series`] = values
+	} else {
+		delete(par.Node.Attr, `series`)
+	}
+	for _, attr := range []string{`table`, `where`, `aggregate`, `bucket`, `limit`} {
+		delete(par.Node.Attr, attr)
+	}
+
+	cols := append([]string{label}, values...)
+	types := make([]string, len(cols))
+	for i := range types {
+		types[i] = columnTypeText
+	}
+	source := chartAttr(par, `source`)
+	if len(source) == 0 {
+		source = `chart_` + tblname
+		par.Node.Attr[`source`] = source
+	}
+	dataNode := &node{Tag: `data`, Attr: map[string]interface{}{
+		`source`:  source,
+		`columns`: &cols,
+		`types`:   &types,
+		`data`:    &data,
+	}}
+	newSource(parFunc{Node: dataNode, Workspace: par.Workspace})
+	return dataNode, nil
+}
+
+// chartPivot turns the rows (label, series, value) into the rows with the column for each series
+func chartPivot(list []map[string]string) ([][]string, []string) {
+	names := make([]string, 0)
+	index := make(map[string]int)
+	for _, item := range list {
+		name := chartValue(item[chartSeries])
+		if _, ok := index[name]; !ok && len(names) < chartMaxSeries {
+			index[name] = len(names)
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for i, name := range names {
+		index[name] = i
+	}
+	data := make([][]string, 0)
+	rows := make(map[string][]string)
+	for _, item := range list {
+		col, ok := index[chartValue(item[chartSeries])]
+		if !ok {
+			continue
+		}
+		label := chartValue(item[chartLabel])
+		row, ok := rows[label]
+		if !ok {
+			row = make([]string, len(names)+1)
+			row[0] = label
+			for i := range names {
+				row[i+1] = `0`
+			}
+			rows[label] = row
+			data = append(data, row)
+		}
+		row[col+1] = chartValue(item[`v0`])
+	}
+	return data, names
+}
@@ -0,0 +1,115 @@
+// Copyright (C) 2017, 2018, 2019 EGAAS S.A.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or (at
+// your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but
+// WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301, USA.
+
+package template
+
+import (
+	"fmt"
+
+	"github.com/AplaProject/go-apla/packages/converter"
+	"github.com/AplaProject/go-apla/packages/model"
+	"github.com/AplaProject/go-apla/packages/script"
+	"github.com/AplaProject/go-apla/packages/smart"
+)
+
+const (
+	formGroupClass  = `form-group`
+	formInputClass  = `form-control`
+	formButtonClass = `btn btn-primary`
+
+	regexpInt     = `^-?\d+$`
+	regexpFloat   = `^-?\d+(\.\d+)?$`
+	regexpMoney   = `^\d+(\.\d+)?$`
+	regexpAddress = `^(\d{4}-){4}\d{4}$|^-?\d+$`
+)
+
+// formInput returns the input and the validation rules for the field of the contract
+func formInput(field *script.FieldInfo) *node {
+	input := &node{Tag: `input`, Attr: map[string]interface{}{
+		`name`:  field.Name,
+		`class`: formInputClass,
+		`type`:  `text`,
+	}}
+	validate := make(map[string]interface{})
+	if !field.ContainsTag(script.TagOptional) {
+		validate[`required`] = `true`
+	}
+	switch script.OriginalToString(field.Original) {
+	case `int`:
+		input.Attr[`type`] = `number`
+		validate[`regexp`] = regexpInt
+	case `float`:
+		validate[`regexp`] = regexpFloat
+	case `money`:
+		validate[`regexp`] = regexpMoney
+	case `address`:
+		validate[`regexp`] = regexpAddress
+	case `bool`:
+		input.Attr[`type`] = `checkbox`
+		delete(validate, `required`)
+	case `file`:
+		input.Attr[`type`] = `file`
+	}
+	if field.ContainsTag(script.TagAddress) {
+		validate[`regexp`] = regexpAddress
+	}
+	if field.ContainsTag(script.TagFile) {
+		input.Attr[`type`] = `file`
+	}
+	if len(validate) > 0 {
+		input.Attr[`validate`] = validate
+	}
+	return input
+}
+
+// contractFormTag generates the form for the data section of the contract
+func contractFormTag(par parFunc) string {
+	name := macro((*par.Pars)[`Name`], par.Workspace.Vars)
+	if len(name) == 0 {
+		return ``
+	}
+	par.Workspace.readTable((&model.Contract{}).TableName())
+	contract := smart.VMGetContract(par.Workspace.SmartContract.VM, name,
+		uint32(converter.StrToInt64(getVar(par.Workspace, `ecosystem_id`))))
+	if contract == nil {
+		return fmt.Sprintf(`Contract %s has not been found`, name)
+	}
+	form := &node{Tag: `form`, Attr: make(map[string]interface{})}
+	if class := macro((*par.Pars)[`Class`], par.Workspace.Vars); len(class) > 0 {
+		form.Attr[`class`] = class
+	}
+	info := contract.Block.Info.(*script.ContractInfo)
+	if info.Tx != nil {
+		for _, field := range *info.Tx {
+			if field.ContainsTag(script.TagSignature) {
+				continue
+			}
+			group := &node{Tag: `div`, Attr: map[string]interface{}{`class`: formGroupClass}}
+			group.Children = []*node{
+				{Tag: `label`, Attr: map[string]interface{}{`for`: field.Name},
+					Children: []*node{{Tag: tagText, Text: field.Name}}},
+				formInput(field),
+			}
+			form.Children = append(form.Children, group)
+		}
+	}
+	form.Children = append(form.Children, &node{Tag: `button`,
+		Attr:     map[string]interface{}{`class`: formButtonClass, `contract`: info.Name},
+		Children: []*node{{Tag: tagText, Text: info.Name}},
+	})
+	par.Owner.Children = append(par.Owner.Children, form)
+	return ``
+}
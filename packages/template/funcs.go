@@ -55,6 +55,9 @@ var (
 const (
 	columnNameKey = "column_name"
 	dataTypeKey   = "data_type"
+
+	defaultPageSize  = 25
+	defaultPageParam = "page"
 )
 
 func init() {
@@ -76,12 +79,15 @@ func init() {
 	funcs[`Hint`] = tplFunc{defaultTag, defaultTag, `hint`, `Icon,Title,Text`}
 	funcs[`ImageInput`] = tplFunc{defaultTag, defaultTag, `imageinput`, `Name,Width,Ratio,Format`}
 	funcs[`InputErr`] = tplFunc{defaultTag, defaultTag, `inputerr`, `*`}
+	funcs[`HTTPSource`] = tplFunc{httpSourceTag, defaultTag, `httpsource`, `Source,Url,Path,Headers`}
 	funcs[`JsonToSource`] = tplFunc{jsontosourceTag, defaultTag, `jsontosource`, `Source,Data,Prefix`}
 	funcs[`ArrayToSource`] = tplFunc{arraytosourceTag, defaultTag, `arraytosource`, `Source,Data,Prefix`}
 	funcs[`LangRes`] = tplFunc{langresTag, defaultTag, `langres`, `Name,Lang`}
 	funcs[`MenuGroup`] = tplFunc{menugroupTag, defaultTag, `menugroup`, `Title,Body,Icon`}
 	funcs[`MenuItem`] = tplFunc{defaultTag, defaultTag, `menuitem`, `Title,Page,PageParams,Icon,Vde`}
+	funcs[`Markdown`] = tplFunc{markdownTag, defaultTag, `markdown`, `Text`}
 	funcs[`Money`] = tplFunc{moneyTag, defaultTag, `money`, `Exp,Digit`}
+	funcs[`Paginator`] = tplFunc{paginatorTag, defaultTag, `paginator`, `Source,PageSize,PageParam`}
 	funcs[`Range`] = tplFunc{rangeTag, defaultTag, `range`, `Source,From,To,Step`}
 	funcs[`SetTitle`] = tplFunc{defaultTag, defaultTag, `settitle`, `Title`}
 	funcs[`SetVar`] = tplFunc{setvarTag, defaultTag, `setvar`, `Name,Value`}
@@ -93,7 +99,9 @@ func init() {
 	funcs[`Form`] = tplFunc{defaultTailTag, defaultTailTag, `form`, `Class,Body`}
 	funcs[`If`] = tplFunc{ifTag, ifFull, `if`, `Condition,Body`}
 	funcs[`Image`] = tplFunc{imageTag, defaultTailTag, `image`, `Src,Alt,Class`}
-	funcs[`Include`] = tplFunc{includeTag, defaultTag, `include`, `Name`}
+	funcs[`Include`] = tplFunc{includeTag, includeFull, `include`, `*`}
+	funcs[`Params`] = tplFunc{paramsTag, paramsFull, `params`, `*`}
+	funcs[`ContractForm`] = tplFunc{contractFormTag, defaultTag, `contractform`, `Name,Class`}
 	funcs[`Input`] = tplFunc{defaultTailTag, defaultTailTag, `input`, `Name,Class,Placeholder,Type,Value,Disabled`}
 	funcs[`Label`] = tplFunc{defaultTailTag, defaultTailTag, `label`, `Body,Class,For`}
 	funcs[`LinkPage`] = tplFunc{defaultTailTag, defaultTailTag, `linkpage`, `Body,Page,Class,PageParams`}
@@ -166,6 +174,14 @@ func init() {
 		`Vars`:    {tplFunc{tailTag, defaultTailFull, `vars`, `Prefix`}, false},
 		`Cutoff`:  {tplFunc{tailTag, defaultTailFull, `cutoff`, `Cutoff`}, false},
 	}}
+	tails[`chart`] = forTails{map[string]tailInfo{
+		`Table`:     {tplFunc{tailTag, defaultTailFull, `table`, `Table`}, false},
+		`Where`:     {tplFunc{tailTag, defaultTailFull, `where`, `Where`}, false},
+		`Aggregate`: {tplFunc{tailTag, defaultTailFull, `aggregate`, `Aggregate`}, false},
+		`Bucket`:    {tplFunc{tailTag, defaultTailFull, `bucket`, `Bucket`}, false},
+		`Series`:    {tplFunc{tailTag, defaultTailFull, `series`, `Series`}, false},
+		`Limit`:     {tplFunc{tailTag, defaultTailFull, `limit`, `Limit`}, false},
+	}}
 	tails[`p`] = forTails{map[string]tailInfo{
 		`Style`: {tplFunc{tailTag, defaultTailFull, `style`, `Style`}, false},
 	}}
@@ -284,6 +300,9 @@ func forlistTag(par parFunc) (ret string) {
 		for key, item := range vals {
 			setVar(par.Workspace, key, item)
 		}
+		if !par.Workspace.charge(renderRowCost) {
+			return
+		}
 		process((*par.Pars)[`Data`], &root, par.Workspace)
 		for _, item := range root.Children {
 			if item.Tag == `text` {
@@ -370,6 +389,7 @@ func ecosysparTag(par parFunc) string {
 	}
 	sp := &model.StateParameter{}
 	sp.SetTablePrefix(ecosystem)
+	par.Workspace.readTable(sp.TableName())
 	parameterName := macro((*par.Pars)[`Name`], par.Workspace.Vars)
 	_, err := sp.Get(nil, parameterName)
 	if err != nil {
@@ -396,6 +416,7 @@ func appparTag(par parFunc) string {
 	}
 	ap := &model.AppParam{}
 	ap.SetTablePrefix(ecosystem)
+	par.Workspace.readTable(ap.TableName())
 	_, err := ap.Get(nil, converter.StrToInt64(macro((*par.Pars)[`App`], par.Workspace.Vars)),
 		macro((*par.Pars)[`Name`], par.Workspace.Vars))
 	if err != nil {
@@ -424,6 +445,8 @@ func langresTag(par parFunc) string {
 
 func sysparTag(par parFunc) (ret string) {
 	if len((*par.Pars)[`Name`]) > 0 {
+		// system parameters are updated in memory after the commit of the block
+		par.Workspace.noCache()
 		ret = syspar.SysString(macro((*par.Pars)[`Name`], par.Workspace.Vars))
 	}
 	return
@@ -465,6 +488,7 @@ func txinfoTag(par parFunc) (out string) {
 	setAllAttr(par)
 	if par.Node.Attr[`hash`] != nil {
 		var err error
+		par.Workspace.noCache()
 		out, err = smart.TransactionInfo(par.Node.Attr[`hash`].(string))
 		if err != nil {
 			out = err.Error()
@@ -552,6 +576,38 @@ func dataTag(par parFunc) string {
 	return ``
 }
 
+// getWhere converts the JSON-like condition of Where tail into SQL
+// getWhere returns the where expression and the columns which are used in it
+func getWhere(where string) (string, []string, error) {
+	if strings.HasPrefix(where, `{`) {
+		inWhere, _, err := parseObject([]rune(where))
+		if err != nil {
+			return ``, nil, err
+		}
+		var whereMap *types.Map
+		switch v := inWhere.(type) {
+		case string:
+			if len(v) == 0 {
+				return `true`, nil, nil
+			}
+		case map[string]interface{}:
+			whereMap = types.LoadMap(v)
+		case *types.Map:
+			whereMap = v
+		}
+		if whereMap == nil {
+			return ``, nil, errWhere
+		}
+		// the values come from page parameters so they must be quoted
+		qb.QuoteInValues(whereMap)
+		cond, err := qb.GetWhere(whereMap)
+		return cond, qb.WhereColumns(whereMap), err
+	} else if len(where) > 0 {
+		return ``, nil, errWhere
+	}
+	return where, nil, nil
+}
+
 func dbfindTag(par parFunc) string {
 	var (
 		inColumns interface{}
@@ -590,34 +646,9 @@ func dbfindTag(par parFunc) string {
 		return err.Error()
 	}
 	if par.Node.Attr[`where`] != nil {
-		where = macro(par.Node.Attr[`where`].(string), par.Workspace.Vars)
-		if strings.HasPrefix(where, `{`) {
-			inWhere, _, err := parseObject([]rune(where))
-			if err != nil {
-				return err.Error()
-			}
-			switch v := inWhere.(type) {
-			case string:
-				if len(v) == 0 {
-					where = `true`
-				} else {
-					return errWhere.Error()
-				}
-			case map[string]interface{}:
-				where, err = qb.GetWhere(types.LoadMap(v))
-				if err != nil {
-					return err.Error()
-				}
-			case *types.Map:
-				where, err = qb.GetWhere(v)
-				if err != nil {
-					return err.Error()
-				}
-			default:
-				return errWhere.Error()
-			}
-		} else if len(where) > 0 {
-			return errWhere.Error()
+		where, _, err = getWhere(macro(par.Node.Attr[`where`].(string), par.Workspace.Vars))
+		if err != nil {
+			return err.Error()
 		}
 	}
 	if par.Node.Attr[`whereid`] != nil {
@@ -633,6 +664,12 @@ func dbfindTag(par parFunc) string {
 		offset = fmt.Sprintf(` offset %d`, converter.StrToInt(par.Node.Attr[`offset`].(string)))
 	}
 
+	paginator := par.Workspace.Paginator(macro((*par.Pars)[`Source`], par.Workspace.Vars))
+	if paginator != nil {
+		limit = converter.StrToInt(paginator.Attr[`pagesize`].(string))
+		offset = fmt.Sprintf(` offset %d`, converter.StrToInt(paginator.Attr[`offset`].(string)))
+	}
+
 	if par.Node.Attr[`prefix`] != nil {
 		prefix = par.Node.Attr[`prefix`].(string)
 		limit = 1
@@ -647,6 +684,7 @@ func dbfindTag(par parFunc) string {
 	sc := par.Workspace.SmartContract
 	tblname := converter.ParseTable(strings.Trim(macro((*par.Pars)[`Name`], par.Workspace.Vars), `"`), state)
 	tblname = strings.ToLower(tblname)
+	par.Workspace.readTable(tblname)
 
 	inColumns = ``
 	if par.Node.Attr[`order`] != nil {
@@ -704,7 +742,7 @@ func dbfindTag(par parFunc) string {
 			extendedColumns[col] = columnTypeBlob
 			queryColumns[i] = dbfindExpressionBlob(col)
 			break
-		case "text", "varchar", "character varying":
+		case "text", "richtext", "varchar", "character varying":
 			if cutoffColumns[col] {
 				extendedColumns[col] = columnTypeLongText
 				queryColumns[i] = dbfindExpressionLongText(col)
@@ -723,16 +761,23 @@ func dbfindTag(par parFunc) string {
 		}
 		columnNames[i] = strings.TrimSpace(columnNames[i])
 	}
-	if par.Node.Attr[`countvar`] != nil {
+	if par.Node.Attr[`countvar`] != nil || paginator != nil {
 		var count int64
 		err = model.GetDB(nil).Table(tblname).Where(where).Count(&count).Error
 		if err != nil {
 			log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("selecting count from table in DBFind")
 		}
 		countStr := converter.Int64ToStr(count)
-		par.Node.Attr[`count`] = countStr
-		setVar(par.Workspace, par.Node.Attr[`countvar`].(string), countStr)
-		delete(par.Node.Attr, `countvar`)
+		if par.Node.Attr[`countvar`] != nil {
+			par.Node.Attr[`count`] = countStr
+			setVar(par.Workspace, par.Node.Attr[`countvar`].(string), countStr)
+			delete(par.Node.Attr, `countvar`)
+		}
+		if paginator != nil {
+			pageSize := int64(limit)
+			paginator.Attr[`total`] = countStr
+			paginator.Attr[`pages`] = converter.Int64ToStr((count + pageSize - 1) / pageSize)
+		}
 	}
 	if len(where) > 0 {
 		where = ` where ` + where
@@ -829,6 +874,7 @@ func dbfindTag(par parFunc) string {
 			}
 			result[i] = reflect.ValueOf(row).Interface()
 		}
+		par.Workspace.noCache()
 		fltResult, err := smart.VMEvalIf(sc.VM, perm[`filter`], uint32(sc.TxSmart.EcosystemID),
 			&map[string]interface{}{
 				`data`:         result,
@@ -856,6 +902,41 @@ func dbfindTag(par parFunc) string {
 	return ``
 }
 
+// paginatorTag reads the current page from the page parameter and binds the paginator to the source.
+// DBFind with this source must follow the paginator, it fills the total number of rows and pages
+func paginatorTag(par parFunc) string {
+	setAllAttr(par)
+	source := macro((*par.Pars)[`Source`], par.Workspace.Vars)
+	if len(source) == 0 {
+		return ``
+	}
+	pageSize := converter.StrToInt64(macro((*par.Pars)[`PageSize`], par.Workspace.Vars))
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+	if pageSize > consts.DBFindLimit {
+		pageSize = consts.DBFindLimit
+	}
+	pageParam := macro((*par.Pars)[`PageParam`], par.Workspace.Vars)
+	if len(pageParam) == 0 {
+		pageParam = defaultPageParam
+	}
+	page := converter.StrToInt64(getVar(par.Workspace, pageParam))
+	if page < 1 {
+		page = 1
+	}
+	par.Node.Attr[`source`] = source
+	par.Node.Attr[`pagesize`] = converter.Int64ToStr(pageSize)
+	par.Node.Attr[`pageparam`] = pageParam
+	par.Node.Attr[`page`] = converter.Int64ToStr(page)
+	par.Node.Attr[`offset`] = converter.Int64ToStr((page - 1) * pageSize)
+	par.Node.Attr[`total`] = `0`
+	par.Node.Attr[`pages`] = `0`
+	par.Workspace.SetPaginator(source, par.Node)
+	par.Owner.Children = append(par.Owner.Children, par.Node)
+	return ``
+}
+
 func compositeTag(par parFunc) string {
 	setAllAttr(par)
 	if len((*par.Pars)[`Name`]) == 0 {
@@ -963,10 +1044,52 @@ func hideTag(par parFunc) string {
 	return showHideTag(par, `hide`)
 }
 
+// includeParams contains the parameters which have been passed to the included block
+type includeParams struct {
+	Args  []string
+	Named map[string]string
+}
+
+// getIncludeParams returns the name of the block and its positional and named parameters
+func getIncludeParams(par parFunc) (string, *includeParams) {
+	var (
+		name  string
+		start int
+	)
+	params := &includeParams{Named: make(map[string]string)}
+	if name = (*par.Pars)[`Name`]; len(name) == 0 {
+		name = (*par.Pars)[`0`]
+		start = 1
+	}
+	for i := start; i < len(*par.Pars); i++ {
+		val, ok := (*par.Pars)[strconv.Itoa(i)]
+		if !ok {
+			continue
+		}
+		params.Args = append(params.Args, macro(val, par.Workspace.Vars))
+	}
+	for key, val := range *par.Pars {
+		if key == `Name` || isDigits(key) {
+			continue
+		}
+		params.Named[key] = val
+	}
+	return macro(name, par.Workspace.Vars), params
+}
+
+func isDigits(key string) bool {
+	for _, ch := range key {
+		if ch < '0' || ch > '9' {
+			return false
+		}
+	}
+	return len(key) > 0
+}
+
 func includeTag(par parFunc) string {
-	if len((*par.Pars)[`Name`]) >= 0 && len(getVar(par.Workspace, `_include`)) < 5 {
+	if len(getVar(par.Workspace, `_include`)) < 5 {
 		bi := &model.BlockInterface{}
-		name := macro((*par.Pars)[`Name`], par.Workspace.Vars)
+		name, params := getIncludeParams(par)
 		ecosystem, tblname := converter.ParseName(name)
 		prefix := getVar(par.Workspace, `ecosystem_id`)
 		if ecosystem != 0 {
@@ -974,21 +1097,47 @@ func includeTag(par parFunc) string {
 			name = tblname
 		}
 		bi.SetTablePrefix(prefix)
+		par.Workspace.readTable(bi.TableName())
 		found, err := bi.Get(name)
 		if err != nil {
 			log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting block by name")
 			return err.Error()
 		}
 		if !found {
-			log.WithFields(log.Fields{"type": consts.NotFound, "name": (*par.Pars)[`Name`]}).Error("include block not found")
-			return fmt.Sprintf("Inlcude %s has not been found", (*par.Pars)[`Name`])
+			log.WithFields(log.Fields{"type": consts.NotFound, "name": name}).Error("include block not found")
+			return fmt.Sprintf("Inlcude %s has not been found", name)
 		}
+		par.Workspace.checkPersonal(bi.Value)
 		if len(bi.Value) > 0 {
+			var (
+				vars    map[string]Var
+				include *includeParams
+			)
 			root := node{}
+			// The block gets its own variables if it has parameters. Otherwise, it shares
+			// the variables of the page as before.
+			scoped := len(params.Args) > 0 || len(params.Named) > 0 ||
+				strings.Contains(bi.Value, `Params(`)
+			if scoped {
+				vars = make(map[string]Var, len(*par.Workspace.Vars))
+				for key, val := range *par.Workspace.Vars {
+					vars[key] = val
+				}
+				include = par.Workspace.include
+				par.Workspace.include = params
+				for key, val := range params.Named {
+					setVar(par.Workspace, key, val)
+				}
+			}
 			setVar(par.Workspace, `_include`, getVar(par.Workspace, `_include`)+`1`)
 			process(bi.Value, &root, par.Workspace)
-			include := getVar(par.Workspace, `_include`)
-			setVar(par.Workspace, `_include`, include[:len(include)-1])
+			if scoped {
+				*par.Workspace.Vars = vars
+				par.Workspace.include = include
+			} else {
+				level := getVar(par.Workspace, `_include`)
+				setVar(par.Workspace, `_include`, level[:len(level)-1])
+			}
 			for _, item := range root.Children {
 				par.Owner.Children = append(par.Owner.Children, item)
 			}
@@ -997,6 +1146,75 @@ func includeTag(par parFunc) string {
 	return ``
 }
 
+func includeFull(par parFunc) string {
+	name, params := getIncludeParams(par)
+	par.Node.Attr[`name`] = name
+	if len(params.Args) > 0 {
+		par.Node.Attr[`args`] = params.Args
+	}
+	if len(params.Named) > 0 {
+		named := make(map[string]interface{})
+		for key, val := range params.Named {
+			named[key] = val
+		}
+		par.Node.Attr[`params`] = named
+	}
+	par.Owner.Children = append(par.Owner.Children, par.Node)
+	return ``
+}
+
+// paramsTag declares the parameters of the included block. Each parameter is a name with
+// an optional type int, float or text, for example Params(Title, Count int)
+func paramsTag(par parFunc) string {
+	include := par.Workspace.include
+	for i := 0; i < len(*par.Pars); i++ {
+		fields := strings.Fields((*par.Pars)[strconv.Itoa(i)])
+		if len(fields) == 0 {
+			continue
+		}
+		name := fields[0]
+		if include != nil {
+			if _, ok := include.Named[name]; !ok {
+				var value string
+				if i < len(include.Args) {
+					value = include.Args[i]
+				}
+				setVar(par.Workspace, name, value)
+			}
+		}
+		if len(fields) == 1 {
+			continue
+		}
+		value := getVar(par.Workspace, name)
+		switch fields[1] {
+		case `int`:
+			if _, err := strconv.ParseInt(value, 10, 64); err != nil && len(value) > 0 {
+				return fmt.Sprintf(`Parameter %s must be int`, name)
+			}
+		case `float`:
+			if _, err := strconv.ParseFloat(value, 64); err != nil && len(value) > 0 {
+				return fmt.Sprintf(`Parameter %s must be float`, name)
+			}
+		case `text`:
+		default:
+			return fmt.Sprintf(`Parameter %s has unknown type %s`, name, fields[1])
+		}
+	}
+	return ``
+}
+
+func paramsFull(par parFunc) string {
+	names := make([]string, 0, len(*par.Pars))
+	for i := 0; i < len(*par.Pars); i++ {
+		if val := strings.TrimSpace((*par.Pars)[strconv.Itoa(i)]); len(val) > 0 {
+			names = append(names, val)
+		}
+	}
+	par.Node.Attr[`names`] = names
+	par.Owner.Children = append(par.Owner.Children, par.Node)
+	return ``
+}
+
 func setvarTag(par parFunc) string {
 	if len((*par.Pars)[`Name`]) > 0 {
 		if strings.ContainsAny((*par.Pars)[`Value`], `({`) {
@@ -1321,7 +1539,7 @@ func arraytosourceTag(par parFunc) string {
 }
 
 func chartTag(par parFunc) string {
-	defaultTag(par)
+	setAllAttr(par)
 	defaultTail(par, "chart")
 
 	if len((*par.Pars)["Colors"]) > 0 {
@@ -1331,7 +1549,20 @@ func chartTag(par parFunc) string {
 		}
 		par.Node.Attr["colors"] = colors
 	}
-
+	if chartType := macro((*par.Pars)["Type"], par.Workspace.Vars); strings.HasPrefix(chartType, chartStacked) {
+		par.Node.Attr["type"] = strings.TrimPrefix(chartType, chartStacked)
+		par.Node.Attr["stacked"] = "true"
+	}
+	if par.Node.Attr["table"] != nil {
+		data, err := chartAggregate(par)
+		if err != nil {
+			return err.Error()
+		}
+		par.Owner.Children = append(par.Owner.Children, data)
+	} else if values := chartFields(macro((*par.Pars)["FieldValue"], par.Workspace.Vars)); len(values) > 1 {
+		par.Node.Attr["series"] = values
+	}
+	par.Owner.Children = append(par.Owner.Children, par.Node)
 	return ""
 }
 
@@ -1344,6 +1575,9 @@ func rangeTag(par parFunc) string {
 	if len((*par.Pars)["Step"]) > 0 {
 		step = converter.StrToInt64(macro((*par.Pars)["Step"], par.Workspace.Vars))
 	}
+	if step != 0 && !par.Workspace.charge((to-from)/step*renderRowCost) {
+		return ``
+	}
 	if step > 0 && from < to {
 		for i := from; i < to; i += step {
 			data = append(data, []string{converter.Int64ToStr(i)})
@@ -1381,6 +1615,7 @@ func binaryTag(par parFunc) string {
 	}
 	binary := &model.Binary{}
 	binary.SetTablePrefix(ecosystemID)
+	par.Workspace.readTable(binary.TableName())
 
 	var (
 		ok  bool
@@ -1433,6 +1668,8 @@ func getHistoryTag(par parFunc) string {
 		return ``
 	}
 	table := macro((*par.Pars)["Name"], par.Workspace.Vars)
+	par.Workspace.readTable(converter.ParseTable(table,
+		converter.StrToInt64(getVar(par.Workspace, `ecosystem_id`))))
 	list, err := smart.GetHistoryRaw(nil, converter.StrToInt64(getVar(par.Workspace, `ecosystem_id`)),
 		table, converter.StrToInt64(macro((*par.Pars)[`Id`], par.Workspace.Vars)), rollID)
 	if err != nil {
@@ -0,0 +1,353 @@
+// Copyright (C) 2017, 2018, 2019 EGAAS S.A.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or (at
+// your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but
+// WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301, USA.
+
+package template
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"net/url"
+	"strings"
+
+	"github.com/AplaProject/go-apla/packages/consts"
+	"github.com/AplaProject/go-apla/packages/smart"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// renderCallCost is the fuel of the function call in the template which is rendered by the contract
+	renderCallCost = 10
+	// renderRowCost is the fuel of the row of Range and of the iteration of ForList
+	renderRowCost = 1
+	// renderByteCost is the fuel of the byte of the variable and of the result
+	renderByteCost = 1
+)
+
+var (
+	errRenderFunc = `function %s cannot be used in the contract`
+	errRenderFuel = errors.New(`paid CPU resource is over`)
+)
+
+// contractFuncs are the functions which can be used in the templates rendered by contracts.
+// They don't read the database, the network and the current time so the result is the same
+// on every node
+var contractFuncs = map[string]bool{
+	`Address`: true, `AddressToId`: true, `And`: true, `ArrayToSource`: true, `Button`: true,
+	`Calculate`: true, `Code`: true, `CodeAsIs`: true, `Data`: true, `Div`: true, `Em`: true,
+	`ForList`: true, `Form`: true, `GetVar`: true, `Hint`: true, `If`: true, `Image`: true,
+	`Input`: true, `InputErr`: true, `JsonToSource`: true, `Label`: true, `LinkPage`: true,
+	`Lower`: true, `Markdown`: true, `Money`: true, `Or`: true, `P`: true, `QRcode`: true,
+	`RadioGroup`: true, `Range`: true, `Select`: true, `SetTitle`: true, `SetVar`: true,
+	`Span`: true, `Strong`: true, `Table`: true, `VarAsIs`: true,
+}
+
+// renderBudget accounts the fuel of the template which is rendered by the contract
+type renderBudget struct {
+	cost  int64
+	limit int64
+	err   error
+}
+
+// allowFunc checks that the function can be used and charges its call
+func (w *Workspace) allowFunc(name string) bool {
+	if w.budget == nil {
+		return true
+	}
+	if !contractFuncs[name] {
+		w.budget.err = fmt.Errorf(errRenderFunc, name)
+		*w.Timeout = true
+		return false
+	}
+	return w.charge(renderCallCost)
+}
+
+// charge adds the cost to the fuel of the template and stops processing when the limit is exceeded
+func (w *Workspace) charge(cost int64) bool {
+	if w.budget == nil {
+		return true
+	}
+	if w.budget.err != nil {
+		return false
+	}
+	w.budget.cost += cost
+	if cost < 0 || w.budget.cost > w.budget.limit {
+		w.budget.err = errRenderFuel
+		*w.Timeout = true
+		return false
+	}
+	return true
+}
+
+func init() {
+	smart.TemplateRenderer = func(input string, vars map[string]string, limit int64) ([]byte, int64, error) {
+		return ContractHTML(input, vars, limit)
+	}
+}
+
+// ContractHTML converts the template to HTML for the contract. Only the functions of contractFuncs
+// are allowed and the function calls, iterations and bytes of the result are charged.
+// It returns the HTML and its cost
+func ContractHTML(input string, vars map[string]string, limit int64) ([]byte, int64, error) {
+	var timeout bool
+	budget := &renderBudget{limit: limit}
+	tree := template2JSON(input, &timeout, &vars, nil, budget)
+	if budget.err != nil {
+		return nil, budget.cost, budget.err
+	}
+	out := JSON2HTML(tree)
+	budget.cost += int64(len(out)) * renderByteCost
+	if budget.cost > budget.limit {
+		return nil, budget.cost, errRenderFuel
+	}
+	return out, budget.cost, nil
+}
+
+type htmlSource struct {
+	Columns []string
+	Data    [][]string
+}
+
+type htmlRenderer struct {
+	buf     bytes.Buffer
+	sources map[string]*htmlSource
+}
+
+// Template2HTML converts templates to HTML
+func Template2HTML(input string, timeout *bool, vars *map[string]string) []byte {
+	return JSON2HTML(Template2JSON(input, timeout, vars))
+}
+
+// JSON2HTML converts the tree of the template to HTML. All text and values of attributes are escaped
+func JSON2HTML(tree []byte) []byte {
+	var nodes []*node
+	if err := json.Unmarshal(tree, &nodes); err != nil {
+		log.WithFields(log.Fields{"type": consts.JSONUnmarshallError, "error": err}).Error("unmarshalling template tree")
+		return []byte(html.EscapeString(string(tree)))
+	}
+	r := &htmlRenderer{sources: make(map[string]*htmlSource)}
+	r.collect(nodes)
+	r.render(nodes)
+	return r.buf.Bytes()
+}
+
+func attrString(n *node, key string) string {
+	if val, ok := n.Attr[key].(string); ok {
+		return val
+	}
+	return ``
+}
+
+func toStrings(in interface{}) []string {
+	list, _ := in.([]interface{})
+	ret := make([]string, 0, len(list))
+	for _, item := range list {
+		val, _ := item.(string)
+		ret = append(ret, val)
+	}
+	return ret
+}
+
+// collect gets the data of DBFind, Data and other sources of the tree
+func (r *htmlRenderer) collect(nodes []*node) {
+	for _, n := range nodes {
+		if source := attrString(n, `source`); len(source) > 0 && n.Attr[`data`] != nil {
+			if _, ok := n.Attr[`data`].([]interface{}); ok {
+				item := &htmlSource{Columns: toStrings(n.Attr[`columns`])}
+				for _, row := range n.Attr[`data`].([]interface{}) {
+					item.Data = append(item.Data, toStrings(row))
+				}
+				r.sources[source] = item
+			}
+		}
+		r.collect(n.Children)
+	}
+}
+
+func (r *htmlRenderer) open(tag string, attrs ...string) {
+	r.buf.WriteString(`<` + tag)
+	for i := 0; i+1 < len(attrs); i += 2 {
+		if len(attrs[i+1]) > 0 {
+			r.buf.WriteString(` ` + attrs[i] + `="` + html.EscapeString(attrs[i+1]) + `"`)
+		}
+	}
+	r.buf.WriteString(`>`)
+}
+
+func (r *htmlRenderer) element(tag string, n *node, attrs ...string) {
+	r.open(tag, attrs...)
+	r.render(n.Children)
+	r.buf.WriteString(`</` + tag + `>`)
+}
+
+// safeURL returns an empty string for the links with scripts
+func safeURL(link string) string {
+	link = strings.TrimSpace(link)
+	if off := strings.IndexAny(link, `:/?#`); off >= 0 && link[off] == ':' {
+		switch scheme := strings.ToLower(link[:off]); scheme {
+		case `http`, `https`, `mailto`:
+		case `data`:
+			if !strings.HasPrefix(strings.ToLower(link), `data:image/`) {
+				return ``
+			}
+		default:
+			return ``
+		}
+	}
+	return link
+}
+
+func pageLink(n *node) string {
+	page := attrString(n, `page`)
+	if len(page) == 0 {
+		return ``
+	}
+	link := `/page/` + url.PathEscape(page)
+	params, _ := n.Attr[`pageparams`].(map[string]interface{})
+	query := url.Values{}
+	for key, val := range params {
+		if par, ok := val.(map[string]interface{}); ok && par[`type`] == `text` {
+			text, _ := par[`text`].(string)
+			query.Set(key, text)
+		}
+	}
+	if len(query) > 0 {
+		link += `?` + query.Encode()
+	}
+	return link
+}
+
+func (r *htmlRenderer) render(nodes []*node) {
+	for _, n := range nodes {
+		switch n.Tag {
+		case tagText:
+			r.buf.WriteString(html.EscapeString(n.Text))
+		case `div`, `p`, `span`, `em`, `strong`, `form`:
+			r.element(n.Tag, n, `class`, attrString(n, `class`))
+		case `label`:
+			r.element(`label`, n, `class`, attrString(n, `class`), `for`, attrString(n, `for`))
+		case `image`:
+			r.open(`img`, `src`, safeURL(attrString(n, `src`)), `alt`, attrString(n, `alt`),
+				`class`, attrString(n, `class`))
+		case `input`:
+			disabled := attrString(n, `disabled`)
+			if disabled == `true` || disabled == `1` {
+				disabled = `disabled`
+			} else {
+				disabled = ``
+			}
+			r.open(`input`, `type`, attrString(n, `type`), `name`, attrString(n, `name`),
+				`value`, attrString(n, `value`), `placeholder`, attrString(n, `placeholder`),
+				`class`, attrString(n, `class`), `disabled`, disabled)
+		case `select`:
+			r.renderSelect(n)
+		case `table`:
+			r.renderTable(n)
+		case `code`:
+			r.open(`code`, `class`, attrString(n, `class`))
+			r.buf.WriteString(html.EscapeString(attrString(n, `text`)) + `</code>`)
+		case `linkpage`:
+			r.element(`a`, n, `href`, pageLink(n), `class`, attrString(n, `class`))
+		case `button`:
+			r.element(`button`, n, `type`, `button`, `class`, attrString(n, `class`),
+				`data-page`, attrString(n, `page`), `data-contract`, attrString(n, `contract`))
+		case `dbfind`, `data`, `jsontosource`, `arraytosource`, `httpsource`:
+		default:
+			r.render(n.Children)
+		}
+	}
+}
+
+func columnIndex(columns []string, name string) int {
+	for i, col := range columns {
+		if col == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func (r *htmlRenderer) renderSelect(n *node) {
+	r.open(`select`, `name`, attrString(n, `name`), `class`, attrString(n, `class`))
+	if source, ok := r.sources[attrString(n, `source`)]; ok {
+		nameColumn, valueColumn := attrString(n, `namecolumn`), attrString(n, `valuecolumn`)
+		if len(nameColumn) == 0 {
+			nameColumn = `name`
+		}
+		if len(valueColumn) == 0 {
+			valueColumn = `id`
+		}
+		nameIndex := columnIndex(source.Columns, nameColumn)
+		valueIndex := columnIndex(source.Columns, valueColumn)
+		for _, row := range source.Data {
+			var name, value string
+			if nameIndex >= 0 && nameIndex < len(row) {
+				name = row[nameIndex]
+			}
+			if valueIndex >= 0 && valueIndex < len(row) {
+				value = row[valueIndex]
+			}
+			var selected string
+			if value == attrString(n, `value`) {
+				selected = `selected`
+			}
+			r.open(`option`, `value`, value, `selected`, selected)
+			r.buf.WriteString(html.EscapeString(name) + `</option>`)
+		}
+	}
+	r.buf.WriteString(`</select>`)
+}
+
+func (r *htmlRenderer) renderTable(n *node) {
+	source, ok := r.sources[attrString(n, `source`)]
+	if !ok {
+		return
+	}
+	var titles, names []string
+	if columns, ok := n.Attr[`columns`].([]interface{}); ok {
+		for _, item := range columns {
+			col, _ := item.(map[string]interface{})
+			title, _ := col[`Title`].(string)
+			name, _ := col[`Name`].(string)
+			titles = append(titles, title)
+			names = append(names, name)
+		}
+	} else {
+		titles = source.Columns
+		names = source.Columns
+	}
+	r.open(`table`, `class`, attrString(n, `class`))
+	r.buf.WriteString(`<thead><tr>`)
+	for _, title := range titles {
+		r.buf.WriteString(`<th>` + html.EscapeString(title) + `</th>`)
+	}
+	r.buf.WriteString(`</tr></thead><tbody>`)
+	for _, row := range source.Data {
+		r.buf.WriteString(`<tr>`)
+		for _, name := range names {
+			var val string
+			if i := columnIndex(source.Columns, name); i >= 0 && i < len(row) {
+				val = row[i]
+			}
+			r.buf.WriteString(`<td>` + html.EscapeString(val) + `</td>`)
+		}
+		r.buf.WriteString(`</tr>`)
+	}
+	r.buf.WriteString(`</tbody></table>`)
+}
@@ -0,0 +1,382 @@
+// Copyright (C) 2017, 2018, 2019 EGAAS S.A.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or (at
+// your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but
+// WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301, USA.
+
+package template
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AplaProject/go-apla/packages/conf"
+	"github.com/AplaProject/go-apla/packages/consts"
+	"github.com/AplaProject/go-apla/packages/types"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	httpSourceMaxSize      = 10 << 20 // maximum size of the response
+	httpSourceCacheSize    = 256      // maximum number of cached responses
+	httpSourceMaxRedirects = 5        // maximum number of redirects of the request
+
+	httpSourceValue = `value`
+)
+
+var (
+	errHTTPSourceOBS      = errors.New(`HTTPSource is available only in OBS mode`)
+	errHTTPSourceURL      = errors.New(`HTTPSource url is not allowed`)
+	errHTTPSourcePath     = errors.New(`HTTPSource path is wrong`)
+	errHTTPSourceSize     = errors.New(`HTTPSource response is too large`)
+	errHTTPSourceHeads    = errors.New(`HTTPSource headers must be an object`)
+	errHTTPSourceRedirect = errors.New(`HTTPSource has too many redirects`)
+
+	httpSourceCache = struct {
+		sync.Mutex
+		items map[string]httpSourceItem
+	}{items: make(map[string]httpSourceItem)}
+)
+
+type httpSourceItem struct {
+	data    []byte
+	expires time.Time
+}
+
+// httpSourceAllowed checks that the url starts with one of the allowed prefixes of the config
+func httpSourceAllowed(link string) bool {
+	u, err := url.Parse(link)
+	if err != nil || (u.Scheme != `http` && u.Scheme != `https`) || len(u.Host) == 0 {
+		return false
+	}
+	// the path is cleaned so that http://host/api/../admin doesn't match http://host/api/
+	cleanPath := u.Path
+	if len(cleanPath) > 0 {
+		cleanPath = path.Clean(cleanPath)
+		if strings.HasSuffix(u.Path, `/`) && cleanPath != `/` {
+			cleanPath += `/`
+		}
+	}
+	link = u.Scheme + `://` + strings.ToLower(u.Host) + cleanPath
+	if len(u.RawQuery) > 0 {
+		link += `?` + u.RawQuery
+	}
+	for _, prefix := range conf.Config.HTTPSource.AllowedURLs {
+		if !strings.HasPrefix(link, prefix) {
+			continue
+		}
+		// http://host must not allow http://host.other.com
+		if len(link) == len(prefix) || strings.HasSuffix(prefix, `/`) ||
+			strings.IndexByte(`/?#`, link[len(prefix)]) >= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// httpSourceHeaders parses the object with the headers of the request
+func httpSourceHeaders(input string) (map[string]string, error) {
+	headers := make(map[string]string)
+	if len(strings.TrimSpace(input)) == 0 {
+		return headers, nil
+	}
+	obj, _, err := parseObject([]rune(input))
+	if err != nil {
+		return nil, err
+	}
+	switch v := obj.(type) {
+	case map[string]interface{}:
+		for key, val := range v {
+			headers[key] = fmt.Sprint(val)
+		}
+	case *types.Map:
+		for _, key := range v.Keys() {
+			val, _ := v.Get(key)
+			headers[key] = fmt.Sprint(val)
+		}
+	default:
+		return nil, errHTTPSourceHeads
+	}
+	return headers, nil
+}
+
+// httpSourceGet returns the response from the cache or sends GET request
+func httpSourceGet(link string, headers map[string]string) ([]byte, error) {
+	keys := make([]string, 0, len(headers))
+	for key := range headers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	cacheKey := link
+	for _, key := range keys {
+		cacheKey += "\n" + key + `:` + headers[key]
+	}
+	now := time.Now()
+	httpSourceCache.Lock()
+	item, ok := httpSourceCache.items[cacheKey]
+	httpSourceCache.Unlock()
+	if ok && now.Before(item.expires) {
+		return item.data, nil
+	}
+
+	req, err := http.NewRequest(`GET`, link, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, val := range headers {
+		req.Header.Set(key, val)
+	}
+	client := &http.Client{
+		Timeout: time.Duration(conf.Config.HTTPSource.Timeout) * time.Second,
+		// every redirect must lead to the allowed url too
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= httpSourceMaxRedirects {
+				return errHTTPSourceRedirect
+			}
+			if !httpSourceAllowed(req.URL.String()) {
+				return errHTTPSourceURL
+			}
+			return nil
+		},
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.NetworkError, "error": err, "url": link}).Error("HTTPSource request")
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, httpSourceMaxSize+1))
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.IOError, "error": err, "url": link}).Error("reading HTTPSource answer")
+		return nil, err
+	}
+	if len(data) > httpSourceMaxSize {
+		return nil, errHTTPSourceSize
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf(`%d %s`, resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+	if ttl := conf.Config.HTTPSource.CacheTTL; ttl > 0 {
+		httpSourceCache.Lock()
+		if len(httpSourceCache.items) >= httpSourceCacheSize {
+			for key, item := range httpSourceCache.items {
+				if !now.Before(item.expires) {
+					delete(httpSourceCache.items, key)
+				}
+			}
+		}
+		if len(httpSourceCache.items) < httpSourceCacheSize {
+			httpSourceCache.items[cacheKey] = httpSourceItem{data: data,
+				expires: now.Add(time.Duration(ttl) * time.Second)}
+		}
+		httpSourceCache.Unlock()
+	}
+	return data, nil
+}
+
+// jsonPath selects the values by the path like $.data.items[*].name or items[0]
+func jsonPath(value interface{}, path string) (interface{}, error) {
+	path = strings.TrimPrefix(strings.TrimSpace(path), `$`)
+	values := []interface{}{value}
+	var list bool
+	for len(path) > 0 {
+		var (
+			key   string
+			index = -1
+			all   bool
+		)
+		switch path[0] {
+		case '.':
+			path = path[1:]
+			end := strings.IndexAny(path, `.[`)
+			if end < 0 {
+				end = len(path)
+			}
+			key, path = path[:end], path[end:]
+			if key == `*` {
+				all, key = true, ``
+			}
+		case '[':
+			end := strings.IndexByte(path, ']')
+			if end < 0 {
+				return nil, errHTTPSourcePath
+			}
+			sel := strings.TrimSpace(path[1:end])
+			path = path[end+1:]
+			if sel == `*` {
+				all = true
+			} else if i, err := strconv.Atoi(sel); err == nil {
+				index = i
+			} else {
+				key = strings.Trim(sel, `'"`)
+			}
+		default:
+			end := strings.IndexAny(path, `.[`)
+			if end < 0 {
+				end = len(path)
+			}
+			key, path = path[:end], path[end:]
+		}
+		next := make([]interface{}, 0, len(values))
+		for _, item := range values {
+			switch v := item.(type) {
+			case map[string]interface{}:
+				if all {
+					keys := make([]string, 0, len(v))
+					for k := range v {
+						keys = append(keys, k)
+					}
+					sort.Strings(keys)
+					for _, k := range keys {
+						next = append(next, v[k])
+					}
+				} else if val, ok := v[key]; ok && len(key) > 0 {
+					next = append(next, val)
+				}
+			case []interface{}:
+				if all {
+					next = append(next, v...)
+				} else if index >= 0 && index < len(v) {
+					next = append(next, v[index])
+				}
+			}
+		}
+		list = list || all
+		values = next
+	}
+	if list {
+		return values, nil
+	}
+	if len(values) == 0 {
+		return nil, nil
+	}
+	return values[0], nil
+}
+
+func httpSourceString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ``
+	case string:
+		return v
+	case map[string]interface{}, []interface{}:
+		out, _ := json.Marshal(v)
+		return string(out)
+	}
+	return fmt.Sprint(value)
+}
+
+// httpSourceData converts the selected JSON value into the columns and the rows of the source.
+// The array of objects gives a row for each object, the object gives one row
+// and the scalar values are put in value column
+func httpSourceData(value interface{}) ([]string, [][]string) {
+	var items []interface{}
+	switch v := value.(type) {
+	case []interface{}:
+		items = v
+	case nil:
+	default:
+		items = []interface{}{v}
+	}
+	cols := make([]string, 0)
+	index := make(map[string]int)
+	addColumn := func(name string) {
+		if _, ok := index[name]; !ok {
+			index[name] = len(cols)
+			cols = append(cols, name)
+		}
+	}
+	for _, item := range items {
+		if obj, ok := item.(map[string]interface{}); ok {
+			keys := make([]string, 0, len(obj))
+			for key := range obj {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				addColumn(key)
+			}
+		} else {
+			addColumn(httpSourceValue)
+		}
+	}
+	data := make([][]string, 0, len(items))
+	for _, item := range items {
+		row := make([]string, len(cols))
+		if obj, ok := item.(map[string]interface{}); ok {
+			for key, val := range obj {
+				row[index[key]] = httpSourceString(val)
+			}
+		} else {
+			row[index[httpSourceValue]] = httpSourceString(item)
+		}
+		data = append(data, row)
+	}
+	return cols, data
+}
+
+// httpSourceTag requests JSON data from the allowed url and creates the source from it
+func httpSourceTag(par parFunc) string {
+	setAllAttr(par)
+	if !conf.Config.IsSupportingOBS() {
+		return errHTTPSourceOBS.Error()
+	}
+	link := macro((*par.Pars)[`Url`], par.Workspace.Vars)
+	if !httpSourceAllowed(link) {
+		return errHTTPSourceURL.Error()
+	}
+	headers, err := httpSourceHeaders(macro((*par.Pars)[`Headers`], par.Workspace.Vars))
+	if err != nil {
+		return err.Error()
+	}
+	par.Workspace.noCache()
+	out, err := httpSourceGet(link, headers)
+	if err != nil {
+		return err.Error()
+	}
+	var value interface{}
+	if err = json.Unmarshal(out, &value); err != nil {
+		return err.Error()
+	}
+	if path := macro((*par.Pars)[`Path`], par.Workspace.Vars); len(path) > 0 {
+		if value, err = jsonPath(value, path); err != nil {
+			return err.Error()
+		}
+	}
+	cols, data := httpSourceData(value)
+	types := make([]string, len(cols))
+	for i := range types {
+		types[i] = columnTypeText
+	}
+	// the url and the headers can contain the credentials of the service
+	delete(par.Node.Attr, `url`)
+	delete(par.Node.Attr, `headers`)
+	par.Node.Attr[`columns`] = &cols
+	par.Node.Attr[`types`] = &types
+	par.Node.Attr[`data`] = &data
+	newSource(par)
+	par.Owner.Children = append(par.Owner.Children, par.Node)
+	return ``
+}
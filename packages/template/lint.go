@@ -0,0 +1,350 @@
+// Copyright (C) 2017, 2018, 2019 EGAAS S.A.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or (at
+// your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but
+// WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301, USA.
+
+package template
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/AplaProject/go-apla/packages/consts"
+	"github.com/AplaProject/go-apla/packages/converter"
+	"github.com/AplaProject/go-apla/packages/model"
+	"github.com/AplaProject/go-apla/packages/smart"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// LintError describes the problem which has been found in the source of the template
+type LintError struct {
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Message string `json:"message"`
+}
+
+func (e LintError) Error() string {
+	return fmt.Sprintf(`%d:%d: %s`, e.Line, e.Column, e.Message)
+}
+
+func init() {
+	smart.TemplateLinter = func(transaction *model.DbTransaction, input string, ecosystemID int64) ([]error, error) {
+		errs, err := Lint(transaction, input, ecosystemID)
+		if err != nil {
+			return nil, err
+		}
+		var ret []error
+		for _, item := range errs {
+			ret = append(ret, item)
+		}
+		return ret, nil
+	}
+}
+
+type linter struct {
+	input       []rune
+	ecosystem   int64
+	transaction *model.DbTransaction
+	errors      []LintError
+	dbErr       error
+}
+
+// Lint checks the source of the template. It reports unknown functions and tails, wrong names
+// of parameters, unknown blocks of Include and unbalanced brackets. The blocks are searched
+// within the transaction if it is specified
+func Lint(transaction *model.DbTransaction, input string, ecosystemID int64) ([]LintError, error) {
+	l := &linter{input: []rune(input), ecosystem: ecosystemID, transaction: transaction}
+	l.text(0, len(l.input))
+	if l.dbErr != nil {
+		return nil, l.dbErr
+	}
+	sort.SliceStable(l.errors, func(i, j int) bool {
+		if l.errors[i].Line == l.errors[j].Line {
+			return l.errors[i].Column < l.errors[j].Column
+		}
+		return l.errors[i].Line < l.errors[j].Line
+	})
+	return l.errors, nil
+}
+
+func (l *linter) errorf(off int, format string, args ...interface{}) {
+	line, column := 1, 1
+	for _, ch := range l.input[:off] {
+		if ch == '\n' {
+			line++
+			column = 1
+		} else {
+			column++
+		}
+	}
+	l.errors = append(l.errors, LintError{Line: line, Column: column, Message: fmt.Sprintf(format, args...)})
+}
+
+func isLetter(ch rune) bool {
+	return (ch >= 'A' && ch <= 'Z') || (ch >= 'a' && ch <= 'z')
+}
+
+func (l *linter) skipSpaces(off, end int) int {
+	for off < end && (l.input[off] == ' ' || l.input[off] == '\t') {
+		off++
+	}
+	return off
+}
+
+// text checks the text of the template between start and end offsets
+func (l *linter) text(start, end int) {
+	nameOff := start
+	for off := start; off < end; off++ {
+		if isLetter(l.input[off]) {
+			continue
+		}
+		if l.input[off] == '(' && off > nameOff {
+			name := string(l.input[nameOff:off])
+			if curFunc, ok := funcs[name]; ok {
+				off = l.function(curFunc, name, off, end)
+			} else if len(name) > 1 && name[0] >= 'A' && name[0] <= 'Z' {
+				l.errorf(nameOff, `unknown function %s`, name)
+			}
+		}
+		nameOff = off + 1
+	}
+}
+
+// function checks the function with its body and tails. It returns the offset of the last character
+func (l *linter) function(curFunc tplFunc, name string, open, end int) int {
+	off := l.call(curFunc, name, open, end)
+	if off < 0 {
+		return end - 1
+	}
+	tail := tails[curFunc.Tag]
+	for off+2 < end && l.input[off+1] == '.' {
+		next := off + 2
+		if l.input[next] == '(' {
+			return l.function(curFunc, name, next, end)
+		}
+		keyEnd := next
+		for keyEnd < end && isLetter(l.input[keyEnd]) {
+			keyEnd++
+		}
+		paren := l.skipSpaces(keyEnd, end)
+		if keyEnd == next || paren >= end || (l.input[paren] != '(' && l.input[paren] != '{') {
+			break
+		}
+		key := string(l.input[next:keyEnd])
+		tailFunc, ok := tail.Tails[key]
+		if !ok {
+			if _, isFunc := funcs[key]; !isFunc && tail.Tails != nil {
+				l.errorf(next, `unknown tail %s of %s`, key, name)
+				if unknown := l.call(tplFunc{Params: `*`}, key, paren, end); unknown >= 0 {
+					off = unknown
+				}
+			}
+			break
+		}
+		if off = l.call(tailFunc.tplFunc, key, paren, end); off < 0 {
+			return end - 1
+		}
+		if tailFunc.Last {
+			break
+		}
+	}
+	return off
+}
+
+// call checks the parameters and the body of the function. It returns the offset of the last
+// character or -1 if the brackets are unbalanced
+func (l *linter) call(curFunc tplFunc, name string, open, end int) int {
+	closing := l.block(open, end)
+	if closing < 0 {
+		return -1
+	}
+	if l.input[open] == '{' {
+		l.text(open+1, closing)
+		return closing
+	}
+	l.params(curFunc, name, open+1, closing)
+	if strings.Contains(curFunc.Params, `Body`) || strings.Contains(curFunc.Params, `Data`) {
+		next := l.skipSpaces(closing+1, end)
+		if next < end && l.input[next] == '{' {
+			if closing = l.block(next, end); closing < 0 {
+				return -1
+			}
+			if strings.Contains(curFunc.Params, `Body`) {
+				l.text(next+1, closing)
+			}
+		}
+	}
+	return closing
+}
+
+// block returns the offset of the bracket which closes the bracket at open offset
+func (l *linter) block(open, end int) int {
+	var quote rune
+	if l.input[open] == '{' {
+		level := 0
+		for off := open; off < end; off++ {
+			switch l.input[off] {
+			case '{':
+				level++
+			case '}':
+				if level--; level == 0 {
+					return off
+				}
+			}
+		}
+		l.errorf(open, `unclosed bracket {`)
+		return -1
+	}
+	stack := make([]rune, 0, 16)
+	for off := open; off < end; off++ {
+		ch := l.input[off]
+		if quote != 0 {
+			if ch == quote {
+				if off+1 < end && l.input[off+1] == quote {
+					off++
+				} else {
+					quote = 0
+				}
+			}
+			continue
+		}
+		switch ch {
+		case '"', '`':
+			quote = ch
+		case '(':
+			stack = append(stack, ')')
+		case '[':
+			stack = append(stack, ']')
+		case '{':
+			stack = append(stack, '}')
+		case ')', ']', '}':
+			if stack[len(stack)-1] != ch {
+				l.errorf(off, `unexpected bracket %c`, ch)
+				continue
+			}
+			if stack = stack[:len(stack)-1]; len(stack) == 0 {
+				return off
+			}
+		}
+	}
+	l.errorf(open, `unclosed bracket (`)
+	return -1
+}
+
+// params checks the names of the parameters between start and end offsets
+func (l *linter) params(curFunc tplFunc, name string, start, end int) {
+	var (
+		quote rune
+		level int
+		index int
+	)
+	check := func(from, to int) {
+		for from < to && (l.input[from] == '\n' || l.input[from] == '\r' || l.input[from] == ' ' ||
+			l.input[from] == '\t') {
+			from++
+		}
+		value := from
+		keyEnd := from
+		if keyEnd < to && l.input[keyEnd] == '#' {
+			keyEnd++
+		}
+		for keyEnd < to && (isLetter(l.input[keyEnd]) || l.input[keyEnd] == '_' ||
+			(l.input[keyEnd] >= '0' && l.input[keyEnd] <= '9')) {
+			keyEnd++
+		}
+		var key string
+		if colon := l.skipSpaces(keyEnd, to); keyEnd > from && colon < to && l.input[colon] == ':' {
+			key = strings.TrimPrefix(string(l.input[from:keyEnd]), `#`)
+			value = colon + 1
+			// the names of parameters are capitalized so "color: red" or "HH:MI" are values
+			if curFunc.Params != `*` && key[0] >= 'A' && key[0] <= 'Z' &&
+				strings.ToUpper(key) != key && !hasParam(curFunc.Params, key) {
+				l.errorf(from, `unknown parameter %s of %s`, key, name)
+			}
+		} else if list := strings.Split(curFunc.Params, `,`); curFunc.Params != `*` && index < len(list) {
+			key = strings.TrimPrefix(strings.TrimSpace(list[index]), `#`)
+		}
+		if name == `Include` && (key == `Name` || (len(key) == 0 && index == 0)) {
+			l.include(value, strings.Trim(strings.TrimSpace(string(l.input[value:to])), "\"`"))
+		}
+		switch {
+		case key == `Data`, key == `Params`, key == `PageParams`:
+		case name == `Markdown` && key == `Text`:
+		default:
+			l.text(value, to)
+		}
+		index++
+	}
+	from := start
+	for off := start; off < end; off++ {
+		ch := l.input[off]
+		if quote != 0 {
+			if ch == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch ch {
+		case '"', '`':
+			quote = ch
+		case '(', '[', '{':
+			level++
+		case ')', ']', '}':
+			level--
+		case ',':
+			if level == 0 {
+				check(from, off)
+				from = off + 1
+			}
+		}
+	}
+	if l.skipSpaces(from, end) < end {
+		check(from, end)
+	}
+}
+
+func hasParam(params, key string) bool {
+	for _, par := range strings.Split(params, `,`) {
+		if strings.TrimPrefix(strings.TrimSpace(par), `#`) == key {
+			return true
+		}
+	}
+	return false
+}
+
+// include checks that the block exists
+func (l *linter) include(off int, name string) {
+	if model.DBConn == nil || l.dbErr != nil || len(name) == 0 || strings.ContainsAny(name, `#(`) {
+		return
+	}
+	off = l.skipSpaces(off, len(l.input))
+	bi := &model.BlockInterface{}
+	ecosystem, tblname := converter.ParseName(name)
+	if ecosystem == 0 {
+		ecosystem = l.ecosystem
+		tblname = name
+	}
+	bi.SetTablePrefix(converter.Int64ToStr(ecosystem))
+	found, err := bi.GetTransaction(l.transaction, tblname)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting block by name")
+		l.dbErr = err
+		return
+	}
+	if !found {
+		l.errorf(off, `unknown block %s`, name)
+	}
+}
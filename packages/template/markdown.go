@@ -0,0 +1,273 @@
+// Copyright (C) 2017, 2018, 2019 EGAAS S.A.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or (at
+// your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but
+// WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301, USA.
+
+package template
+
+import (
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	mdLinkClass = `link`
+	mdEscapes   = "\\`*_{}[]()#+-.!>"
+)
+
+var (
+	reMdHeading = regexp.MustCompile(`^(#{1,6})\s+(.*?)(?:\s+#+)?\s*$`)
+	reMdRule    = regexp.MustCompile(`^([-*_])(\s*[-*_]){2,}$`)
+	reMdBullet  = regexp.MustCompile(`^[-*+]\s+(.*)$`)
+	reMdNumber  = regexp.MustCompile(`^\d+[.)]\s+(.*)$`)
+	reMdPage    = regexp.MustCompile(`^[\w\-]+(\?.*)?$`)
+)
+
+// markdownTag converts the markdown text into the nodes of the template
+func markdownTag(par parFunc) string {
+	nodes := markdownNodes((*par.Pars)[`Text`])
+	markdownMacro(nodes, par.Workspace.Vars)
+	par.Owner.Children = append(par.Owner.Children, nodes...)
+	return ``
+}
+
+// markdownMacro replaces variables after parsing so the headings are not taken as the variables.
+// The text of the code is left as is.
+func markdownMacro(nodes []*node, vars *map[string]Var) {
+	for _, item := range nodes {
+		if item.Tag == `code` {
+			continue
+		}
+		if item.Tag == tagText {
+			item.Text = macro(item.Text, vars)
+		}
+		for key, val := range item.Attr {
+			if s, ok := val.(string); ok {
+				item.Attr[key] = macro(s, vars)
+			}
+		}
+		markdownMacro(item.Children, vars)
+	}
+}
+
+func divClass(class string, children []*node) *node {
+	return &node{Tag: `div`, Attr: map[string]interface{}{`class`: class}, Children: children}
+}
+
+// markdownNodes parses the blocks of the markdown text
+func markdownNodes(input string) []*node {
+	var (
+		out  []*node
+		para []string
+	)
+	flush := func() {
+		if len(para) > 0 {
+			out = append(out, &node{Tag: `p`, Attr: make(map[string]interface{}),
+				Children: markdownInline([]rune(strings.Join(para, ` `)))})
+			para = nil
+		}
+	}
+	lines := strings.Split(strings.Replace(input, "\r\n", "\n", -1), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		switch {
+		case len(line) == 0:
+			flush()
+		case strings.HasPrefix(line, "```"):
+			flush()
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), "```"); i++ {
+				code = append(code, lines[i])
+			}
+			out = append(out, &node{Tag: `code`, Attr: map[string]interface{}{
+				`text`: strings.Join(code, "\n")}})
+		case reMdHeading.MatchString(line):
+			flush()
+			ret := reMdHeading.FindStringSubmatch(line)
+			out = append(out, divClass(`h`+strconv.Itoa(len(ret[1])), markdownInline([]rune(ret[2]))))
+		case reMdRule.MatchString(line):
+			flush()
+			out = append(out, divClass(`hr`, nil))
+		case line[0] == '>':
+			flush()
+			var quote []string
+			for ; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), `>`); i++ {
+				quote = append(quote, strings.TrimPrefix(strings.TrimSpace(lines[i])[1:], ` `))
+			}
+			i--
+			out = append(out, divClass(`blockquote`, markdownNodes(strings.Join(quote, "\n"))))
+		case reMdBullet.MatchString(line) || reMdNumber.MatchString(line):
+			flush()
+			re, class := reMdBullet, `ul`
+			if reMdNumber.MatchString(line) {
+				re, class = reMdNumber, `ol`
+			}
+			var items []*node
+			for ; i < len(lines) && re.MatchString(strings.TrimSpace(lines[i])); i++ {
+				ret := re.FindStringSubmatch(strings.TrimSpace(lines[i]))
+				items = append(items, divClass(`li`, markdownInline([]rune(ret[1]))))
+			}
+			i--
+			out = append(out, divClass(class, items))
+		default:
+			para = append(para, line)
+		}
+	}
+	flush()
+	return out
+}
+
+func indexRune(input []rune, from int, ch rune) int {
+	for i := from; i < len(input); i++ {
+		if input[i] == ch {
+			return i
+		}
+	}
+	return -1
+}
+
+func indexPair(input []rune, from int, ch rune) int {
+	for i := from; i < len(input)-1; i++ {
+		if input[i] == ch && input[i+1] == ch {
+			return i
+		}
+	}
+	return -1
+}
+
+// markdownRef parses [label](link) starting at the position of '['
+func markdownRef(input []rune, start int) (label []rune, link string, end int) {
+	closing := indexRune(input, start+1, ']')
+	if closing < 0 || closing+1 >= len(input) || input[closing+1] != '(' {
+		return nil, ``, -1
+	}
+	depth := 0
+	for end = closing + 2; end < len(input); end++ {
+		if input[end] == '(' {
+			depth++
+		} else if input[end] == ')' {
+			if depth == 0 {
+				return input[start+1 : closing], strings.TrimSpace(string(input[closing+2 : end])), end
+			}
+			depth--
+		}
+	}
+	return nil, ``, -1
+}
+
+// markdownLink returns linkpage for the links to the pages and span for the external links
+func markdownLink(label []rune, link string) *node {
+	children := markdownInline(label)
+	if reMdPage.MatchString(link) {
+		ret := &node{Tag: `linkpage`, Attr: make(map[string]interface{}), Children: children}
+		page := link
+		if off := strings.IndexByte(link, '?'); off >= 0 {
+			page = link[:off]
+			if query, err := url.ParseQuery(link[off+1:]); err == nil && len(query) > 0 {
+				pars := make(map[string]interface{})
+				for key := range query {
+					pars[key] = map[string]interface{}{`type`: `text`, `text`: query.Get(key)}
+				}
+				ret.Attr[`pageparams`] = pars
+			}
+		}
+		ret.Attr[`page`] = page
+		return ret
+	}
+	ret := &node{Tag: `span`, Attr: map[string]interface{}{`class`: mdLinkClass}, Children: children}
+	if link = safeURL(link); len(link) > 0 {
+		ret.Attr[`title`] = link
+	}
+	return ret
+}
+
+// markdownInline parses the emphasis, code spans, links and images of the markdown text
+func markdownInline(input []rune) []*node {
+	var (
+		out  []*node
+		text []rune
+	)
+	flush := func() {
+		if len(text) > 0 {
+			out = append(out, &node{Tag: tagText, Text: string(text)})
+			text = text[:0]
+		}
+	}
+	for i := 0; i < len(input); i++ {
+		ch := input[i]
+		switch ch {
+		case '\\':
+			if i+1 < len(input) && strings.ContainsRune(mdEscapes, input[i+1]) {
+				i++
+				text = append(text, input[i])
+				continue
+			}
+		case '`':
+			if end := indexRune(input, i+1, '`'); end > i+1 {
+				flush()
+				out = append(out, &node{Tag: `code`, Attr: map[string]interface{}{
+					`text`: string(input[i+1 : end])}})
+				i = end
+				continue
+			}
+		case '!':
+			if i+1 < len(input) && input[i+1] == '[' {
+				if label, link, end := markdownRef(input, i+1); end > 0 {
+					flush()
+					img := &node{Tag: `image`, Attr: map[string]interface{}{`alt`: string(label)}}
+					if link = safeURL(link); len(link) > 0 {
+						img.Attr[`src`] = link
+					}
+					out = append(out, img)
+					i = end
+					continue
+				}
+			}
+		case '[':
+			if label, link, end := markdownRef(input, i); end > 0 {
+				flush()
+				out = append(out, markdownLink(label, link))
+				i = end
+				continue
+			}
+		case '*', '_':
+			if ch == '_' && i > 0 && (unicode.IsLetter(input[i-1]) || unicode.IsDigit(input[i-1])) {
+				break
+			}
+			if i+1 < len(input) && input[i+1] == ch {
+				if end := indexPair(input, i+2, ch); end > i+2 {
+					flush()
+					out = append(out, &node{Tag: `strong`, Attr: make(map[string]interface{}),
+						Children: markdownInline(input[i+2 : end])})
+					i = end + 1
+					continue
+				}
+			} else if i+1 < len(input) && input[i+1] != ' ' {
+				if end := indexRune(input, i+1, ch); end > i+1 {
+					flush()
+					out = append(out, &node{Tag: `em`, Attr: make(map[string]interface{}),
+						Children: markdownInline(input[i+1 : end])})
+					i = end
+					continue
+				}
+			}
+		}
+		text = append(text, ch)
+	}
+	flush()
+	return out
+}
@@ -0,0 +1,259 @@
+// Copyright (C) 2017, 2018, 2019 EGAAS S.A.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or (at
+// your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but
+// WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301, USA.
+
+package template
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// SchemaVersion is the version of the schema of the node tree.
+// It must be increased when the format of the nodes is changed
+const SchemaVersion = "1"
+
+const schemaRef = `#/definitions/`
+
+var (
+	schemaString      = map[string]interface{}{`type`: `string`}
+	schemaStringArray = map[string]interface{}{`type`: `array`, `items`: schemaString}
+
+	// schemaAttrTypes are the types of the attributes which are not strings
+	schemaAttrTypes = map[string]map[string]interface{}{
+		`params`:     {`$ref`: schemaRef + `params`},
+		`pageparams`: {`$ref`: schemaRef + `params`},
+		`colors`:     schemaStringArray,
+		`series`:     schemaStringArray,
+	}
+	// schemaSourceAttrs are the attributes of the nodes which are data sources
+	schemaSourceAttrs = map[string]interface{}{
+		`columns`: schemaStringArray,
+		`types`:   schemaStringArray,
+		`data`:    map[string]interface{}{`type`: `array`, `items`: schemaStringArray},
+	}
+	schemaSources  = []string{`data`, `dbfind`, `jsontosource`, `arraytosource`, `range`, `httpsource`}
+	schemaTagAttrs = map[string]map[string]interface{}{
+		`table`: {`columns`: map[string]interface{}{`type`: `array`, `items`: map[string]interface{}{
+			`type`: `object`, `properties`: map[string]interface{}{`Name`: schemaString, `Title`: schemaString},
+		}}},
+	}
+)
+
+type schemaParam struct {
+	Name string `json:"name"`
+	Attr string `json:"attr,omitempty"`
+}
+
+// schemaParams returns the parameters of the function and the names of their attributes
+func schemaParams(params string) (list []schemaParam, variadic bool) {
+	if params == `*` {
+		return nil, true
+	}
+	for _, name := range strings.Split(params, `,`) {
+		name = strings.TrimPrefix(strings.TrimSpace(name), `#`)
+		item := schemaParam{Name: name}
+		if name != `Body` {
+			item.Attr = strings.ToLower(name)
+		}
+		list = append(list, item)
+	}
+	return
+}
+
+func schemaAttr(tag, name string) interface{} {
+	if attr, ok := schemaTagAttrs[tag][name]; ok {
+		return attr
+	}
+	if attr, ok := schemaAttrTypes[name]; ok {
+		return attr
+	}
+	return schemaString
+}
+
+// schemaTailAttr adds the attribute which is created by the tail in the owner node
+func schemaTailAttr(attrs map[string]interface{}, tag string, params []schemaParam) {
+	stringMap := map[string]interface{}{`type`: `object`, `additionalProperties`: schemaString}
+	switch tag {
+	case `alert`, `popup`:
+		attrs[tag] = schemaAttrs(tag, params, nil)
+	case `validate`:
+		attrs[tag] = stringMap
+	case `show`, `hide`:
+		attrs[tag] = map[string]interface{}{`type`: `array`, `items`: stringMap}
+	case `errorredirect`:
+		attrs[`errredirect`] = map[string]interface{}{`type`: `object`,
+			`additionalProperties`: schemaAttrs(tag, params, nil)}
+	case `composite`:
+		attrs[tag] = map[string]interface{}{`type`: `array`, `items`: map[string]interface{}{
+			`type`: `object`, `properties`: map[string]interface{}{`name`: schemaString, `data`: map[string]interface{}{}},
+		}}
+	}
+}
+
+func isTailAttr(info tplFunc) bool {
+	return reflect.ValueOf(info.Func).Pointer() == reflect.ValueOf(tailTag).Pointer()
+}
+
+func sortedKeys(m map[string]tailInfo) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Schema returns JSON schema of the node tree which is generated by Template2JSON.
+// The definition of each tag contains x-functions with the template functions and their parameters
+// and x-tails with the tails which can follow these functions
+func Schema() map[string]interface{} {
+	definitions := map[string]interface{}{
+		`params`: map[string]interface{}{
+			`type`: `object`,
+			`additionalProperties`: map[string]interface{}{
+				`type`:       `object`,
+				`required`:   []string{`type`},
+				`properties`: map[string]interface{}{`type`: schemaString, `text`: schemaString, `params`: schemaStringArray},
+			},
+		},
+		`nodes`: map[string]interface{}{`type`: `array`, `items`: map[string]interface{}{`$ref`: schemaRef + `node`}},
+		tagText: map[string]interface{}{
+			`type`:       `object`,
+			`required`:   []string{`tag`, `text`},
+			`properties`: map[string]interface{}{`tag`: map[string]interface{}{`const`: tagText}, `text`: schemaString},
+		},
+	}
+	type tagInfo struct {
+		attrs     map[string]interface{}
+		functions []interface{}
+		tails     []interface{}
+		tailNodes []interface{}
+	}
+	tags := make(map[string]*tagInfo)
+	names := make([]string, 0, len(funcs))
+	for name := range funcs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fn := funcs[name]
+		info := tags[fn.Tag]
+		if info == nil {
+			info = &tagInfo{attrs: make(map[string]interface{})}
+			tags[fn.Tag] = info
+			for _, tailName := range sortedKeys(tails[fn.Tag].Tails) {
+				tail := tails[fn.Tag].Tails[tailName]
+				params, variadic := schemaParams(tail.Params)
+				item := map[string]interface{}{`name`: tailName, `params`: params, `last`: tail.Last}
+				if variadic {
+					item[`variadic`] = true
+				}
+				if isTailAttr(tail.tplFunc) {
+					for _, par := range params {
+						if len(par.Attr) > 0 {
+							info.attrs[par.Attr] = schemaAttr(fn.Tag, par.Attr)
+						}
+					}
+				} else {
+					schemaTailAttr(info.attrs, tail.Tag, params)
+				}
+				item[`tag`] = tail.Tag
+				info.tailNodes = append(info.tailNodes, map[string]interface{}{`$ref`: schemaRef + `tail_` + tail.Tag})
+				definitions[`tail_`+tail.Tag] = map[string]interface{}{
+					`type`:     `object`,
+					`required`: []string{`tag`},
+					`properties`: map[string]interface{}{
+						`tag`:  map[string]interface{}{`const`: tail.Tag},
+						`attr`: schemaAttrs(tail.Tag, params, nil),
+					},
+				}
+				info.tails = append(info.tails, item)
+			}
+		}
+		params, variadic := schemaParams(fn.Params)
+		item := map[string]interface{}{`name`: name, `params`: params}
+		if variadic {
+			item[`variadic`] = true
+		}
+		info.functions = append(info.functions, item)
+		for _, par := range params {
+			if len(par.Attr) > 0 {
+				info.attrs[par.Attr] = schemaAttr(fn.Tag, par.Attr)
+			}
+		}
+	}
+	for _, tag := range schemaSources {
+		if info, ok := tags[tag]; ok {
+			for key, val := range schemaSourceAttrs {
+				info.attrs[key] = val
+			}
+		}
+	}
+
+	nodes := []interface{}{map[string]interface{}{`$ref`: schemaRef + tagText}}
+	tagNames := make([]string, 0, len(tags))
+	for tag := range tags {
+		tagNames = append(tagNames, tag)
+	}
+	sort.Strings(tagNames)
+	for _, tag := range tagNames {
+		info := tags[tag]
+		properties := map[string]interface{}{
+			`tag`:      map[string]interface{}{`const`: tag},
+			`attr`:     schemaAttrs(tag, nil, info.attrs),
+			`children`: map[string]interface{}{`$ref`: schemaRef + `nodes`},
+		}
+		def := map[string]interface{}{
+			`type`:        `object`,
+			`required`:    []string{`tag`},
+			`properties`:  properties,
+			`x-functions`: info.functions,
+		}
+		if len(info.tails) > 0 {
+			def[`x-tails`] = info.tails
+		}
+		if len(info.tailNodes) > 0 {
+			properties[`tail`] = map[string]interface{}{`type`: `array`,
+				`items`: map[string]interface{}{`anyOf`: info.tailNodes}}
+		}
+		definitions[`tag_`+tag] = def
+		nodes = append(nodes, map[string]interface{}{`$ref`: schemaRef + `tag_` + tag})
+	}
+	definitions[`node`] = map[string]interface{}{`anyOf`: nodes}
+
+	return map[string]interface{}{
+		`$schema`:     `http://json-schema.org/draft-07/schema#`,
+		`title`:       `Template node tree`,
+		`version`:     SchemaVersion,
+		`$ref`:        schemaRef + `nodes`,
+		`definitions`: definitions,
+	}
+}
+
+// schemaAttrs returns the schema of attributes. Unknown attributes are allowed
+// because some functions add the attributes which are not parameters
+func schemaAttrs(tag string, params []schemaParam, attrs map[string]interface{}) map[string]interface{} {
+	if attrs == nil {
+		attrs = make(map[string]interface{})
+	}
+	for _, par := range params {
+		if len(par.Attr) > 0 {
+			attrs[par.Attr] = schemaAttr(tag, par.Attr)
+		}
+	}
+	return map[string]interface{}{`type`: `object`, `properties`: attrs}
+}
@@ -59,12 +59,41 @@ type Var struct {
 	AsIs  bool
 }
 
+// Dependencies collects the tables which have been read while processing the template
+type Dependencies struct {
+	Tables map[string]bool
+	// NoCache is true if the result depends on the key of the user or on data which are not tracked
+	NoCache bool
+}
+
 // Workspace represents a workspace of executable template
 type Workspace struct {
 	Sources       *map[string]Source
+	Paginators    *map[string]*node
 	Vars          *map[string]Var
 	SmartContract *smart.SmartContract
 	Timeout       *bool
+	Deps          *Dependencies
+	include       *includeParams
+	budget        *renderBudget
+}
+
+func (w *Workspace) readTable(name string) {
+	if w.Deps != nil {
+		w.Deps.Tables[name] = true
+	}
+}
+
+func (w *Workspace) checkPersonal(input string) {
+	if w.Deps != nil && (strings.Contains(input, `key_id`) || strings.Contains(input, `account_id`)) {
+		w.Deps.NoCache = true
+	}
+}
+
+func (w *Workspace) noCache() {
+	if w.Deps != nil {
+		w.Deps.NoCache = true
+	}
 }
 
 // SetSource sets source to workspace
@@ -76,6 +105,23 @@ func (w *Workspace) SetSource(name string, source *Source) {
 	(*w.Sources)[name] = *source
 }
 
+// SetPaginator binds paginator node to the source
+func (w *Workspace) SetPaginator(name string, paginator *node) {
+	if w.Paginators == nil {
+		paginators := make(map[string]*node)
+		w.Paginators = &paginators
+	}
+	(*w.Paginators)[name] = paginator
+}
+
+// Paginator returns the paginator node of the source
+func (w *Workspace) Paginator(name string) *node {
+	if w.Paginators == nil {
+		return nil
+	}
+	return (*w.Paginators)[name]
+}
+
 type parFunc struct {
 	Owner     *node
 	Node      *node
@@ -680,7 +726,7 @@ func process(input string, owner *node, workspace *Workspace) {
 		}
 		if ch == '(' {
 			if curFunc, isFunc = funcs[string(name[nameOff:])]; isFunc {
-				if *workspace.Timeout {
+				if *workspace.Timeout || !workspace.allowFunc(string(name[nameOff:])) {
 					return
 				}
 				appendText(owner, macro(string(name[:nameOff]), workspace.Vars))
@@ -723,6 +769,16 @@ func parseArg(arg string, workspace *Workspace) (val string) {
 
 // Template2JSON converts templates to JSON data
 func Template2JSON(input string, timeout *bool, vars *map[string]string) []byte {
+	return Template2JSONDeps(input, timeout, vars, nil)
+}
+
+// Template2JSONDeps converts templates to JSON data and collects the tables which have been read
+func Template2JSONDeps(input string, timeout *bool, vars *map[string]string, deps *Dependencies) []byte {
+	return template2JSON(input, timeout, vars, deps, nil)
+}
+
+func template2JSON(input string, timeout *bool, vars *map[string]string, deps *Dependencies,
+	budget *renderBudget) []byte {
 	root := node{}
 	isobs := (*vars)[`obs`] == `true` || (*vars)[`obs`] == `1`
 	sc := smart.SmartContract{
@@ -737,7 +793,10 @@ func Template2JSON(input string, timeout *bool, vars *map[string]string) []byte
 		},
 	}
 	toVars := mapToVar(*vars)
-	process(input, &root, &Workspace{Vars: toVars, Timeout: timeout, SmartContract: &sc})
+	workspace := &Workspace{Vars: toVars, Timeout: timeout, SmartContract: &sc, Deps: deps,
+		budget: budget}
+	workspace.checkPersonal(input)
+	process(input, &root, workspace)
 	if root.Children == nil || *timeout {
 		return []byte(`[]`)
 	}
@@ -816,6 +875,9 @@ func splitArray(in []rune) []string {
 }
 
 func setVar(par *Workspace, key, value string) {
+	if !par.charge(int64(len(value)) * renderByteCost) {
+		return
+	}
 	(*par.Vars)[key] = Var{Value: value}
 }
 
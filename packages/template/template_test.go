@@ -17,11 +17,18 @@
 package template
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"sort"
 	"strings"
+	"sync/atomic"
 	"testing"
 
+	"github.com/AplaProject/go-apla/packages/conf"
+	"github.com/AplaProject/go-apla/packages/script"
+	"github.com/AplaProject/go-apla/packages/smart"
 	"github.com/AplaProject/go-apla/packages/types"
 )
 
@@ -65,7 +72,7 @@ func TestObj(t *testing.T) {
 	}
 	for _, item := range list {
 		var result string
-		val, _ := parseObject([]rune(item.input))
+		val, _, _ := parseObject([]rune(item.input))
 		switch v := val.(type) {
 		case []interface{}:
 			result = fmt.Sprintf("%v", v)
@@ -93,7 +100,259 @@ func TestJSON(t *testing.T) {
 	}
 }
 
+func TestMarkdown(t *testing.T) {
+	var timeout bool
+	vars := map[string]string{`_full`: `0`, `name`: `Bob`}
+	list := []tplItem{
+		{"Markdown(\"# Hello, #name#\n\nSome **bold** and *italic* text\nwith `snake_case` code\")",
+			`[{"tag":"div","attr":{"class":"h1"},"children":[{"tag":"text","text":"Hello, Bob"}]},{"tag":"p","children":[{"tag":"text","text":"Some "},{"tag":"strong","children":[{"tag":"text","text":"bold"}]},{"tag":"text","text":" and "},{"tag":"em","children":[{"tag":"text","text":"italic"}]},{"tag":"text","text":" text with "},{"tag":"code","attr":{"text":"snake_case"}},{"tag":"text","text":" code"}]}]`},
+		{"Markdown(\"- one\n- [two](page_two?id=5)\n\n1. [site](https://apla.io)\n2. ![pic](javascript:alert(1))\")",
+			`[{"tag":"div","attr":{"class":"ul"},"children":[{"tag":"div","attr":{"class":"li"},"children":[{"tag":"text","text":"one"}]},{"tag":"div","attr":{"class":"li"},"children":[{"tag":"linkpage","attr":{"page":"page_two","pageparams":{"id":{"text":"5","type":"text"}}},"children":[{"tag":"text","text":"two"}]}]}]},{"tag":"div","attr":{"class":"ol"},"children":[{"tag":"div","attr":{"class":"li"},"children":[{"tag":"span","attr":{"class":"link","title":"https://apla.io"},"children":[{"tag":"text","text":"site"}]}]},{"tag":"div","attr":{"class":"li"},"children":[{"tag":"image","attr":{"alt":"pic"}}]}]}]`},
+		{"Markdown(\"> quoted \\*text\\*\n\n---\n```\nDiv(){}\n```\")",
+			`[{"tag":"div","attr":{"class":"blockquote"},"children":[{"tag":"p","children":[{"tag":"text","text":"quoted *text*"}]}]},{"tag":"div","attr":{"class":"hr"}},{"tag":"code","attr":{"text":"Div(){}"}}]`},
+	}
+	for _, item := range list {
+		if out := string(Template2JSON(item.input, &timeout, &vars)); out != item.want {
+			t.Errorf("wrong markdown \r\n%s != \r\n%s", out, item.want)
+		}
+	}
+	want := `<div class="h2">Title</div><p><strong>bold</strong> <code>a&lt;b</code></p>`
+	if out := string(Template2HTML("Markdown(\"## Title\n**bold** `a<b`\")", &timeout, &vars)); out != want {
+		t.Errorf("wrong markdown html \r\n%s != \r\n%s", out, want)
+	}
+}
+
+func TestChartPivot(t *testing.T) {
+	data, names := chartPivot([]map[string]string{
+		{`label`: `2019-01`, `series`: `south`, `v0`: `5`},
+		{`label`: `2019-01`, `series`: `north`, `v0`: `10`},
+		{`label`: `2019-02`, `series`: `NULL`, `v0`: `7`},
+	})
+	if out := fmt.Sprint(names, data); out != `[ north south] [[2019-01 0 10 5] [2019-02 7 0 0]]` {
+		t.Errorf("wrong pivot %s", out)
+	}
+}
+
+func TestSchema(t *testing.T) {
+	out, err := json.Marshal(Schema())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var schema struct {
+		Version     string                            `json:"version"`
+		Definitions map[string]map[string]interface{} `json:"definitions"`
+	}
+	if err = json.Unmarshal(out, &schema); err != nil {
+		t.Fatal(err)
+	}
+	if schema.Version != SchemaVersion {
+		t.Errorf("wrong version %s", schema.Version)
+	}
+	for name, fn := range funcs {
+		if _, ok := schema.Definitions[`tag_`+fn.Tag]; !ok {
+			t.Errorf("tag %s of %s is not described", fn.Tag, name)
+		}
+	}
+	button, _ := json.Marshal(schema.Definitions[`tag_button`])
+	for _, want := range []string{
+		`"popup":{"properties":{"header":{"type":"string"},"width":{"type":"string"}},"type":"object"}`,
+		`"style":{"type":"string"}`,
+		`{"name":"Button","params":[{"name":"Body"},{"attr":"page","name":"Page"},`,
+		`{"last":true,"name":"Alert","params":[{"attr":"text","name":"Text"},`,
+		`"tail":{"items":{"anyOf":[{"$ref":"#/definitions/tail_alert"},`,
+	} {
+		if !strings.Contains(string(button), want) {
+			t.Errorf("schema of button %s does not contain %s", button, want)
+		}
+	}
+}
+
+func TestHTTPSource(t *testing.T) {
+	var (
+		timeout  bool
+		requests int32
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if r.URL.Path == `/redirect` {
+			http.Redirect(w, r, `http://localhost:1/items`, http.StatusFound)
+			return
+		}
+		if r.Header.Get(`Authorization`) != `Bearer key` {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"data": {"items": [{"name": "First", "count": 1}, {"name": "Second", "tags": ["a"]}]}}`))
+	}))
+	defer server.Close()
+	defer func(cfg conf.GlobalConfig) { conf.Config = cfg }(conf.Config)
+	conf.Config.HTTPSource = conf.HTTPSourceConfig{AllowedURLs: []string{server.URL}, Timeout: 5, CacheTTL: 60}
+
+	vars := map[string]string{`_full`: `0`}
+	input := `HTTPSource(src, ` + server.URL + `/items, "$.data.items[*]", {Authorization: "Bearer key"})ForList(src){#name#;}`
+	if out := string(Template2JSON(input, &timeout, &vars)); out != `[{"tag":"text","text":"HTTPSource is available only in OBS mode"}]` {
+		t.Errorf("wrong result %s", out)
+	}
+	conf.Config.OBSMode = `OBS`
+	want := `[{"tag":"httpsource","attr":{"columns":["count","name","tags"],"data":[["1","First",""],["","Second","[\"a\"]"]],"path":"$.data.items[*]","source":"src","types":["text","text","text"]}},` +
+		`{"tag":"forlist","attr":{"source":"src"},"children":[{"tag":"text","text":"First;"},{"tag":"text","text":"Second;"}]}]`
+	for i := 0; i < 2; i++ {
+		if out := string(Template2JSON(input, &timeout, &vars)); out != want {
+			t.Errorf("wrong result %s", out)
+		}
+	}
+	if atomic.LoadInt32(&requests) != 1 {
+		t.Errorf("response has not been cached %d", requests)
+	}
+	list := []tplItem{
+		{`HTTPSource(src, ` + server.URL + `.evil.com/items)`, `[{"tag":"text","text":"HTTPSource url is not allowed"}]`},
+		{`HTTPSource(src, ` + server.URL + `/other, "data.items[0].name")`, `[{"tag":"text","text":"401 Unauthorized"}]`},
+	}
+	for _, item := range list {
+		if out := string(Template2JSON(item.input, &timeout, &vars)); out != item.want {
+			t.Errorf("wrong result %s != %s", out, item.want)
+		}
+	}
+	out := string(Template2JSON(`HTTPSource(src, `+server.URL+`/redirect)`, &timeout, &vars))
+	if !strings.Contains(out, errHTTPSourceURL.Error()) {
+		t.Errorf("redirect to not allowed url %s", out)
+	}
+}
+
+func TestHTTPSourceAllowed(t *testing.T) {
+	defer func(cfg conf.GlobalConfig) { conf.Config = cfg }(conf.Config)
+	conf.Config.HTTPSource = conf.HTTPSourceConfig{AllowedURLs: []string{`http://host/api/`, `https://other`}}
+	for link, want := range map[string]bool{
+		`http://host/api/items`:        true,
+		`http://host/api/`:             true,
+		`http://host/api/a/../b?x=1`:   true,
+		`http://host/api/../admin`:     false,
+		`http://host/api/%2e%2e/admin`: false,
+		`http://host/api`:              false,
+		`https://other`:                true,
+		`https://other/path`:           true,
+		`https://other.evil.com/path`:  false,
+		`ftp://host/api/items`:         false,
+	} {
+		if httpSourceAllowed(link) != want {
+			t.Errorf("%s: %v != %v", link, !want, want)
+		}
+	}
+}
+
+func TestJSONPath(t *testing.T) {
+	var value interface{}
+	json.Unmarshal([]byte(`{"a": {"b": [{"c": 1}, {"c": 2}], "d e": "x"}}`), &value)
+	list := []struct {
+		path, want string
+	}{
+		{`$.a.b[*].c`, `[1 2]`},
+		{`a.b[1].c`, `2`},
+		{`$.a['d e']`, `x`},
+		{`$.a.none`, `<nil>`},
+		{`$.a.*`, `[[map[c:1] map[c:2]] x]`},
+	}
+	for _, item := range list {
+		out, err := jsonPath(value, item.path)
+		if err != nil || fmt.Sprint(out) != item.want {
+			t.Errorf("%s: %v != %s (%v)", item.path, out, item.want, err)
+		}
+	}
+}
+
+func TestLint(t *testing.T) {
+	list := []tplItem{
+		{`Div(Class: my){Span(Body: text).Style(color: red)}`, `[]`},
+		{`DBFind(mytable, src).Columns(id).Wher(id=1)`, `[1:34: unknown tail Wher of DBFind]`},
+		{"Div(){\n  Spn(text) Button(Bodi: ok)\n}", `[2:3: unknown function Spn 2:20: unknown parameter Bodi of Button]`},
+		{"Span(test, Div(text)\nP(ok)", `[1:5: unclosed bracket (]`},
+		{`If(true){Span(ok]}.Else{no}`, `[1:14: unclosed bracket ( 1:17: unexpected bracket ]]`},
+		{"Markdown(\"See Docs(intro) and [Page](about)\")", `[]`},
+	}
+	for _, item := range list {
+		errs, err := Lint(nil, item.input, 1)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		result := make([]string, 0, len(errs))
+		for _, err := range errs {
+			result = append(result, err.Error())
+		}
+		if out := fmt.Sprintf(`%v`, result); out != item.want {
+			t.Errorf("%s: %s != %s", item.input, out, item.want)
+		}
+	}
+}
+
+func TestContractHTML(t *testing.T) {
+	out, cost, err := ContractHTML(`Range(src, 0, 3)ForList(src){Span(#src_index#)}`, map[string]string{}, 1000)
+	if err != nil || string(out) != `<span>1</span><span>2</span><span>3</span>` || cost == 0 {
+		t.Errorf(`wrong html %s %d %v`, out, cost, err)
+	}
+	if _, _, err = ContractHTML(`DBFind(pages, src)`, map[string]string{}, 1000); err == nil ||
+		err.Error() != `function DBFind cannot be used in the contract` {
+		t.Errorf(`DBFind has been allowed %v`, err)
+	}
+	if _, _, err = ContractHTML(`Range(src, 0, 1000000000)`, map[string]string{}, 1000); err != errRenderFuel {
+		t.Errorf(`fuel has not been exceeded %v`, err)
+	}
+}
+
+func TestHTML(t *testing.T) {
+	var timeout bool
+	vars := map[string]string{`_full`: `0`}
+	list := []tplItem{
+		{`Div(my class){P(Hello <b>"world"</b>)}Image(Src: javascript:alert(1), Alt: pic)`,
+			`<div class="my class"><p>Hello &lt;b&gt;&#34;world&#34;&lt;/b&gt;</p></div><img alt="pic">`},
+		{`Data(src, "id,name"){1,First
+		2,<Second>}Table(src, "Name=name")Select(Name: sel, Source: src, Value: 2)`,
+			`<table><thead><tr><th>Name</th></tr></thead><tbody><tr><td>First</td></tr><tr><td>&lt;Second&gt;</td></tr></tbody></table>` +
+				`<select name="sel"><option value="1">First</option><option value="2" selected="selected">&lt;Second&gt;</option></select>`},
+		{`Form(){Input(Name: amount, Type: text, Value: 1<0)Button(Body: Send, Contract: MyContract)}LinkPage(Body: Next, Page: list, PageParams: "page=2,id=Val(id)")`,
+			`<form><input type="text" name="amount" value="1&lt;0"><button type="button" data-contract="MyContract">Send</button></form>` +
+				`<a href="/page/list?page=2">Next</a>`},
+	}
+	for _, item := range list {
+		if out := string(Template2HTML(item.input, &timeout, &vars)); out != item.want {
+			t.Errorf("wrong html \r\n%s != \r\n%s", out, item.want)
+		}
+	}
+}
+
+func TestContractForm(t *testing.T) {
+	var timeout bool
+	err := smart.GetVM().Compile([]rune(`contract FormTest {
+		data {
+			Amount money
+			Recipient string "address"
+			Count int "optional"
+			Avatar file "optional"
+		}
+	}`), &script.OwnerInfo{StateID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	vars := map[string]string{`_full`: `0`, `ecosystem_id`: `1`}
+	want := `[{"tag":"form","children":[` +
+		`{"tag":"div","attr":{"class":"form-group"},"children":[{"tag":"label","attr":{"for":"Amount"},"children":[{"tag":"text","text":"Amount"}]},{"tag":"input","attr":{"class":"form-control","name":"Amount","type":"text","validate":{"regexp":"^\\d+(\\.\\d+)?$","required":"true"}}}]},` +
+		`{"tag":"div","attr":{"class":"form-group"},"children":[{"tag":"label","attr":{"for":"Recipient"},"children":[{"tag":"text","text":"Recipient"}]},{"tag":"input","attr":{"class":"form-control","name":"Recipient","type":"text","validate":{"regexp":"^(\\d{4}-){4}\\d{4}$|^-?\\d+$","required":"true"}}}]},` +
+		`{"tag":"div","attr":{"class":"form-group"},"children":[{"tag":"label","attr":{"for":"Count"},"children":[{"tag":"text","text":"Count"}]},{"tag":"input","attr":{"class":"form-control","name":"Count","type":"number","validate":{"regexp":"^-?\\d+$"}}}]},` +
+		`{"tag":"div","attr":{"class":"form-group"},"children":[{"tag":"label","attr":{"for":"Avatar"},"children":[{"tag":"text","text":"Avatar"}]},{"tag":"input","attr":{"class":"form-control","name":"Avatar","type":"file"}}]},` +
+		`{"tag":"button","attr":{"class":"btn btn-primary","contract":"@1FormTest"},"children":[{"tag":"text","text":"@1FormTest"}]}]}]`
+	if out := string(Template2JSON(`ContractForm(FormTest)`, &timeout, &vars)); out != want {
+		t.Errorf("wrong form \r\n%s != \r\n%s", out, want)
+	}
+	if out := string(Template2JSON(`ContractForm(Unknown)`, &timeout, &vars)); out != `[{"tag":"text","text":"Contract Unknown has not been found"}]` {
+		t.Errorf(`wrong error %s`, out)
+	}
+}
+
 var forTest = tplList{
+	{`SetVar(cnt, 5)Params(cnt int, title)Span(#cnt#)`,
+		`[{"tag":"span","children":[{"tag":"text","text":"5"}]}]`},
+	{`SetVar(cnt, five)Params(cnt int)`,
+		`[{"tag":"text","text":"Parameter cnt must be int"}]`},
 	{`SetVar(group_access, "[zz]")If(#group_access# != "[]"){test}.Else{ok}
 	If(Or(#group_access# != "[]", false)){testOr}.Else{okOr}
 	If(And(#group_access# != "[]", true)){testAnd}.Else{okAnd}`, `[{"tag":"text","text":"test"},{"tag":"text","text":"testOr"},{"tag":"text","text":"testAnd"}]`},
@@ -377,6 +636,8 @@ var forTest = tplList{
 		`[{"tag":"table","attr":{"columns":[{"Name":"id","Title":"ID"},{"Name":"name","Title":"name"},{"Name":"wallet","Title":"Wallet"}],"source":"src"}}]`},
 	{`Chart(Type: "bar", Source: src, FieldLabel: "name", FieldValue: "count", Colors: "red, green")`,
 		`[{"tag":"chart","attr":{"colors":["red","green"],"fieldlabel":"name","fieldvalue":"count","source":"src","type":"bar"}}]`},
+	{`Chart(Type: "stackedbar", Source: src, FieldLabel: "month", FieldValue: "income, expense")`,
+		`[{"tag":"chart","attr":{"fieldlabel":"month","fieldvalue":"income, expense","series":["income","expense"],"source":"src","stacked":"true","type":"bar"}}]`},
 	{"InputMap(mapName, `{\"zoom\":\"12\", \"address\": \"some address\", \"area\":\"some area\", \"coords\": \"some cords\"}`, PolyType, satelite)",
 		`[{"tag":"inputMap","attr":{"@value":"{\"zoom\":\"12\", \"address\": \"some address\", \"area\":\"some area\", \"coords\": \"some cords\"}","maptype":"satelite","name":"mapName","type":"PolyType"}}]`},
 	{"InputMap(mapName, `{\"zoom\":\"12\", \"address\": \"some address\", \"area\":\"some area\", \"coords\": \"some cords\"}`, PolyType, satelite).Validate(ping: pong)",
@@ -389,6 +650,10 @@ var forTest = tplList{
 		`[{"tag":"text","text":"2019-06-19 12:00:00"}]`},
 	{`DateTime(DateTime: 1560938400, Location: "Europe/Moscow")`,
 		`[{"tag":"text","text":"2019-06-19 13:00:00"}]`},
+	{`SetVar(pg, 3)Paginator(src, 10, pg)`,
+		`[{"tag":"paginator","attr":{"offset":"20","page":"3","pageparam":"pg","pages":"0","pagesize":"10","source":"src","total":"0"}}]`},
+	{`Paginator(Source: src, PageSize: 20000)`,
+		`[{"tag":"paginator","attr":{"offset":"0","page":"1","pageparam":"page","pages":"0","pagesize":"10000","source":"src","total":"0"}}]`},
 }
 
 func TestFullJSON(t *testing.T) {
@@ -423,7 +688,24 @@ var forFullTest = tplList{
 		`[{"tag":"setvar","attr":{"name":"testvalue","value":"The new value"}},{"tag":"setvar","attr":{"name":"n","value":"param"}},{"tag":"text","text":"."},{"tag":"span","children":[{"tag":"text","text":"#testvalue#"}]}]`},
 	{`Include(myblock)`,
 		`[{"tag":"include","attr":{"name":"myblock"}}]`},
+	{`Include(myblock, My title, 10).Include(Name: myblock, Title: Text, Source: src)`,
+		`[{"tag":"include","attr":{"args":["My title","10"],"name":"myblock"}},{"tag":"text","text":"."},{"tag":"include","attr":{"name":"myblock","params":{"Source":"src","Title":"Text"}}}]`},
+	{`Params(Title, Count int)`,
+		`[{"tag":"params","attr":{"names":["Title","Count int"]}}]`},
 	{`If(true) {OK}.Else {false} If(false, FALSE).ElseIf(1) {Else OK
 			}.Else {Fourth}If(0).Else{ALL right}.What`,
 		`[{"tag":"if","attr":{"condition":"true"},"children":[{"tag":"text","text":"OK"}],"tail":[{"tag":"else","children":[{"tag":"text","text":"false"}]}]},{"tag":"if","attr":{"condition":"false"},"children":[{"tag":"text","text":"FALSE"}],"tail":[{"tag":"elseif","attr":{"condition":"1"},"children":[{"tag":"text","text":"Else OK"}]},{"tag":"else","children":[{"tag":"text","text":"Fourth"}]}]},{"tag":"if","attr":{"condition":"0"},"tail":[{"tag":"else","children":[{"tag":"text","text":"ALL right"}]}]},{"tag":"text","text":".What"}]`},
 }
+
+func TestGetWhere(t *testing.T) {
+	where, columns, err := getWhere(`{name: {$in: "x') or (1=1"}, amount: {$gt: 10}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(where, `'x')`) {
+		t.Errorf("value of $in is not quoted %s", where)
+	}
+	if fmt.Sprint(columns) != `[name amount]` {
+		t.Errorf("wrong where columns %v", columns)
+	}
+}
@@ -23,6 +23,7 @@ import (
 
 	"github.com/AplaProject/go-apla/packages/consts"
 	"github.com/AplaProject/go-apla/packages/model"
+	"github.com/AplaProject/go-apla/packages/publisher"
 	"github.com/AplaProject/go-apla/packages/utils"
 
 	log "github.com/sirupsen/logrus"
@@ -46,6 +47,22 @@ func InsertInLogTx(t *Transaction, blockID int64) error {
 	return nil
 }
 
+// InsertInTxIndex adds the transaction of the block to the index by the sender and by the contract
+func InsertInTxIndex(t *Transaction, blockID, blockTime int64) error {
+	index := &model.TxIndex{Hash: t.TxHash, BlockID: blockID, KeyID: t.TxKeyID, Time: blockTime}
+	if t.TxSmart != nil {
+		index.Ecosystem = t.TxSmart.EcosystemID
+	}
+	if t.TxContract != nil {
+		index.Contract = t.TxContract.Name
+	}
+	if err := index.Create(t.DbTransaction); err != nil {
+		log.WithFields(log.Fields{"error": err, "type": consts.DBError}).Error("insert transaction index")
+		return utils.ErrInfo(err)
+	}
+	return nil
+}
+
 // CheckLogTx checks if this transaction exists
 // And it would have successfully passed a frontal test
 func CheckLogTx(txHash []byte, transactions, txQueue bool) error {
@@ -134,6 +151,9 @@ func MarkTransactionBad(dbTransaction *model.DbTransaction, hash []byte, errText
 			log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("setting transaction status error")
 			return utils.ErrInfo(err)
 		}
+		if found, err := m.Get(hash); err == nil && found {
+			publisher.PublishTxStatus(m.WalletID, hash, 0, errText)
+		}
 	}
 	err = DeleteQueueTx(dbTransaction, hash)
 	if err != nil {
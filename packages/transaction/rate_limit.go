@@ -0,0 +1,192 @@
+// Copyright (C) 2017, 2018, 2019 EGAAS S.A.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or (at
+// your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but
+// WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301, USA.
+
+package transaction
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/AplaProject/go-apla/packages/consts"
+	"github.com/AplaProject/go-apla/packages/converter"
+	"github.com/AplaProject/go-apla/packages/model"
+	"github.com/AplaProject/go-apla/packages/smart"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// ErrRateLimitCode is the code of the error which is returned in /txstatus
+	ErrRateLimitCode = `E_RATELIMIT`
+
+	errRateLimitType = `rateLimit`
+	eRateLimit       = `The limit of %d calls of %s per %d seconds has been exceeded`
+)
+
+func rateLimitError(contract string, limit *model.RateLimit) error {
+	out, err := json.Marshal(&smart.ThrowError{
+		Type:    errRateLimitType,
+		Code:    ErrRateLimitCode,
+		ErrText: fmt.Sprintf(eRateLimit, limit.MaxCalls, contract, limit.Period),
+	})
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.JSONMarshallError, "error": err}).Error("marshalling rate limit error")
+		out = []byte(`{"type": "panic", "error": "marshalling rate limit error"}`)
+	}
+	return errors.New(string(out))
+}
+
+// rateLimits returns the rate limits of the ecosystem which are applied to the contract of the transaction
+func (t *Transaction) rateLimits() ([]model.RateLimit, error) {
+	if t.TxContract == nil || t.TxSmart == nil {
+		return nil, nil
+	}
+	ecosystem := t.TxSmart.EcosystemID
+	names := []string{t.TxContract.Name, model.RateLimitAnyContract}
+	// contracts of the own ecosystem can be specified without the prefix
+	if id, name := converter.ParseName(t.TxContract.Name); id == ecosystem {
+		names = append(names, name)
+	}
+	limits, err := (&model.RateLimit{}).SetTablePrefix(ecosystem).GetByContracts(t.DbTransaction, names)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting rate limits")
+		return nil, err
+	}
+	return limits, nil
+}
+
+// checkRateLimits returns an error if the key of the transaction has exhausted any of the rate limits
+func (t *Transaction) checkRateLimits(checkTime int64, limits []model.RateLimit) error {
+	for i := range limits {
+		limit := &limits[i]
+		contract := t.TxContract.Name
+		if limit.Contract == model.RateLimitAnyContract {
+			contract = model.RateLimitAnyContract
+		}
+		count, err := model.CountRateLimitUsage(t.DbTransaction, t.TxSmart.EcosystemID, t.TxKeyID,
+			contract, checkTime-limit.Period)
+		if err != nil {
+			log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("counting rate limit usage")
+			return err
+		}
+		if count >= limit.MaxCalls {
+			log.WithFields(log.Fields{"type": consts.ParameterExceeded, "key_id": t.TxKeyID,
+				"contract": t.TxContract.Name, "limit_id": limit.ID}).Warning("rate limit exceeded")
+			return rateLimitError(contract, limit)
+		}
+	}
+	return nil
+}
+
+// CheckRateLimits checks the rate limits of the ecosystem for the transaction
+func (t *Transaction) CheckRateLimits(checkTime int64) error {
+	limits, err := t.rateLimits()
+	if err != nil {
+		return err
+	}
+	return t.checkRateLimits(checkTime, limits)
+}
+
+// pruneRateLimitUsage deletes the calls of the contract made by the key which are out of all the limits.
+// The deleted records are saved in the system rollback in order to restore them
+func (t *Transaction) pruneRateLimitUsage(blockID, blockTime int64, limits []model.RateLimit) error {
+	var period int64
+	for _, limit := range limits {
+		if limit.Period > period {
+			period = limit.Period
+		}
+	}
+	expired, err := model.GetExpiredRateLimitUsage(t.DbTransaction, t.TxSmart.EcosystemID, t.TxKeyID,
+		t.TxContract.Name, blockTime-period)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting expired rate limit usage")
+		return err
+	}
+	if len(expired) == 0 {
+		return nil
+	}
+	ids := make([]int64, 0, len(expired))
+	for _, usage := range expired {
+		ids = append(ids, usage.ID)
+	}
+	if err = model.DeleteRateLimitUsage(t.DbTransaction, ids); err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("deleting expired rate limit usage")
+		return err
+	}
+	data, err := json.Marshal(expired)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.JSONMarshallError, "error": err}).Error("marshalling expired rate limit usage")
+		return err
+	}
+	out, err := json.Marshal(smart.SysRollData{Type: "DeleteRateLimitUsage", Data: string(data)})
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.JSONMarshallError, "error": err}).Error("marshalling sys rollback")
+		return err
+	}
+	rollbackSys := &model.RollbackTx{
+		BlockID:   blockID,
+		TxHash:    t.TxHash,
+		NameTable: smart.SysName,
+		TableID:   converter.Int64ToStr(t.TxSmart.EcosystemID),
+		Data:      string(out),
+	}
+	if err = rollbackSys.Create(t.DbTransaction); err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("creating system rollback for rate limit usage")
+		return err
+	}
+	return nil
+}
+
+// ApplyRateLimits checks the rate limits of the transaction and registers its call
+// if any of the limits is applied to the contract. The expired calls of the contract are deleted
+func (t *Transaction) ApplyRateLimits(blockID, blockTime int64) error {
+	limits, err := t.rateLimits()
+	if err != nil {
+		return err
+	}
+	if len(limits) == 0 {
+		return nil
+	}
+	if err = t.checkRateLimits(blockTime, limits); err != nil {
+		return err
+	}
+	if err = t.pruneRateLimitUsage(blockID, blockTime, limits); err != nil {
+		return err
+	}
+	usage := &model.RateLimitUsage{
+		Contract:  t.TxContract.Name,
+		KeyID:     t.TxKeyID,
+		BlockID:   blockID,
+		Time:      blockTime,
+		Ecosystem: t.TxSmart.EcosystemID,
+	}
+	if err = usage.Create(t.DbTransaction); err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("inserting rate limit usage")
+		return err
+	}
+	rollbackTx := &model.RollbackTx{
+		BlockID:   blockID,
+		TxHash:    t.TxHash,
+		NameTable: usage.TableName(),
+		TableID:   converter.Int64ToStr(usage.ID),
+	}
+	if err = rollbackTx.Create(t.DbTransaction); err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("creating rollback tx for rate limit usage")
+		return err
+	}
+	return nil
+}
@@ -309,9 +309,10 @@ func (t *Transaction) Check(checkTime int64, checkForDupTr bool) error {
 				return ErrEmptyKey
 			}
 		}
+		return nil
 	}
 
-	return nil
+	return t.CheckRateLimits(checkTime)
 }
 
 func (t *Transaction) Play() (string, []smart.FlushInfo, error) {
@@ -0,0 +1,44 @@
+// Copyright (C) 2017, 2018, 2019 EGAAS S.A.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or (at
+// your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but
+// WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program; if not, write to the Free Software
+// Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301, USA.
+
+package utils
+
+import (
+	"net"
+	"testing"
+)
+
+func TestIsPrivateIP(t *testing.T) {
+	for ip, want := range map[string]bool{
+		`127.0.0.1`:        true,
+		`10.1.2.3`:         true,
+		`172.20.0.1`:       true,
+		`192.168.1.1`:      true,
+		`169.254.169.254`:  true,
+		`0.0.0.0`:          true,
+		`::1`:              true,
+		`fd00::1`:          true,
+		`fe80::1`:          true,
+		`::ffff:127.0.0.1`: true,
+		`8.8.8.8`:          false,
+		`172.32.0.1`:       false,
+		`2001:4860::8888`:  false,
+	} {
+		if IsPrivateIP(net.ParseIP(ip)) != want {
+			t.Errorf("%s: %v != %v", ip, !want, want)
+		}
+	}
+}